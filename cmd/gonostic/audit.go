@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sultanfariz/gonostic/pkg/audit"
+)
+
+// runAuditVerify checks each audit log and fails if any chain is broken.
+func runAuditVerify(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no audit log files given")
+	}
+	failed := 0
+	for _, path := range args {
+		n, err := audit.VerifyFile(path)
+		if err != nil {
+			fmt.Printf("%s: FAILED after %d entries: %v\n", path, n, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: OK (%d entries)\n", path, n)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d logs failed verification", failed, len(args))
+	}
+	return nil
+}
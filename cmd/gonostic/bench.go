@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// runBench measures framework overhead with stub agents, so results reflect
+// the Executor and State rather than any model backend.
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	jobs := fs.Int("jobs", 10000, "jobs to submit in the executor benchmark")
+	workers := fs.Int("workers", 64, "executor workers")
+	pollers := fs.Int("pollers", 100, "goroutines polling job status while jobs run")
+	pollEvery := fs.Duration("poll-interval", time.Millisecond, "delay between status reads per poller")
+	work := fs.Duration("work", 0, "simulated agent time per job")
+	stateOps := fs.Int("state-ops", 1000000, "operations in the state benchmark")
+	stateKeys := fs.Int("state-keys", 1000, "distinct keys in the state benchmark")
+	goroutines := fs.Int("goroutines", runtime.GOMAXPROCS(0), "goroutines in the state benchmark")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fmt.Printf("go %s, GOMAXPROCS=%d\n\n", runtime.Version(), runtime.GOMAXPROCS(0))
+	if *jobs > 0 {
+		benchExecutor(*jobs, *workers, *pollers, *pollEvery, *work)
+	}
+	if *stateOps > 0 {
+		benchState(*stateOps, *stateKeys, *goroutines)
+	}
+	return nil
+}
+
+// stubAgent returns immediately (or after a fixed delay) with one step.
+type stubAgent struct {
+	work time.Duration
+}
+
+func (a *stubAgent) Name() string             { return "bench" }
+func (a *stubAgent) SubAgents() []agent.Agent { return nil }
+
+func (a *stubAgent) Execute(ctx context.Context, task *agent.Task) (*agent.Result, error) {
+	if a.work > 0 {
+		select {
+		case <-time.After(a.work):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return &agent.Result{
+		TaskID:  task.ID,
+		Success: true,
+		Output:  task.Input,
+		Steps:   []agent.ExecutionStep{{AgentName: "bench", Action: "reasoning", Timestamp: time.Now()}},
+	}, nil
+}
+
+func benchExecutor(jobs, workers, pollers int, pollEvery, work time.Duration) {
+	fmt.Printf("executor: %d jobs, %d workers, %d status pollers every %s, %s work/job\n", jobs, workers, pollers, pollEvery, work)
+
+	var memBefore runtime.MemStats
+	runtime.ReadMemStats(&memBefore)
+
+	var remaining sync.WaitGroup
+	remaining.Add(jobs)
+	exec := agent.NewExecutor(&stubAgent{work: work}, workers, agent.WithOnJobDone(func(*agent.Job) {
+		remaining.Done()
+	}))
+
+	ids := make([]string, 0, jobs)
+	var idsMu sync.RWMutex
+	stop := make(chan struct{})
+	var polls atomic.Int64
+	var pollWG sync.WaitGroup
+	for i := 0; i < pollers; i++ {
+		pollWG.Add(1)
+		go func(seed int64) {
+			defer pollWG.Done()
+			rng := rand.New(rand.NewSource(seed))
+			ticker := time.NewTicker(pollEvery)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+				}
+				idsMu.RLock()
+				n := len(ids)
+				var id string
+				if n > 0 {
+					id = ids[rng.Intn(n)]
+				}
+				idsMu.RUnlock()
+				if id == "" {
+					continue
+				}
+				exec.GetStatus(id)
+				polls.Add(1)
+			}
+		}(int64(i))
+	}
+
+	start := time.Now()
+	for i := 0; i < jobs; i++ {
+		id, err := exec.Submit("job "+strconv.Itoa(i), nil, nil)
+		if err != nil {
+			fmt.Printf("  submit failed: %v\n", err)
+			return
+		}
+		idsMu.Lock()
+		ids = append(ids, id)
+		idsMu.Unlock()
+	}
+	submitted := time.Since(start)
+	remaining.Wait()
+	elapsed := time.Since(start)
+	close(stop)
+	pollWG.Wait()
+
+	var memAfter runtime.MemStats
+	runtime.ReadMemStats(&memAfter)
+	stats := exec.Stats()
+
+	fmt.Printf("  submit:      %s (%.0f jobs/s)\n", submitted.Round(time.Millisecond), float64(jobs)/submitted.Seconds())
+	fmt.Printf("  complete:    %s (%.0f jobs/s)\n", elapsed.Round(time.Millisecond), float64(jobs)/elapsed.Seconds())
+	fmt.Printf("  job latency: p50 %s, p95 %s\n", stats.P50, stats.P95)
+	fmt.Printf("  status reads: %d (%.0f/s)\n", polls.Load(), float64(polls.Load())/elapsed.Seconds())
+	fmt.Printf("  allocated:   %.1f MiB (%d GCs)\n\n",
+		float64(memAfter.TotalAlloc-memBefore.TotalAlloc)/(1<<20), memAfter.NumGC-memBefore.NumGC)
+}
+
+func benchState(ops, keys, goroutines int) {
+	fmt.Printf("state: %d ops over %d keys, %d goroutines, 90%% reads\n", ops, keys, goroutines)
+
+	state := agent.NewMapState()
+	names := make([]string, keys)
+	for i := range names {
+		names[i] = "key-" + strconv.Itoa(i)
+		state.Set(names[i], i)
+	}
+
+	var wg sync.WaitGroup
+	perG := ops / goroutines
+	start := time.Now()
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for i := 0; i < perG; i++ {
+				key := names[rng.Intn(keys)]
+				if i%10 == 0 {
+					state.Set(key, i)
+				} else {
+					state.Get(key)
+				}
+			}
+		}(int64(g))
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	total := perG * goroutines
+	fmt.Printf("  %s (%.0f ops/s, %s/op)\n\n", elapsed.Round(time.Millisecond),
+		float64(total)/elapsed.Seconds(), (elapsed / time.Duration(total)).String())
+}
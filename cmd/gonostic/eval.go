@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+	"github.com/sultanfariz/gonostic/pkg/eval"
+	"github.com/sultanfariz/gonostic/pkg/providers"
+)
+
+// runEval runs an agent over a cases file and writes JUnit and JSON reports.
+// It fails when the pass rate is below -min-pass-rate, for use as a CI gate.
+func runEval(args []string) error {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	specPath := fs.String("c", "agent.yaml", "agent spec file")
+	casesPath := fs.String("d", "cases.jsonl", "cases file (JSON lines)")
+	concurrency := fs.Int("concurrency", 4, "cases run at once")
+	timeout := fs.Duration("timeout", 2*time.Minute, "per-case timeout")
+	junit := fs.String("junit", "", "write a JUnit XML report to this file")
+	report := fs.String("json", "", "write a JSON report to this file")
+	minPass := fs.Float64("min-pass-rate", 1, "fail when the pass rate is below this (0-1)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	spec, err := loadAgentSpec(*specPath)
+	if err != nil {
+		return err
+	}
+	cases, err := eval.LoadCases(*casesPath)
+	if err != nil {
+		return err
+	}
+	if len(cases) == 0 {
+		return fmt.Errorf("%s has no cases", *casesPath)
+	}
+
+	cfg := eval.Config{
+		Agent:       spec.agent(),
+		Concurrency: *concurrency,
+		Timeout:     *timeout,
+		OnResult: func(r eval.CaseResult) {
+			status := "PASS"
+			detail := ""
+			switch {
+			case r.Error != "":
+				status, detail = "ERROR", r.Error
+			case !r.Passed:
+				status, detail = "FAIL", strings.Join(r.Failures, "; ")
+			}
+			fmt.Printf("%-5s %s (%s) %s\n", status, r.ID, r.Duration.Round(time.Millisecond), detail)
+		},
+	}
+	rep := eval.Run(context.Background(), cfg, cases)
+	fmt.Printf("\n%d/%d passed (%.1f%%), %d failed, %d errored in %s, %d tokens\n",
+		rep.Passed, rep.Total, rep.PassRate*100, rep.Failed, rep.Errored, rep.Duration.Round(time.Millisecond), rep.Usage.TotalTokens)
+
+	suite := spec.Name
+	if suite == "" {
+		suite = strings.TrimSuffix(filepath.Base(*casesPath), filepath.Ext(*casesPath))
+	}
+	if *junit != "" {
+		if err := writeReport(*junit, func(f *os.File) error { return rep.WriteJUnit(f, suite) }); err != nil {
+			return err
+		}
+	}
+	if *report != "" {
+		if err := writeReport(*report, func(f *os.File) error { return rep.WriteJSON(f) }); err != nil {
+			return err
+		}
+	}
+	if rep.PassRate < *minPass {
+		return fmt.Errorf("pass rate %.3f is below %.3f", rep.PassRate, *minPass)
+	}
+	return nil
+}
+
+func writeReport(path string, write func(*os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// agentSpec describes the LLMAgent under evaluation, served by an
+// OpenAI-compatible chat completions API.
+type agentSpec struct {
+	Name        string  `json:"name"`
+	Prompt      string  `json:"prompt"`
+	Endpoint    string  `json:"endpoint"`
+	Model       string  `json:"model"`
+	APIKeyEnv   string  `json:"api_key_env"`
+	MaxTurns    int     `json:"max_turns"`
+	Temperature float32 `json:"temperature"`
+}
+
+func (s *agentSpec) agent() agent.Agent {
+	model := &chatModel{baseURL: strings.TrimSuffix(s.Endpoint, "/"), model: s.Model, apiKey: os.Getenv(s.APIKeyEnv), client: providers.DefaultClient()}
+	cfg := agent.LLMAgentConfig{Name: s.Name, Prompt: s.Prompt, Model: model, MaxTurns: s.MaxTurns}
+	if s.Temperature > 0 {
+		cfg.Defaults = &agent.ExecutionConfig{Temperature: s.Temperature}
+	}
+	return agent.NewLLMAgent(cfg)
+}
+
+// loadAgentSpec reads a spec as JSON or as flat YAML: "key: value" lines,
+// with "|" for a multi-line block (e.g. the prompt) indented under its key.
+func loadAgentSpec(path string) (*agentSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec := &agentSpec{Name: "eval", APIKeyEnv: "OPENAI_API_KEY"}
+	if text := strings.TrimSpace(string(data)); strings.HasPrefix(text, "{") {
+		if err := json.Unmarshal(data, spec); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	} else if err := parseFlatYAML(string(data), spec); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if spec.Endpoint == "" || spec.Model == "" {
+		return nil, fmt.Errorf("%s: endpoint and model are required", path)
+	}
+	return spec, nil
+}
+
+func parseFlatYAML(text string, spec *agentSpec) error {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if t := strings.TrimSpace(line); t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, " ") {
+			return fmt.Errorf("line %d: expected key: value", i+1)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		if value == "|" || value == "|-" {
+			var block []string
+			for i+1 < len(lines) && (strings.TrimSpace(lines[i+1]) == "" || strings.HasPrefix(lines[i+1], " ") || strings.HasPrefix(lines[i+1], "\t")) {
+				i++
+				block = append(block, lines[i])
+			}
+			value = dedent(block)
+		} else {
+			value = unquote(value)
+		}
+
+		var err error
+		switch key {
+		case "name":
+			spec.Name = value
+		case "prompt":
+			spec.Prompt = value
+		case "endpoint":
+			spec.Endpoint = value
+		case "model":
+			spec.Model = value
+		case "api_key_env":
+			spec.APIKeyEnv = value
+		case "max_turns":
+			spec.MaxTurns, err = strconv.Atoi(value)
+		case "temperature":
+			var t float64
+			t, err = strconv.ParseFloat(value, 32)
+			spec.Temperature = float32(t)
+		default:
+			return fmt.Errorf("line %d: unknown key %q", i+1, key)
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %s: %w", i+1, key, err)
+		}
+	}
+	return nil
+}
+
+// dedent strips the common leading whitespace of a block and trailing blank
+// lines.
+func dedent(lines []string) string {
+	prefix := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if prefix < 0 || n < prefix {
+			prefix = n
+		}
+	}
+	for i, l := range lines {
+		if len(l) >= prefix && prefix > 0 {
+			lines[i] = l[prefix:]
+		} else {
+			lines[i] = strings.TrimSpace(l)
+		}
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		if s[0] == '"' {
+			if u, err := strconv.Unquote(s); err == nil {
+				return u
+			}
+		}
+		return s[1 : len(s)-1]
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+// Command gonostic provides operational utilities for gonostic deployments.
+//
+// Usage:
+//
+//	gonostic <command> [arguments]
+//
+// Commands:
+//
+//	audit-verify <file>...  verify the hash chain of audit logs
+//	bench [flags]           measure Executor and State overhead with stub agents
+//	eval -c spec -d cases   run an agent over eval cases and write JUnit/JSON reports
+//	replay [flags] <trace>  step through a recorded execution turn by turn
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"audit-verify", "audit-verify <file>...", runAuditVerify},
+	{"bench", "bench [-jobs n] [-workers n] [-pollers n] [-poll-interval d] [-work d] [-state-ops n]", runBench},
+	{"eval", "eval -c agent.yaml -d cases.jsonl [-concurrency n] [-timeout d] [-junit file] [-json file] [-min-pass-rate r]", runEval},
+	{"replay", "replay [-endpoint url -model name] <trace.json>", runReplay},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "gonostic %s: %v\n", c.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "gonostic: unknown command %q\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gonostic <command> [arguments]\n\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", c.usage)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+	"github.com/sultanfariz/gonostic/pkg/providers"
+	"github.com/sultanfariz/gonostic/pkg/replay"
+)
+
+// runReplay steps through a recorded trace. With -endpoint set, turns can be
+// re-run against an OpenAI-compatible chat completions API.
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	endpoint := fs.String("endpoint", "", "OpenAI-compatible base URL for re-runs, e.g. https://api.openai.com/v1")
+	model := fs.String("model", "", "model name for re-runs")
+	keyEnv := fs.String("api-key-env", "OPENAI_API_KEY", "environment variable holding the API key")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: gonostic replay [-endpoint url -model name] trace.json")
+	}
+
+	trace, err := replay.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	d := &replay.Debugger{Trace: trace, In: os.Stdin, Out: os.Stdout}
+	if *endpoint != "" {
+		d.Model = &chatModel{baseURL: strings.TrimSuffix(*endpoint, "/"), model: *model, apiKey: os.Getenv(*keyEnv), client: providers.DefaultClient()}
+	}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		d.Edit = func(prompt string) (string, error) { return editInEditor(editor, prompt) }
+	}
+	return d.Run(context.Background())
+}
+
+// editInEditor opens text in the user's editor and returns the saved result.
+func editInEditor(editor, text string) (string, error) {
+	f, err := os.CreateTemp("", "gonostic-prompt-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", err
+	}
+	f.Close()
+
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", f.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(f.Name())
+	return strings.TrimRight(string(data), "\n"), err
+}
+
+// chatModel is a minimal client for OpenAI-compatible chat completions,
+// enough to re-run a recorded turn.
+type chatModel struct {
+	baseURL string
+	model   string
+	apiKey  string
+	client  *http.Client
+}
+
+func (m *chatModel) Complete(ctx context.Context, req *agent.CompletionRequest) (*agent.ModelResponse, error) {
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	body := map[string]interface{}{"model": m.model}
+	msgs := make([]message, 0, len(req.History)+1)
+	for _, h := range req.History {
+		msgs = append(msgs, message{Role: string(h.Role), Content: h.Content})
+	}
+	body["messages"] = append(msgs, message{Role: "user", Content: req.Prompt})
+	if len(req.Tools) > 0 {
+		tools := make([]map[string]interface{}, len(req.Tools))
+		for i, t := range req.Tools {
+			tools[i] = map[string]interface{}{"type": "function", "function": map[string]interface{}{
+				"name": t.Name(), "description": t.Description(), "parameters": t.Schema(),
+			}}
+		}
+		body["tools"] = tools
+	}
+	if req.OutputSchema != nil {
+		body["response_format"] = map[string]interface{}{"type": "json_schema", "json_schema": map[string]interface{}{"name": "output", "schema": req.OutputSchema}}
+	}
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	if req.MaxTokens != nil {
+		body["max_tokens"] = *req.MaxTokens
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.baseURL+"/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+	resp, err := m.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, providers.StatusError(resp.StatusCode, string(raw))
+	}
+
+	var out struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content   string `json:"content"`
+				ToolCalls []struct {
+					ID       string `json:"id"`
+					Function struct {
+						Name      string `json:"name"`
+						Arguments string `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage *agent.TokenUsage `json:"usage"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(out.Choices) == 0 {
+		return nil, errors.New("response has no choices")
+	}
+	msg := out.Choices[0].Message
+	result := &agent.ModelResponse{
+		Content:      msg.Content,
+		Usage:        out.Usage,
+		Model:        out.Model,
+		FinishReason: agent.ParseFinishReason(out.Choices[0].FinishReason),
+	}
+	result.Finished = len(msg.ToolCalls) == 0 && result.FinishReason != agent.FinishLength
+	for _, tc := range msg.ToolCalls {
+		var args map[string]interface{}
+		json.Unmarshal([]byte(tc.Function.Arguments), &args)
+		result.ToolCalls = append(result.ToolCalls, agent.ToolCall{ID: tc.ID, Name: tc.Function.Name, Arguments: args})
+	}
+	return result, nil
+}
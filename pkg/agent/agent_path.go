@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"strings"
+)
+
+type agentPathKey struct{}
+
+// AgentPathFromContext returns the hierarchical path of the agent currently
+// executing, e.g. "root/research-pipeline/researcher".
+func AgentPathFromContext(ctx context.Context) string {
+	path, _ := ctx.Value(agentPathKey{}).(string)
+	return path
+}
+
+// EnterAgent returns a context whose agent path has name appended. Built-in
+// agents call it at the start of Execute; custom agents that record their
+// own steps can call it to appear in nested traces.
+func EnterAgent(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, agentPathKey{}, pathFor(ctx, name))
+}
+
+// pathFor returns the path of agentName relative to the agent path in ctx.
+// When ctx already ends in agentName, that path is returned unchanged.
+func pathFor(ctx context.Context, agentName string) string {
+	parent := AgentPathFromContext(ctx)
+	switch {
+	case parent == "":
+		return agentName
+	case agentName == "" || parent == agentName || strings.HasSuffix(parent, "/"+agentName):
+		return parent
+	default:
+		return parent + "/" + agentName
+	}
+}
+
+// appendSteps adds sub-agent steps to the result, filling in the agent path
+// for steps from agents that did not record one.
+func (r *Result) appendSteps(ctx context.Context, steps []ExecutionStep) {
+	for _, step := range steps {
+		if step.AgentPath == "" {
+			step.AgentPath = pathFor(ctx, step.AgentName)
+		}
+		r.Steps = append(r.Steps, step)
+	}
+}
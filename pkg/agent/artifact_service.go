@@ -0,0 +1,196 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StoredArtifact describes an artifact saved in an ArtifactService. Its
+// content is fetched separately with ArtifactService.Open.
+type StoredArtifact struct {
+	ID        string                 `json:"id"`
+	SessionID string                 `json:"session_id"`
+	TaskID    string                 `json:"task_id,omitempty"`
+	UserID    string                 `json:"-"`
+	Name      string                 `json:"name,omitempty"`
+	Type      string                 `json:"type"`
+	MimeType  string                 `json:"mime_type,omitempty"`
+	Size      int                    `json:"size"`
+	CreatedAt time.Time              `json:"created_at"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ArtifactFilter narrows ArtifactService.ListBySession. Empty fields match
+// everything.
+type ArtifactFilter struct {
+	Type     string // Artifact.Type, e.g. "image"
+	MimeType string // Exact type, or a "image/*" wildcard
+}
+
+func (f ArtifactFilter) match(a *StoredArtifact) bool {
+	if f.Type != "" && a.Type != f.Type {
+		return false
+	}
+	if f.MimeType == "" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(f.MimeType, "/*"); ok {
+		return strings.HasPrefix(a.MimeType, prefix+"/")
+	}
+	return a.MimeType == f.MimeType
+}
+
+// ArtifactService stores the artifacts generated in sessions, so a UI can
+// list the files of a conversation without querying storage itself.
+// Implementations back it with object storage; NewInMemoryArtifactService
+// keeps artifacts in memory.
+type ArtifactService interface {
+	// Save stores data under meta, generating its ID and CreatedAt when
+	// unset, and returns the stored description.
+	Save(ctx context.Context, meta StoredArtifact, data []byte) (*StoredArtifact, error)
+
+	// Open returns an artifact and its content.
+	Open(ctx context.Context, id string) (*StoredArtifact, []byte, error)
+
+	// ListBySession returns the session's artifacts matching filter, oldest
+	// first.
+	ListBySession(ctx context.Context, sessionID string, filter ArtifactFilter) ([]StoredArtifact, error)
+}
+
+// ErrArtifactNotFound is returned for unknown artifact IDs.
+var ErrArtifactNotFound = &jobStateError{msg: "artifact not found", status: http.StatusNotFound}
+
+// InMemoryArtifactService is an ArtifactService for tests and single-process
+// deployments. It is safe for concurrent use.
+type InMemoryArtifactService struct {
+	mu        sync.RWMutex
+	artifacts map[string]*storedContent
+	sessions  map[string][]string // Artifact IDs per session, in save order
+}
+
+type storedContent struct {
+	meta StoredArtifact
+	data []byte
+}
+
+// NewInMemoryArtifactService creates an empty in-memory ArtifactService.
+func NewInMemoryArtifactService() *InMemoryArtifactService {
+	return &InMemoryArtifactService{
+		artifacts: make(map[string]*storedContent),
+		sessions:  make(map[string][]string),
+	}
+}
+
+func (s *InMemoryArtifactService) Save(ctx context.Context, meta StoredArtifact, data []byte) (*StoredArtifact, error) {
+	if meta.SessionID == "" {
+		return nil, errors.New("artifact session ID is required")
+	}
+	if meta.ID == "" {
+		meta.ID = NewID(ctx)
+	}
+	if meta.CreatedAt.IsZero() {
+		meta.CreatedAt = Now(ctx)
+	}
+	meta.Size = len(data)
+	meta.Metadata = copyMap(meta.Metadata)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.artifacts[meta.ID]; exists {
+		return nil, fmt.Errorf("artifact %s already exists", meta.ID)
+	}
+	s.artifacts[meta.ID] = &storedContent{meta: meta, data: append([]byte(nil), data...)}
+	s.sessions[meta.SessionID] = append(s.sessions[meta.SessionID], meta.ID)
+	return &meta, nil
+}
+
+func (s *InMemoryArtifactService) Open(ctx context.Context, id string) (*StoredArtifact, []byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.artifacts[id]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrArtifactNotFound, id)
+	}
+	meta := c.meta
+	return &meta, c.data, nil
+}
+
+func (s *InMemoryArtifactService) ListBySession(ctx context.Context, sessionID string, filter ArtifactFilter) ([]StoredArtifact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []StoredArtifact
+	for _, id := range s.sessions[sessionID] {
+		if c := s.artifacts[id]; filter.match(&c.meta) {
+			out = append(out, c.meta)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// ArtifactSink returns a ResultSink saving the artifacts of every finished
+// job that belongs to a session (Params["session_id"]) to svc. Register it
+// with WithResultSink.
+func ArtifactSink(svc ArtifactService) ResultSink {
+	return ResultSinkFunc(func(ctx context.Context, job *Job) error {
+		sessionID, _ := job.Task.Params[ParamSessionID].(string)
+		if sessionID == "" || job.Result == nil {
+			return nil
+		}
+		var userID string
+		if job.Task.Config != nil {
+			userID = job.Task.Config.UserID
+		}
+		for i, a := range job.Result.Artifacts {
+			data, err := artifactContent(a)
+			if err != nil {
+				return fmt.Errorf("artifact %d: %w", i, err)
+			}
+			meta := StoredArtifact{
+				SessionID: sessionID,
+				TaskID:    job.Task.ID,
+				UserID:    userID,
+				Name:      artifactName(a, i),
+				Type:      a.Type,
+				MimeType:  a.MimeType,
+				Metadata:  a.Metadata,
+			}
+			if _, err := svc.Save(ctx, meta, data); err != nil {
+				return fmt.Errorf("artifact %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
+
+// artifactContent renders an artifact's content as bytes: strings and byte
+// slices as-is, anything else as JSON.
+func artifactContent(a Artifact) ([]byte, error) {
+	switch c := a.Content.(type) {
+	case string:
+		return []byte(c), nil
+	case []byte:
+		return c, nil
+	default:
+		return json.Marshal(c)
+	}
+}
+
+// artifactName is the artifact's "name" or workspace "path" metadata, else
+// a name derived from its position and type.
+func artifactName(a Artifact, i int) string {
+	for _, key := range []string{"name", "path"} {
+		if name, ok := a.Metadata[key].(string); ok && name != "" {
+			return path.Base(name)
+		}
+	}
+	return fmt.Sprintf("artifact-%03d-%s", i, a.Type)
+}
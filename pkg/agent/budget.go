@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Budget surfaces the remaining turn, token, and time allowance to the model
+// at the start of every turn, so it can wrap up before being cut off by
+// MaxTurns or the timeout. Each turn's accounting is also emitted as an
+// EventBudget event.
+type Budget struct {
+	// MaxTokens is the token allowance across all turns; once spent, the
+	// model is told to give its final answer (0 = not tracked).
+	MaxTokens int
+
+	// Format renders the status into the message sent to the model
+	// (default DefaultBudgetFormat).
+	Format func(BudgetStatus) string
+}
+
+// BudgetStatus is the budget accounting at the start of a turn.
+type BudgetStatus struct {
+	Turn       int           `json:"turn"`        // 1-based
+	TurnsLeft  int           `json:"turns_left"`  // Including this one
+	TokensUsed int           `json:"tokens_used"` // By earlier turns
+	TokensLeft int           `json:"tokens_left"` // -1 when MaxTokens is not set
+	TimeLeft   time.Duration `json:"time_left"`   // 0 when there is no deadline
+}
+
+// Final reports whether this is the last turn the budget allows.
+func (s BudgetStatus) Final() bool {
+	return s.TurnsLeft <= 1 || s.TokensLeft == 0
+}
+
+// DefaultBudgetFormat renders e.g. "Budget: 2 turns, ~3k tokens and 40s
+// left. Plan to give your final answer before it runs out."
+func DefaultBudgetFormat(s BudgetStatus) string {
+	if s.Final() {
+		return "Budget: this is your last turn. Give your final answer now without calling tools."
+	}
+	parts := []string{fmt.Sprintf("%d turns", s.TurnsLeft)}
+	if s.TokensLeft > 0 {
+		parts = append(parts, "~"+approxTokens(s.TokensLeft)+" tokens")
+	}
+	if s.TimeLeft > 0 {
+		parts = append(parts, s.TimeLeft.Round(time.Second).String())
+	}
+	list := parts[0]
+	if n := len(parts); n > 1 {
+		list = strings.Join(parts[:n-1], ", ") + " and " + parts[n-1]
+	}
+	return "Budget: " + list + " left. Plan to give your final answer before it runs out."
+}
+
+func approxTokens(n int) string {
+	if n < 1000 {
+		return fmt.Sprint(n)
+	}
+	return fmt.Sprintf("%dk", (n+500)/1000)
+}
+
+// status computes the budget for turn (0-based) of maxTurns.
+func (b *Budget) status(ctx context.Context, turn, maxTurns int, result *Result) BudgetStatus {
+	s := BudgetStatus{Turn: turn + 1, TurnsLeft: maxTurns - turn, TokensLeft: -1}
+	for _, step := range result.Steps {
+		if step.TokenUsage != nil {
+			s.TokensUsed += step.TokenUsage.TotalTokens
+		}
+	}
+	if b.MaxTokens > 0 {
+		s.TokensLeft = max(b.MaxTokens-s.TokensUsed, 0)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		s.TimeLeft = max(time.Until(deadline), 0)
+	}
+	return s
+}
+
+// inject adds the budget message for this turn to the system message of req
+// and emits it as an event. The message is not kept in the conversation
+// history.
+func (b *Budget) inject(ctx context.Context, task *Task, agentName string, req *CompletionRequest, status BudgetStatus) {
+	format := b.Format
+	if format == nil {
+		format = DefaultBudgetFormat
+	}
+	req.History = withSystemNote(req.History, format(status))
+
+	ev := newEvent(ctx, task.ID, agentName, EventBudget)
+	ev.AgentPath = AgentPathFromContext(ctx)
+	ev.Budget = &status
+	emit(task, ev)
+}
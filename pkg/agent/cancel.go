@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CancellableTool is implemented by long-running tools (browser sessions,
+// builds) that hold external resources. When the task is cancelled or times
+// out while Execute is running, Cancel is called with the interrupted call so
+// the tool can release them; the agent waits for Cancel to return before
+// moving on.
+type CancellableTool interface {
+	Tool
+	Cancel(ctx context.Context, call ToolCall) error
+}
+
+// ToolCancelTimeout bounds each CancellableTool.Cancel call.
+var ToolCancelTimeout = 30 * time.Second
+
+// ErrJobCancelled is the error of a job stopped by Executor.Cancel.
+var ErrJobCancelled = errors.New("job cancelled")
+
+// ErrJobFinished is returned by Executor.Cancel for jobs that already
+// completed or failed.
+var ErrJobFinished = &jobStateError{msg: "job already finished", status: http.StatusConflict}
+
+// ErrJobNotFound is returned for unknown task IDs.
+var ErrJobNotFound = &jobStateError{msg: "task not found", status: http.StatusNotFound}
+
+type jobStateError struct {
+	msg    string
+	status int
+}
+
+func (e *jobStateError) Error() string { return e.msg }
+
+// StatusCode returns the HTTP status servers should respond with.
+func (e *jobStateError) StatusCode() int { return e.status }
+
+// runCancellable executes a cancellable tool, calling Cancel if ctx ends
+// before Execute returns. Cancel errors are recorded in
+// Metadata["tool_cancel_errors"].
+func runCancellable(ctx context.Context, result *Result, tool CancellableTool, tc *ToolCall) (interface{}, error) {
+	cancelErr := make(chan error, 1)
+	stop := context.AfterFunc(ctx, func() {
+		cctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ToolCancelTimeout)
+		defer cancel()
+		cancelErr <- tool.Cancel(cctx, *tc)
+	})
+
+	res, err := tool.Execute(ctx, tc.Arguments)
+	if !stop() {
+		if cerr := <-cancelErr; cerr != nil {
+			errs, _ := result.Metadata["tool_cancel_errors"].([]string)
+			result.Metadata["tool_cancel_errors"] = append(errs, fmt.Sprintf("%s: %v", tc.Name, cerr))
+		}
+	}
+	return res, err
+}
+
+// Cancel stops a job. A pending job fails as soon as a worker picks it up; a
+// running job's context is cancelled, so CancellableTools clean up, and it
+// fails with ErrJobCancelled.
+func (e *Executor) Cancel(taskID string) error {
+	job, ok := e.jobs.get(taskID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, taskID)
+	}
+	job.cancelRequested.Store(true)
+	switch job.Status() {
+	case JobCompleted, JobFailed:
+		return ErrJobFinished
+	case JobRunning, JobStalled, JobNeedsInput:
+		// cancel is set before the status becomes running
+		job.cancel()
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CascadeTier is one model in a cascade.
+type CascadeTier struct {
+	Name  string
+	Model ModelProvider
+}
+
+// CascadeConfig holds configuration for creating a CascadeModel.
+type CascadeConfig struct {
+	Tiers []CascadeTier // Cheapest first
+
+	// MaxTurns moves a task to the next tier once this many turns have run
+	// on the current one, counting the assistant messages in the history
+	// (0 = never).
+	MaxTurns int
+
+	// Confidence scores a response; a score below MinConfidence escalates
+	// to the next tier (optional).
+	Confidence    func(resp *ModelResponse) float64
+	MinConfidence float64
+
+	// Validate rejects a response, escalating to the next tier. The default
+	// checks final answers (no tool calls) against req.OutputSchema.
+	Validate func(req *CompletionRequest, resp *ModelResponse) error
+}
+
+// CascadeModel routes turns to the cheapest tier that produces an acceptable
+// response. Each response keeps the serving provider's Model, so Pricing
+// costs it, and reports the tier and escalation path in Routing, which
+// LLMAgent records on the step.
+type CascadeModel struct {
+	cfg CascadeConfig
+}
+
+// NewCascadeModel creates a new CascadeModel from the given configuration.
+func NewCascadeModel(cfg CascadeConfig) *CascadeModel {
+	if cfg.Validate == nil {
+		cfg.Validate = validateFinalOutput
+	}
+	for i := range cfg.Tiers {
+		if cfg.Tiers[i].Name == "" {
+			cfg.Tiers[i].Name = fmt.Sprintf("tier-%d", i)
+		}
+	}
+	return &CascadeModel{cfg: cfg}
+}
+
+// validateFinalOutput checks a final answer against the requested schema,
+// repairing near-valid JSON first as LLMAgent would.
+func validateFinalOutput(req *CompletionRequest, resp *ModelResponse) error {
+	if req.OutputSchema == nil || len(resp.ToolCalls) > 0 {
+		return nil
+	}
+	content := resp.Content
+	if repaired, err := RepairJSON(content); err == nil {
+		content = repaired
+	}
+	return ValidateSchema(req.OutputSchema, content)
+}
+
+func (m *CascadeModel) Complete(ctx context.Context, req *CompletionRequest) (*ModelResponse, error) {
+	return m.complete(ctx, req, nil)
+}
+
+// CompleteStream streams each tier that supports streaming. A tier is only
+// judged once its response is complete, so the deltas of a rejected tier
+// have already been delivered when the next tier starts streaming; the
+// response holds only the serving tier's content.
+func (m *CascadeModel) CompleteStream(ctx context.Context, req *CompletionRequest, onDelta func(delta string)) (*ModelResponse, error) {
+	return m.complete(ctx, req, onDelta)
+}
+
+func (m *CascadeModel) complete(ctx context.Context, req *CompletionRequest, onDelta func(string)) (*ModelResponse, error) {
+	if len(m.cfg.Tiers) == 0 {
+		return nil, errors.New("cascade has no tiers")
+	}
+
+	start, reason := m.startTier(req)
+	var decisions []string
+	if reason != "" {
+		decisions = append(decisions, reason)
+	}
+	var usage TokenUsage
+	var lastErr error
+	var best *ModelResponse
+
+	for i := start; i < len(m.cfg.Tiers); i++ {
+		tier := m.cfg.Tiers[i]
+		resp, err := completeStream(ctx, tier.Model, req, onDelta)
+		if err != nil && ctx.Err() != nil {
+			return nil, err
+		}
+		if resp != nil && resp.Usage != nil {
+			usage = addUsage(usage, *resp.Usage)
+		}
+
+		why := m.reject(tier, req, resp, err)
+		if why == "" {
+			return m.served(resp, tier, decisions, usage), nil
+		}
+		if err != nil {
+			lastErr = err
+		} else {
+			// The strongest tier's answer is kept even if it falls short
+			best = m.served(resp, tier, append(decisions, why), usage)
+		}
+		decisions = append(decisions, why)
+	}
+
+	if best != nil {
+		return best, nil
+	}
+	return nil, fmt.Errorf("all cascade tiers failed: %w", lastErr)
+}
+
+// reject returns why a tier's response is not acceptable, or "" if it is.
+func (m *CascadeModel) reject(tier CascadeTier, req *CompletionRequest, resp *ModelResponse, err error) string {
+	if err != nil {
+		return fmt.Sprintf("%s failed: %v", tier.Name, err)
+	}
+	if verr := m.cfg.Validate(req, resp); verr != nil {
+		return fmt.Sprintf("%s failed validation: %v", tier.Name, verr)
+	}
+	if m.cfg.Confidence != nil {
+		if c := m.cfg.Confidence(resp); c < m.cfg.MinConfidence {
+			return fmt.Sprintf("%s confidence %.2f below %.2f", tier.Name, c, m.cfg.MinConfidence)
+		}
+	}
+	return ""
+}
+
+// startTier picks the first tier for this turn based on MaxTurns.
+func (m *CascadeModel) startTier(req *CompletionRequest) (int, string) {
+	if m.cfg.MaxTurns <= 0 {
+		return 0, ""
+	}
+	turns := 0
+	for _, msg := range req.History {
+		if msg.Role == RoleAssistant {
+			turns++
+		}
+	}
+	tier := turns / m.cfg.MaxTurns
+	if tier == 0 {
+		return 0, ""
+	}
+	if tier >= len(m.cfg.Tiers) {
+		tier = len(m.cfg.Tiers) - 1
+	}
+	return tier, fmt.Sprintf("turn %d exceeds %d turns per tier", turns+1, m.cfg.MaxTurns)
+}
+
+func (m *CascadeModel) served(resp *ModelResponse, tier CascadeTier, decisions []string, usage TokenUsage) *ModelResponse {
+	out := *resp
+	out.Routing = "cascade served by " + tier.Name
+	if len(decisions) > 0 {
+		out.Routing += ": " + strings.Join(decisions, "; ")
+	}
+	if usage != (TokenUsage{}) {
+		out.Usage = &usage
+	}
+	return &out
+}
+
+// completeStream calls model's CompleteStream when it streams and onDelta
+// is set, and Complete otherwise.
+func completeStream(ctx context.Context, model ModelProvider, req *CompletionRequest, onDelta func(string)) (*ModelResponse, error) {
+	if sp, ok := model.(StreamingModelProvider); ok && onDelta != nil {
+		return sp.CompleteStream(ctx, req, onDelta)
+	}
+	return model.Complete(ctx, req)
+}
+
+func addUsage(a, b TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		TotalTokens:      a.TotalTokens + b.TotalTokens,
+		ReasoningTokens:  a.ReasoningTokens + b.ReasoningTokens,
+	}
+}
@@ -0,0 +1,285 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Checkpoint is a named snapshot taken after a workflow stage: task state
+// plus, for the workflow and every workflow enclosing it, where to pick up.
+// Executor.ResumeFrom re-runs only the stages after it.
+type Checkpoint struct {
+	TaskID    string
+	Name      string
+	CreatedAt time.Time
+	Params    map[string]interface{}
+	State     map[string]interface{}
+	Frames    []CheckpointFrame // Outermost workflow first
+}
+
+// CheckpointFrame is one workflow's position in a checkpoint.
+type CheckpointFrame struct {
+	Path      string          // Agent path of the workflow
+	Stage     int             // Index of the stage to run next
+	Input     string          // Task input for that stage
+	Steps     []ExecutionStep // Steps the workflow had recorded so far
+	Artifacts []Artifact
+	Output    interface{} // Workflow output so far
+}
+
+// ErrCheckpointNotFound is returned by CheckpointStore.Load for unknown
+// checkpoints.
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// CheckpointStore persists checkpoints. Implementations must be safe for
+// concurrent use.
+type CheckpointStore interface {
+	Save(ctx context.Context, cp *Checkpoint) error
+	Load(ctx context.Context, taskID, name string) (*Checkpoint, error)
+	List(ctx context.Context, taskID string) ([]*Checkpoint, error)
+}
+
+// MemoryCheckpointStore is an in-process CheckpointStore.
+type MemoryCheckpointStore struct {
+	mu    sync.RWMutex
+	tasks map[string]map[string]*Checkpoint
+}
+
+// NewMemoryCheckpointStore creates a new empty MemoryCheckpointStore.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{tasks: make(map[string]map[string]*Checkpoint)}
+}
+
+func (s *MemoryCheckpointStore) Save(ctx context.Context, cp *Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tasks[cp.TaskID] == nil {
+		s.tasks[cp.TaskID] = make(map[string]*Checkpoint)
+	}
+	s.tasks[cp.TaskID][cp.Name] = cp
+	return nil
+}
+
+func (s *MemoryCheckpointStore) Load(ctx context.Context, taskID, name string) (*Checkpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cp, ok := s.tasks[taskID][name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", ErrCheckpointNotFound, taskID, name)
+	}
+	return cp, nil
+}
+
+// List returns a task's checkpoints, oldest first.
+func (s *MemoryCheckpointStore) List(ctx context.Context, taskID string) ([]*Checkpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cps := make([]*Checkpoint, 0, len(s.tasks[taskID]))
+	for _, cp := range s.tasks[taskID] {
+		cps = append(cps, cp)
+	}
+	sort.Slice(cps, func(i, j int) bool { return cps[i].CreatedAt.Before(cps[j].CreatedAt) })
+	return cps, nil
+}
+
+// WithCheckpoints saves a checkpoint to store after every successful stage,
+// named "after-<stage name>" unless the stage is labelled with
+// WithCheckpointName.
+func WithCheckpoints(store CheckpointStore) WorkflowOption {
+	return func(o *workflowOptions) {
+		o.checkpoints = store
+	}
+}
+
+// checkpointLabel decorates a stage with a checkpoint name.
+type checkpointLabel struct {
+	Agent
+	name string
+}
+
+// WithCheckpointName labels the checkpoint taken after ag completes, e.g.
+// "after-research".
+func WithCheckpointName(ag Agent, name string) Agent {
+	return &checkpointLabel{Agent: ag, name: name}
+}
+
+func (a *checkpointLabel) Unwrap() Agent {
+	return a.Agent
+}
+
+func checkpointName(stage Agent) string {
+	if l, ok := unwrapTo(stage, func(a Agent) bool { _, ok := a.(*checkpointLabel); return ok }).(*checkpointLabel); ok {
+		return l.name
+	}
+	return "after-" + stage.Name()
+}
+
+// stageFrame is a running workflow's position, carried in the context of
+// its stages so nested workflows can checkpoint the whole stack.
+type stageFrame struct {
+	path   string
+	stage  int
+	input  string
+	result *Result
+}
+
+type stageFramesKey struct{}
+
+// enterStage returns the context for running stage of the workflow in ctx.
+func enterStage(ctx context.Context, stage int, input string, result *Result) context.Context {
+	parent, _ := ctx.Value(stageFramesKey{}).([]stageFrame)
+	frames := append(parent[:len(parent):len(parent)], stageFrame{
+		path:   AgentPathFromContext(ctx),
+		stage:  stage,
+		input:  input,
+		result: result,
+	})
+	return context.WithValue(ctx, stageFramesKey{}, frames)
+}
+
+func (f stageFrame) snapshot(stage int, input string) CheckpointFrame {
+	return CheckpointFrame{
+		Path:      f.path,
+		Stage:     stage,
+		Input:     input,
+		Steps:     append([]ExecutionStep(nil), f.result.Steps...),
+		Artifacts: append([]Artifact(nil), f.result.Artifacts...),
+		Output:    f.result.Output,
+	}
+}
+
+// saveCheckpoint records a checkpoint after stage index of the workflow in
+// ctx. nextInput is the input of the following stage. Save failures are
+// recorded in Metadata["checkpoint_error"] and never fail the workflow.
+func (o *workflowOptions) saveCheckpoint(ctx context.Context, index int, stage Agent, task *Task, result *Result, nextInput string) {
+	if o.checkpoints == nil {
+		return
+	}
+	cp := &Checkpoint{
+		TaskID:    task.ID,
+		Name:      checkpointName(stage),
+		CreatedAt: Now(ctx),
+		Params:    copyMap(task.Params),
+		State:     copyMap(task.State),
+	}
+	ancestors, _ := ctx.Value(stageFramesKey{}).([]stageFrame)
+	for _, f := range ancestors {
+		cp.Frames = append(cp.Frames, f.snapshot(f.stage, f.input))
+	}
+	own := stageFrame{path: AgentPathFromContext(ctx), result: result}
+	cp.Frames = append(cp.Frames, own.snapshot(index+1, nextInput))
+
+	if err := o.checkpoints.Save(ctx, cp); err != nil {
+		result.Metadata["checkpoint_error"] = err.Error()
+	}
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// resumeState is a checkpoint being resumed; each frame is used once.
+type resumeState struct {
+	cp   *Checkpoint
+	mu   sync.Mutex
+	used map[string]bool
+}
+
+type resumeKey struct{}
+
+// resumeAt positions the workflow in ctx at its checkpoint frame when the
+// execution is a resume, seeding result and task input from the frame. It
+// returns the index of the first stage to run.
+func resumeAt(ctx context.Context, task *Task, result *Result) int {
+	rs, _ := ctx.Value(resumeKey{}).(*resumeState)
+	if rs == nil {
+		return 0
+	}
+	path := AgentPathFromContext(ctx)
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for _, f := range rs.cp.Frames {
+		if f.Path != path || rs.used[path] {
+			continue
+		}
+		rs.used[path] = true
+		result.Steps = append(result.Steps, f.Steps...)
+		result.Artifacts = append(result.Artifacts, f.Artifacts...)
+		result.Output = f.Output
+		result.Metadata["resumed_from"] = rs.cp.Name
+		task.Input = f.Input
+		return f.Stage
+	}
+	return 0
+}
+
+// ResumeOption modifies a checkpoint before it is resumed.
+type ResumeOption func(cp *Checkpoint)
+
+// WithResumeInput replaces the input of the first stage that runs.
+func WithResumeInput(input string) ResumeOption {
+	return func(cp *Checkpoint) {
+		cp.Frames[len(cp.Frames)-1].Input = input
+	}
+}
+
+// WithResumeState merges state into the checkpointed task state.
+func WithResumeState(state map[string]interface{}) ResumeOption {
+	return func(cp *Checkpoint) {
+		for k, v := range state {
+			cp.State[k] = v
+		}
+	}
+}
+
+// WithCheckpointStore sets the store Executor.ResumeFrom loads checkpoints
+// from; pass the same store to the workflows' WithCheckpoints.
+func WithCheckpointStore(store CheckpointStore) ExecutorOption {
+	return func(e *Executor) {
+		e.checkpoints = store
+	}
+}
+
+// ResumeFrom submits a new job that continues task taskID from the named
+// checkpoint, skipping the stages before it. Options change the state or
+// input first, so the tail of an expensive run can be iterated on cheaply.
+// It returns the new job's task ID.
+func (e *Executor) ResumeFrom(taskID, checkpoint string, opts ...ResumeOption) (string, error) {
+	if e.checkpoints == nil {
+		return "", errors.New("resume: executor has no checkpoint store")
+	}
+	if err := e.maintenance.check(e.agent.Name()); err != nil {
+		return "", err
+	}
+	stored, err := e.checkpoints.Load(context.Background(), taskID, checkpoint)
+	if err != nil {
+		return "", err
+	}
+	cp := *stored
+	cp.State = copyMap(stored.State)
+	if cp.State == nil {
+		cp.State = make(map[string]interface{})
+	}
+	cp.Frames = append([]CheckpointFrame(nil), stored.Frames...)
+	if len(cp.Frames) == 0 {
+		return "", fmt.Errorf("resume: checkpoint %s has no workflow frames", checkpoint)
+	}
+	for _, opt := range opts {
+		opt(&cp)
+	}
+
+	task := e.newTask(cp.Frames[0].Input, copyMap(cp.Params), nil)
+	task.State = cp.State
+	return e.enqueue(task, &resumeState{cp: &cp, used: map[string]bool{}}), nil
+}
@@ -0,0 +1,183 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator produces the IDs of tasks, events, tool calls, sessions, and
+// memories. Implementations must be safe for concurrent use.
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDFunc adapts a function to the IDGenerator interface.
+type IDFunc func() string
+
+func (f IDFunc) NewID() string {
+	return f()
+}
+
+// Clock tells the time stamped on tasks, steps, and events and used to
+// measure step durations. Timeouts and stall detection always use the
+// system clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// UUIDs generates random UUIDs; it is the default IDGenerator.
+var UUIDs IDGenerator = IDFunc(func() string { return uuid.New().String() })
+
+// SystemClock is the default Clock.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// PrefixedIDs prepends prefix to the IDs of gen (default UUIDs), e.g.
+// PrefixedIDs("task_", nil).
+func PrefixedIDs(prefix string, gen IDGenerator) IDGenerator {
+	if gen == nil {
+		gen = UUIDs
+	}
+	return IDFunc(func() string { return prefix + gen.NewID() })
+}
+
+// SequentialIDs generates prefix1, prefix2, ... for tests and
+// deterministic replays.
+func SequentialIDs(prefix string) IDGenerator {
+	var n atomic.Int64
+	return IDFunc(func() string { return prefix + strconv.FormatInt(n.Add(1), 10) })
+}
+
+// crockford is the ULID alphabet.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDs generates ULIDs: 26 characters that sort by creation time, taken
+// from clock (default SystemClock), followed by 80 random bits.
+func ULIDs(clock Clock) IDGenerator {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return IDFunc(func() string {
+		var b [16]byte
+		binary.BigEndian.PutUint64(b[:8], uint64(clock.Now().UnixMilli())<<16)
+		rand.Read(b[6:])
+		// 128 bits as 26 base32 digits, the first carrying only 3 bits
+		hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+		var out [26]byte
+		for i := 25; i >= 0; i-- {
+			out[i] = crockford[lo&31]
+			lo = lo>>5 | hi<<59
+			hi >>= 5
+		}
+		return string(out[:])
+	})
+}
+
+// ManualClock is a Clock that only moves when told to, for tests and
+// deterministic replays. It is safe for concurrent use.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock creates a clock stopped at t.
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{now: t}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to t.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type idGeneratorKey struct{}
+
+type clockKey struct{}
+
+// ContextWithIDGenerator makes NewID use gen for everything run with ctx.
+func ContextWithIDGenerator(ctx context.Context, gen IDGenerator) context.Context {
+	return context.WithValue(ctx, idGeneratorKey{}, gen)
+}
+
+// ContextWithClock makes Now use clock for everything run with ctx.
+func ContextWithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, clock)
+}
+
+// NewID returns an ID from ctx's IDGenerator, or a UUID. Agents and tools
+// use it so injected generators cover the IDs they create.
+func NewID(ctx context.Context) string {
+	if gen, ok := ctx.Value(idGeneratorKey{}).(IDGenerator); ok {
+		return gen.NewID()
+	}
+	return UUIDs.NewID()
+}
+
+// Now returns the time on ctx's Clock, or the system time.
+func Now(ctx context.Context) time.Time {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return clock.Now()
+	}
+	return time.Now()
+}
+
+// Since returns the time elapsed since t on ctx's Clock.
+func Since(ctx context.Context, t time.Time) time.Duration {
+	return Now(ctx).Sub(t)
+}
+
+// WithIDGenerator makes the Executor and the agents it runs take IDs from
+// gen, e.g. SequentialIDs for reproducible tests or PrefixedIDs for an
+// ID format requirement.
+func WithIDGenerator(gen IDGenerator) ExecutorOption {
+	return func(e *Executor) {
+		e.ids = gen
+	}
+}
+
+// WithClock makes the Executor and the agents it runs take time from
+// clock, e.g. a ManualClock for reproducible timestamps and durations.
+func WithClock(clock Clock) ExecutorOption {
+	return func(e *Executor) {
+		e.clock = clock
+	}
+}
+
+// withClock carries the Executor's IDGenerator and Clock in ctx.
+func (e *Executor) withClock(ctx context.Context) context.Context {
+	if e.ids != nil {
+		ctx = ContextWithIDGenerator(ctx, e.ids)
+	}
+	if e.clock != nil {
+		ctx = ContextWithClock(ctx, e.clock)
+	}
+	return ctx
+}
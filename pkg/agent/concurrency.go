@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConcurrencyStats describes one agent's concurrency limit and usage.
+type ConcurrencyStats struct {
+	Limit     int
+	Active    int64         // Executions holding a slot
+	Waiting   int64         // Executions queued for a slot
+	Acquired  int64         // Slots granted since the limit was set
+	Queued    int64         // Grants that had to wait for a slot
+	TotalWait time.Duration // Time spent waiting across all grants
+}
+
+// ConcurrencyLimits caps how many executions of an agent, by name, run at
+// once, independent of any worker pool. Executions over the limit wait for a
+// slot. Limits are enforced by Executor jobs, LLMAgent.Execute, and agents
+// wrapped with WithConcurrencyLimit; custom agents can call Acquire.
+type ConcurrencyLimits struct {
+	mu     sync.RWMutex
+	limits map[string]*concurrencyLimit
+}
+
+type concurrencyLimit struct {
+	max   int
+	slots chan struct{}
+
+	active    atomic.Int64
+	waiting   atomic.Int64
+	acquired  atomic.Int64
+	queued    atomic.Int64
+	totalWait atomic.Int64
+}
+
+type concurrencyHeldKey struct{}
+
+// DefaultConcurrencyLimits is the process-wide registry used by LLMAgent,
+// WithConcurrencyLimit, and Executors unless another is configured.
+var DefaultConcurrencyLimits = NewConcurrencyLimits()
+
+// NewConcurrencyLimits creates an empty registry.
+func NewConcurrencyLimits() *ConcurrencyLimits {
+	return &ConcurrencyLimits{limits: make(map[string]*concurrencyLimit)}
+}
+
+// SetConcurrencyLimit limits the named agent to max concurrent executions
+// process-wide; see ConcurrencyLimits.SetLimit.
+func SetConcurrencyLimit(name string, max int) {
+	DefaultConcurrencyLimits.SetLimit(name, max)
+}
+
+// SetLimit limits the named agent to max concurrent executions; max <= 0
+// removes the limit. Executions already holding a slot keep it, and those
+// waiting under the old limit still wait for it.
+func (c *ConcurrencyLimits) SetLimit(name string, max int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if max <= 0 {
+		delete(c.limits, name)
+		return
+	}
+	c.limits[name] = &concurrencyLimit{max: max, slots: make(chan struct{}, max)}
+}
+
+// Acquire waits for a slot of the named agent, returning a context that
+// marks the slot as held and a func releasing it. Nested executions of the
+// same agent within ctx reuse the held slot instead of deadlocking, and
+// agents without a limit return immediately. It fails only when ctx is done
+// first.
+func (c *ConcurrencyLimits) Acquire(ctx context.Context, name string) (context.Context, func(), error) {
+	c.mu.RLock()
+	l := c.limits[name]
+	c.mu.RUnlock()
+	if l == nil || holdsSlot(ctx, l) {
+		return ctx, func() {}, nil
+	}
+
+	start := time.Now()
+	select {
+	case l.slots <- struct{}{}:
+	default:
+		l.waiting.Add(1)
+		select {
+		case l.slots <- struct{}{}:
+			l.waiting.Add(-1)
+			l.queued.Add(1)
+			l.totalWait.Add(int64(time.Since(start)))
+		case <-ctx.Done():
+			l.waiting.Add(-1)
+			return ctx, func() {}, fmt.Errorf("waiting for %s concurrency slot: %w", name, ctx.Err())
+		}
+	}
+	l.acquired.Add(1)
+	l.active.Add(1)
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			l.active.Add(-1)
+			<-l.slots
+		})
+	}
+	return context.WithValue(ctx, concurrencyHeldKey{}, heldSlot{l, ctx}), release, nil
+}
+
+// heldSlot links the limits held along a context chain.
+type heldSlot struct {
+	limit  *concurrencyLimit
+	parent context.Context
+}
+
+func holdsSlot(ctx context.Context, l *concurrencyLimit) bool {
+	for {
+		h, ok := ctx.Value(concurrencyHeldKey{}).(heldSlot)
+		if !ok {
+			return false
+		}
+		if h.limit == l {
+			return true
+		}
+		ctx = h.parent
+	}
+}
+
+// Stats returns each limited agent's limit and usage, keyed by name.
+func (c *ConcurrencyLimits) Stats() map[string]ConcurrencyStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	stats := make(map[string]ConcurrencyStats, len(c.limits))
+	for name, l := range c.limits {
+		stats[name] = ConcurrencyStats{
+			Limit:     l.max,
+			Active:    l.active.Load(),
+			Waiting:   l.waiting.Load(),
+			Acquired:  l.acquired.Load(),
+			Queued:    l.queued.Load(),
+			TotalWait: time.Duration(l.totalWait.Load()),
+		}
+	}
+	return stats
+}
+
+// concurrencyLimited decorates an agent so its executions take a slot.
+type concurrencyLimited struct {
+	Agent
+	limits *ConcurrencyLimits
+}
+
+// WithConcurrencyLimit sets a process-wide limit of max concurrent
+// executions for ag's name in DefaultConcurrencyLimits and wraps ag so every
+// Execute call, direct or from a workflow, waits for a slot.
+func WithConcurrencyLimit(ag Agent, max int) Agent {
+	DefaultConcurrencyLimits.SetLimit(ag.Name(), max)
+	return &concurrencyLimited{Agent: ag, limits: DefaultConcurrencyLimits}
+}
+
+func (a *concurrencyLimited) Unwrap() Agent {
+	return a.Agent
+}
+
+func (a *concurrencyLimited) Execute(ctx context.Context, task *Task) (*Result, error) {
+	ctx, release, err := a.limits.Acquire(ctx, a.Agent.Name())
+	if err != nil {
+		return &Result{TaskID: task.ID, Metadata: make(map[string]interface{}), Error: err.Error()}, err
+	}
+	defer release()
+	return a.Agent.Execute(ctx, task)
+}
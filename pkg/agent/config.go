@@ -0,0 +1,112 @@
+package agent
+
+import "time"
+
+// ResolveExecutionConfig merges configuration layers into a new config. Later
+// layers take precedence; a field left at its zero value inherits from the
+// layers before it. Nil layers are skipped.
+//
+// The resolution order used throughout the package is:
+//
+//  1. Agent defaults (e.g., LLMAgentConfig.Defaults, LLMAgentConfig.MaxTurns)
+//  2. Task.Config
+//  3. Per-call options (CallOption passed to Executor.Submit/ExecuteSync)
+func ResolveExecutionConfig(layers ...*ExecutionConfig) *ExecutionConfig {
+	resolved := &ExecutionConfig{}
+	for _, l := range layers {
+		if l == nil {
+			continue
+		}
+		if l.MaxIterations > 0 {
+			resolved.MaxIterations = l.MaxIterations
+		}
+		if l.TimeoutSeconds > 0 {
+			resolved.TimeoutSeconds = l.TimeoutSeconds
+		}
+		if l.Temperature > 0 {
+			resolved.Temperature = l.Temperature
+		}
+		if l.EnablePlan {
+			resolved.EnablePlan = true
+		}
+		if l.CallbackURL != "" {
+			resolved.CallbackURL = l.CallbackURL
+		}
+		if l.StreamingMode != StreamingModeNone {
+			resolved.StreamingMode = l.StreamingMode
+		}
+		if l.OnEvent != nil {
+			resolved.OnEvent = l.OnEvent
+		}
+		if l.UserID != "" {
+			resolved.UserID = l.UserID
+		}
+		if l.Model != "" {
+			resolved.Model = l.Model
+		}
+		if l.TraceParent != "" {
+			resolved.TraceParent, resolved.TraceState = l.TraceParent, l.TraceState
+		}
+		if len(l.Labels) > 0 {
+			resolved.Labels = mergeLabels(resolved.Labels, l.Labels)
+		}
+		if len(l.Tools) > 0 {
+			resolved.Tools = mergeTools(l.Tools, resolved.Tools)
+		}
+	}
+	return resolved
+}
+
+// CallOption overrides execution config for a single call. Call options take
+// precedence over Task.Config and agent defaults.
+type CallOption func(*ExecutionConfig)
+
+// WithMaxIterations overrides the maximum number of agent turns.
+func WithMaxIterations(n int) CallOption {
+	return func(c *ExecutionConfig) { c.MaxIterations = n }
+}
+
+// WithTimeout overrides the execution timeout.
+func WithTimeout(d time.Duration) CallOption {
+	return func(c *ExecutionConfig) { c.TimeoutSeconds = int(d / time.Second) }
+}
+
+// WithTemperature overrides the sampling temperature.
+func WithTemperature(t float32) CallOption {
+	return func(c *ExecutionConfig) { c.Temperature = t }
+}
+
+// WithEventHandler sets the event handler and streaming mode for the call.
+func WithEventHandler(mode StreamingMode, handler EventHandler) CallOption {
+	return func(c *ExecutionConfig) {
+		c.StreamingMode = mode
+		c.OnEvent = handler
+	}
+}
+
+// WithUserID sets the caller identity for the call.
+func WithUserID(id string) CallOption {
+	return func(c *ExecutionConfig) { c.UserID = id }
+}
+
+// WithModelName overrides the model name for the call.
+func WithModelName(name string) CallOption {
+	return func(c *ExecutionConfig) { c.Model = name }
+}
+
+// WithTools adds per-call tools; see ExecutionConfig.Tools.
+func WithTools(tools ...Tool) CallOption {
+	return func(c *ExecutionConfig) { c.Tools = append(c.Tools, tools...) }
+}
+
+// applyCallOptions layers call options on top of a task config.
+func applyCallOptions(cfg *ExecutionConfig, opts []CallOption) *ExecutionConfig {
+	if len(opts) == 0 {
+		return cfg
+	}
+	overrides := &ExecutionConfig{}
+	for _, opt := range opts {
+		opt(overrides)
+	}
+	return ResolveExecutionConfig(cfg, overrides)
+}
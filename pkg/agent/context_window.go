@@ -0,0 +1,284 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"unicode/utf8"
+)
+
+// ContextWindows maps model names to their context window in tokens. Names
+// match exactly or by their longest registered prefix, so "gpt-4o" also
+// covers dated versions such as "gpt-4o-2024-08-06".
+type ContextWindows struct {
+	mu     sync.RWMutex
+	limits map[string]int
+}
+
+// NewContextWindows creates a registry holding limits.
+func NewContextWindows(limits map[string]int) *ContextWindows {
+	w := &ContextWindows{limits: make(map[string]int, len(limits))}
+	for model, tokens := range limits {
+		w.limits[model] = tokens
+	}
+	return w
+}
+
+// DefaultContextWindows holds the context windows of common hosted models.
+// Override or add entries with Set, e.g. for self-hosted models.
+var DefaultContextWindows = NewContextWindows(map[string]int{
+	"gpt-3.5-turbo":     16385,
+	"gpt-4":             8192,
+	"gpt-4-turbo":       128000,
+	"gpt-4o":            128000,
+	"gpt-4.1":           1047576,
+	"o1":                200000,
+	"o3":                200000,
+	"o4-mini":           200000,
+	"claude-3":          200000,
+	"claude-sonnet-4":   200000,
+	"claude-opus-4":     200000,
+	"gemini-1.5-flash":  1048576,
+	"gemini-1.5-pro":    2097152,
+	"gemini-2.0-flash":  1048576,
+	"gemini-2.5":        1048576,
+	"mistral-large":     128000,
+	"llama3":            8192,
+	"llama-3.1":         131072,
+	"command-r":         128000,
+	"deepseek-chat":     65536,
+	"deepseek-reasoner": 65536,
+})
+
+// Set registers the context window of model; tokens <= 0 removes it.
+func (w *ContextWindows) Set(model string, tokens int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if tokens <= 0 {
+		delete(w.limits, model)
+		return
+	}
+	w.limits[model] = tokens
+}
+
+// Limit returns the context window of model.
+func (w *ContextWindows) Limit(model string) (int, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return longestPrefix(w.limits, model)
+}
+
+// ContextWindow checks the size of every LLMAgent request against the
+// model's context window before it is sent. Oversized requests are
+// compacted by Compactor when one is set, and otherwise fail with a
+// *ContextLimitError instead of an opaque error from the provider.
+type ContextWindow struct {
+	// Windows resolves the model's limit (default DefaultContextWindows).
+	Windows *ContextWindows
+
+	// Model names the provider's default model, for requests that do not
+	// set one. When neither does, the model reported by the previous turn
+	// is used; requests for unknown models are not checked.
+	Model string
+
+	// Limit fixes the context window, bypassing Windows (optional).
+	Limit int
+
+	// Reserve is kept free for the completion when the request sets no
+	// MaxTokens (default 1024).
+	Reserve int
+
+	// Estimate counts a request's prompt tokens (default EstimateTokens).
+	Estimate func(*CompletionRequest) int
+
+	// Compactor shrinks the history to fit (optional, e.g.
+	// DropOldestMessages). Metadata["context_compacted"] counts the
+	// requests it compacted.
+	Compactor Compactor
+}
+
+// Compactor shrinks history so its estimated size drops by at least excess
+// tokens, returning the new history.
+type Compactor func(ctx context.Context, history []Message, excess int) ([]Message, error)
+
+// ContextLimitError reports a request that does not fit the model's
+// context window.
+type ContextLimitError struct {
+	Agent    string
+	Model    string
+	Tokens   int // Estimated prompt tokens
+	Limit    int // Model context window
+	Reserved int // Tokens kept for the completion
+}
+
+func (e *ContextLimitError) Error() string {
+	return fmt.Sprintf("%s request for %s is ~%d tokens, over the %d-token context window with %d reserved for the completion",
+		e.Agent, e.Model, e.Tokens, e.Limit, e.Reserved)
+}
+
+// StatusCode returns the HTTP status servers should respond with.
+func (e *ContextLimitError) StatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// Per-item estimates used by EstimateTokens.
+const (
+	messageOverheadTokens = 4    // Role and separators of each message
+	fileTokens            = 1000 // Each non-text part or file
+)
+
+// EstimateTokens approximates the prompt size of req at four characters
+// per token, counting history, tool definitions, and the output schema.
+// It errs slightly high, which is the safe side for a preflight check.
+func EstimateTokens(req *CompletionRequest) int {
+	n := estimateMessages(req.History)
+	if len(req.History) == 0 {
+		n += textTokens(req.Prompt) + messageOverheadTokens + fileTokens*len(req.Files)
+	}
+	for _, t := range req.Tools {
+		schema, _ := json.Marshal(t.Schema())
+		n += textTokens(t.Name()) + textTokens(t.Description()) + textTokens(string(schema))
+	}
+	if req.OutputSchema != nil {
+		schema, _ := json.Marshal(req.OutputSchema)
+		n += textTokens(string(schema))
+	}
+	return n
+}
+
+func estimateMessages(msgs []Message) int {
+	n := 0
+	for _, m := range msgs {
+		n += messageOverheadTokens + textTokens(m.Content)
+		for _, p := range m.Parts {
+			if s, ok := p.Data.(string); ok && (p.Type == "text" || p.Type == "") {
+				n += textTokens(s)
+			} else {
+				n += fileTokens
+			}
+		}
+		for _, tc := range m.ToolCalls {
+			args, _ := json.Marshal(tc.Arguments)
+			n += textTokens(tc.Name) + textTokens(string(args))
+		}
+	}
+	return n
+}
+
+func textTokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
+
+// DropOldestMessages is a Compactor that removes the oldest turns after the
+// system message and the first user message, keeping tool calls together
+// with their results, and notes the omission in the system message. The
+// latest message is always kept.
+func DropOldestMessages(ctx context.Context, history []Message, excess int) ([]Message, error) {
+	start := 0
+	for start < len(history) && history[start].Role == RoleSystem {
+		start++
+	}
+	if start < len(history) {
+		start++ // First user message
+	}
+
+	// Drop whole turns (an assistant message and the tool results or user
+	// message after it) so the remaining messages still alternate
+	end, freed := start, 0
+	for freed < excess {
+		next := end + 1
+		for next < len(history) && history[next].Role != RoleAssistant {
+			next++
+		}
+		if next >= len(history) {
+			break
+		}
+		freed += estimateMessages(history[end:next])
+		end = next
+	}
+	if end == start {
+		return history, nil
+	}
+
+	compacted := make([]Message, 0, len(history)-(end-start))
+	compacted = append(compacted, history[:start]...)
+	compacted = withSystemNote(compacted, fmt.Sprintf("[%d earlier messages were omitted to fit the context window]", end-start))
+	return append(compacted, history[end:]...), nil
+}
+
+// withSystemNote returns a copy of history with note appended to its system
+// message, adding one if there is none.
+func withSystemNote(history []Message, note string) []Message {
+	if len(history) > 0 && history[0].Role == RoleSystem {
+		out := make([]Message, len(history))
+		copy(out, history)
+		if out[0].Content != "" {
+			note = out[0].Content + "\n\n" + note
+		}
+		out[0].Content = note
+		return out
+	}
+	return append([]Message{{Role: RoleSystem, Content: note}}, history...)
+}
+
+// fit checks req against the context window, compacting its history when
+// a Compactor is set. A nil ContextWindow accepts every request.
+func (w *ContextWindow) fit(ctx context.Context, agentName string, req *CompletionRequest, result *Result) error {
+	if w == nil {
+		return nil
+	}
+	model := req.Model
+	if model == "" {
+		model = w.Model
+	}
+	if model == "" {
+		for i := len(result.Steps) - 1; i >= 0 && model == ""; i-- {
+			model = result.Steps[i].Model
+		}
+	}
+	limit := w.Limit
+	if limit == 0 {
+		windows := w.Windows
+		if windows == nil {
+			windows = DefaultContextWindows
+		}
+		var ok bool
+		if limit, ok = windows.Limit(model); !ok {
+			return nil
+		}
+	}
+	reserve := w.Reserve
+	if req.MaxTokens != nil {
+		reserve = *req.MaxTokens
+	} else if reserve == 0 {
+		reserve = 1024
+	}
+	estimate := w.Estimate
+	if estimate == nil {
+		estimate = EstimateTokens
+	}
+
+	tokens := estimate(req)
+	if tokens+reserve <= limit {
+		return nil
+	}
+	if w.Compactor != nil {
+		history, err := w.Compactor(ctx, req.History, tokens+reserve-limit)
+		if err != nil {
+			return fmt.Errorf("compact context: %w", err)
+		}
+		count, _ := result.Metadata["context_compacted"].(int)
+		result.Metadata["context_compacted"] = count + 1
+		req.History = history
+		tokens = estimate(req)
+	}
+	if tokens+reserve > limit {
+		if model == "" {
+			model = "model"
+		}
+		return &ContextLimitError{Agent: agentName, Model: model, Tokens: tokens, Limit: limit, Reserved: reserve}
+	}
+	return nil
+}
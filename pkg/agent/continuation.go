@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+// Continuation makes LLMAgent continue answers cut off by the token limit
+// (FinishLength): the partial answer is sent back with a request to go on,
+// and the pieces are stitched into one response. Result.Metadata
+// ["continuations"] counts the extra requests; Metadata["truncated"] stays
+// set if the answer is still incomplete when a cap is reached.
+type Continuation struct {
+	MaxContinuations int    // Extra requests per turn (default 3)
+	MaxChars         int    // Cap on the stitched answer in characters (0 = no cap)
+	Prompt           string // Request to continue (default DefaultContinuationPrompt)
+}
+
+// DefaultContinuationPrompt asks the model to resume a truncated answer.
+const DefaultContinuationPrompt = "Your previous answer was cut off. Continue exactly where it stopped, without repeating anything or adding any preamble."
+
+// maxOverlap bounds how much repeated text is looked for when stitching.
+const maxOverlap = 200
+
+// extend issues continuation requests while resp is truncated, returning a
+// response with the stitched content and summed usage.
+func (c *Continuation) extend(ctx context.Context, a *LLMAgent, req *CompletionRequest, resp *ModelResponse, task *Task, result *Result) (*ModelResponse, error) {
+	maxN := c.MaxContinuations
+	if maxN == 0 {
+		maxN = 3
+	}
+	prompt := c.Prompt
+	if prompt == "" {
+		prompt = DefaultContinuationPrompt
+	}
+
+	stitched := *resp
+	var usage *TokenUsage
+	if resp.Usage != nil {
+		u := *resp.Usage
+		usage = &u
+	}
+	content := resp.Content
+	n := 0
+	for latest := resp; latest.Truncated() && len(latest.ToolCalls) == 0 && n < maxN; n++ {
+		if c.MaxChars > 0 && utf8.RuneCountInString(content) >= c.MaxChars {
+			break
+		}
+		next := *req
+		next.History = append(append([]Message(nil), req.History...),
+			Message{Role: RoleAssistant, Content: content},
+			Message{Role: RoleUser, Content: prompt},
+		)
+		var err error
+		if latest, err = a.complete(ctx, &next, task); err != nil {
+			return nil, err
+		}
+		content = stitch(content, latest.Content)
+		if latest.Usage != nil {
+			if usage == nil {
+				usage = &TokenUsage{}
+			}
+			usage.PromptTokens += latest.Usage.PromptTokens
+			usage.CompletionTokens += latest.Usage.CompletionTokens
+			usage.TotalTokens += latest.Usage.TotalTokens
+			usage.ReasoningTokens += latest.Usage.ReasoningTokens
+		}
+		stitched.ToolCalls = latest.ToolCalls
+		stitched.Finished = latest.Finished
+		stitched.FinishReason = latest.Reason()
+	}
+	if c.MaxChars > 0 && utf8.RuneCountInString(content) > c.MaxChars {
+		content = string([]rune(content)[:c.MaxChars])
+	}
+	if n > 0 {
+		count, _ := result.Metadata["continuations"].(int)
+		result.Metadata["continuations"] = count + n
+	}
+	stitched.Content = content
+	stitched.Usage = usage
+	return &stitched, nil
+}
+
+// stitch appends next to prev, dropping text at the start of next that
+// repeats the end of prev.
+func stitch(prev, next string) string {
+	limit := min(len(prev), len(next), maxOverlap)
+	for n := limit; n > 0; n-- {
+		if strings.HasSuffix(prev, next[:n]) {
+			return prev + next[n:]
+		}
+	}
+	return prev + next
+}
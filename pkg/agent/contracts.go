@@ -0,0 +1,212 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// OutputContract is implemented by agents that declare the shape of the
+// Result.Output they produce.
+type OutputContract interface {
+	OutputSchema() map[string]interface{}
+}
+
+// InputContract is implemented by agents that declare the shape of the
+// Task.Input they accept.
+type InputContract interface {
+	InputSchema() map[string]interface{}
+}
+
+// ParamsContract is implemented by agents that declare a JSON schema for
+// Task.Params.
+type ParamsContract interface {
+	ParamsSchema() map[string]interface{}
+}
+
+// ErrInvalidParams is matched (via errors.Is) by every *ParamsError.
+var ErrInvalidParams = errors.New("invalid params")
+
+// ParamsError reports task params rejected by an agent's params schema.
+type ParamsError struct {
+	Agent string
+	Err   error
+}
+
+func (e *ParamsError) Error() string {
+	return fmt.Sprintf("invalid params for %s: %v", e.Agent, e.Err)
+}
+
+func (e *ParamsError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ParamsError) Is(target error) bool {
+	return target == ErrInvalidParams
+}
+
+// StatusCode returns the HTTP status servers should respond with.
+func (e *ParamsError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// ValidateParams checks params against the agent's params schema, if it
+// declares one.
+func ValidateParams(ag Agent, params map[string]interface{}) error {
+	found := unwrapTo(ag, func(a Agent) bool { _, ok := a.(ParamsContract); return ok })
+	if found == nil {
+		return nil
+	}
+	c := found.(ParamsContract)
+	if c.ParamsSchema() == nil {
+		return nil
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	if err := ValidateSchema(c.ParamsSchema(), params); err != nil {
+		return &ParamsError{Agent: ag.Name(), Err: err}
+	}
+	return nil
+}
+
+// ContractError reports a mismatch between what one stage produces and what
+// the next stage accepts. Consumer is empty when a stage's output violates its
+// own declared output schema.
+type ContractError struct {
+	Producer string
+	Consumer string
+	Err      error
+}
+
+func (e *ContractError) Error() string {
+	if e.Consumer == "" {
+		return fmt.Sprintf("output of %s violates its schema: %v", e.Producer, e.Err)
+	}
+	return fmt.Sprintf("output of %s does not satisfy input of %s: %v", e.Producer, e.Consumer, e.Err)
+}
+
+func (e *ContractError) Unwrap() error {
+	return e.Err
+}
+
+// Wrapper is implemented by decorator agents so that optional interfaces of
+// the wrapped agent (contracts, compensation) remain discoverable.
+type Wrapper interface {
+	Unwrap() Agent
+}
+
+// unwrapTo returns the first agent in ag's wrapper chain that satisfies match.
+func unwrapTo(ag Agent, match func(Agent) bool) Agent {
+	for ag != nil {
+		if match(ag) {
+			return ag
+		}
+		w, ok := ag.(Wrapper)
+		if !ok {
+			return nil
+		}
+		ag = w.Unwrap()
+	}
+	return nil
+}
+
+func outputSchemaOf(ag Agent) map[string]interface{} {
+	found := unwrapTo(ag, func(a Agent) bool { _, ok := a.(OutputContract); return ok })
+	if found == nil {
+		return nil
+	}
+	return found.(OutputContract).OutputSchema()
+}
+
+func inputSchemaOf(ag Agent) map[string]interface{} {
+	found := unwrapTo(ag, func(a Agent) bool { _, ok := a.(InputContract); return ok })
+	if found == nil {
+		return nil
+	}
+	return found.(InputContract).InputSchema()
+}
+
+// CheckContracts statically verifies that each stage's declared output schema
+// is compatible with the next stage's declared input schema. Stages without a
+// declared schema on either side are not checked.
+func CheckContracts(stages []Agent) error {
+	for i := 0; i+1 < len(stages); i++ {
+		out := outputSchemaOf(stages[i])
+		in := inputSchemaOf(stages[i+1])
+		if out == nil || in == nil {
+			continue
+		}
+		if err := schemaCompatible(out, in, "$"); err != nil {
+			return &ContractError{Producer: stages[i].Name(), Consumer: stages[i+1].Name(), Err: err}
+		}
+	}
+	return nil
+}
+
+// schemaCompatible reports whether every value valid under producer is
+// plausibly valid under consumer: types must overlap and properties the
+// consumer requires must be guaranteed by the producer.
+func schemaCompatible(producer, consumer map[string]interface{}, path string) error {
+	pt, ct := schemaTypes(producer), schemaTypes(consumer)
+	if len(pt) > 0 && len(ct) > 0 {
+		overlap := false
+		for _, p := range pt {
+			if containsAny(ct, p) || (p == "integer" && containsAny(ct, "number")) {
+				overlap = true
+				break
+			}
+		}
+		// A string producer may carry JSON for an object/array consumer
+		if !overlap && !(containsAny(pt, "string") && containsAny(ct, "object", "array")) {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("produces %v, expects %v", pt, ct)}
+		}
+	}
+
+	if len(schemaTypes(producer)) == 0 || !containsAny(pt, "object") {
+		return nil
+	}
+
+	produced := map[string]bool{}
+	for _, key := range stringList(producer["required"]) {
+		produced[key] = true
+	}
+	for _, key := range stringList(consumer["required"]) {
+		if !produced[key] {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("required property %q is not guaranteed by producer", key)}
+		}
+	}
+
+	pProps, _ := producer["properties"].(map[string]interface{})
+	cProps, _ := consumer["properties"].(map[string]interface{})
+	for key, c := range cProps {
+		p, ok := pProps[key].(map[string]interface{})
+		cs, ok2 := c.(map[string]interface{})
+		if !ok || !ok2 {
+			continue
+		}
+		if err := schemaCompatible(p, cs, path+"."+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStageOutput validates a stage's output against its own output schema
+// and, if next is non-nil, against the next stage's input schema.
+func checkStageOutput(stage, next Agent, output interface{}) error {
+	if schema := outputSchemaOf(stage); schema != nil {
+		if err := ValidateSchema(schema, output); err != nil {
+			return &ContractError{Producer: stage.Name(), Err: err}
+		}
+	}
+	if next == nil {
+		return nil
+	}
+	if schema := inputSchemaOf(next); schema != nil {
+		if err := ValidateSchema(schema, output); err != nil {
+			return &ContractError{Producer: stage.Name(), Consumer: next.Name(), Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AsyncDelegation lets an LLMAgent start its sub-agents in the background and
+// keep working while they run. The agent gets two tools: "delegate_async"
+// starts a sub-agent on an input and returns a delegation ID, and
+// "check_delegation" reports whether it finished and, once it has, its
+// output. Delegations run on a copy of the task state; their steps and
+// usage join the agent's result when checked. Delegations still running
+// when the agent finishes are cancelled.
+type AsyncDelegation struct {
+	MaxPending int           // Delegations running at once (default 4)
+	MaxWait    time.Duration // Longest check_delegation may block (default 30s)
+}
+
+// DelegationStatus is the result of the delegation tools.
+type DelegationStatus struct {
+	ID     string      `json:"id"`
+	Agent  string      `json:"agent"`
+	Status string      `json:"status"` // "running", "completed", or "failed"
+	Output interface{} `json:"output,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+func (s *DelegationStatus) String() string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// ErrTooManyDelegations is returned by delegate_async while MaxPending
+// delegations are running.
+var ErrTooManyDelegations = errors.New("too many delegations running; check one before starting another")
+
+// delegation is one background sub-agent run.
+type delegation struct {
+	id     string
+	agent  Agent
+	done   chan struct{}
+	result *Result
+	err    error
+	merged bool
+}
+
+// delegations tracks the background runs of one LLMAgent execution.
+type delegations struct {
+	cfg    AsyncDelegation
+	ctx    context.Context
+	cancel context.CancelFunc
+	task   *Task
+	agents []Agent
+
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	runs   []*delegation
+	joined []*delegation // Finished and reported, not yet merged into the result
+}
+
+// start prepares the delegations of an execution; stop must be called when
+// it ends.
+func (d *AsyncDelegation) start(ctx context.Context, task *Task, agents []Agent) *delegations {
+	cfg := *d
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = 4
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = 30 * time.Second
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	// Create the lineage map before copying so delegations share it
+	task.lineageMap()
+	return &delegations{cfg: cfg, ctx: ctx, cancel: cancel, task: task, agents: agents}
+}
+
+// stop cancels delegations that are still running and waits for them.
+func (ds *delegations) stop() {
+	if ds == nil {
+		return
+	}
+	ds.cancel()
+	ds.wg.Wait()
+}
+
+// merge folds the delegations reported since the last call into result.
+func (ds *delegations) merge(ctx context.Context, a *LLMAgent, result *Result) {
+	if ds == nil {
+		return
+	}
+	ds.mu.Lock()
+	joined := ds.joined
+	ds.joined = nil
+	ds.mu.Unlock()
+	for _, run := range joined {
+		a.mergeDelegated(ctx, result, run.result)
+	}
+	if len(joined) > 0 {
+		count, _ := result.Metadata["delegations_joined"].(int)
+		result.Metadata["delegations_joined"] = count + len(joined)
+	}
+}
+
+func (ds *delegations) launch(agentName, input string) (*DelegationStatus, error) {
+	var ag Agent
+	for _, sub := range ds.agents {
+		if sub.Name() == agentName {
+			ag = sub
+		}
+	}
+	if ag == nil {
+		return nil, fmt.Errorf("unknown agent %q", agentName)
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	pending := 0
+	for _, run := range ds.runs {
+		select {
+		case <-run.done:
+		default:
+			pending++
+		}
+	}
+	if pending >= ds.cfg.MaxPending {
+		return nil, ErrTooManyDelegations
+	}
+
+	run := &delegation{id: fmt.Sprintf("delegation-%d", len(ds.runs)+1), agent: ag, done: make(chan struct{})}
+	ds.runs = append(ds.runs, run)
+
+	// Each delegation gets its own task and state copy
+	taskCopy := *ds.task
+	taskCopy.Input = input
+	taskCopy.State = copyMap(ds.task.State)
+
+	ds.wg.Add(1)
+	go func() {
+		defer ds.wg.Done()
+		defer close(run.done)
+		defer recoverPanic(&run.err)
+		run.result, run.err = ag.Execute(ds.ctx, &taskCopy)
+	}()
+	return &DelegationStatus{ID: run.id, Agent: ag.Name(), Status: "running"}, nil
+}
+
+// check reports a delegation, waiting up to wait for it to finish.
+func (ds *delegations) check(ctx context.Context, id string, wait time.Duration) (*DelegationStatus, error) {
+	ds.mu.Lock()
+	var run *delegation
+	for _, r := range ds.runs {
+		if r.id == id {
+			run = r
+		}
+	}
+	ds.mu.Unlock()
+	if run == nil {
+		return nil, fmt.Errorf("unknown delegation %q", id)
+	}
+
+	status := &DelegationStatus{ID: run.id, Agent: run.agent.Name(), Status: "running"}
+
+	// A finished run must not race a zero wait in the select below
+	select {
+	case <-run.done:
+	default:
+		timer := time.NewTimer(min(wait, ds.cfg.MaxWait))
+		defer timer.Stop()
+		select {
+		case <-run.done:
+		case <-timer.C:
+			return status, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	ds.mu.Lock()
+	if !run.merged {
+		run.merged = true
+		ds.joined = append(ds.joined, run)
+	}
+	ds.mu.Unlock()
+	switch {
+	case run.err != nil:
+		status.Status = "failed"
+		status.Error = run.err.Error()
+	case run.result != nil && !run.result.Success:
+		status.Status = "failed"
+		status.Error = run.result.Error
+	default:
+		// An agent may return neither a result nor an error; report no output
+		status.Status = "completed"
+		if run.result != nil {
+			status.Output = run.result.Output
+		}
+	}
+	return status, nil
+}
+
+// tools returns the delegate_async and check_delegation tools.
+func (ds *delegations) tools() []Tool {
+	names := make([]string, len(ds.agents))
+	for i, sub := range ds.agents {
+		names[i] = sub.Name()
+	}
+	delegate := NewFuncTool("delegate_async",
+		"Start a sub-agent on a task in the background and continue without waiting. Returns a delegation ID; call check_delegation with it to get the result.",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"agent": map[string]interface{}{"type": "string", "enum": names, "description": "The sub-agent to start"},
+				"input": map[string]interface{}{"type": "string", "description": "The task for the sub-agent"},
+			},
+			"required": []string{"agent", "input"},
+		},
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			name, _ := args["agent"].(string)
+			input, _ := args["input"].(string)
+			return ds.launch(name, input)
+		})
+	check := NewFuncTool("check_delegation",
+		"Check on a delegation started with delegate_async. Returns its status, and its output once completed. Set wait_seconds to wait for it to finish.",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id":           map[string]interface{}{"type": "string", "description": "The delegation ID"},
+				"wait_seconds": map[string]interface{}{"type": "number", "description": "How long to wait for the delegation to finish (default 0)"},
+			},
+			"required": []string{"id"},
+		},
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			id, _ := args["id"].(string)
+			seconds, _ := args["wait_seconds"].(float64)
+			return ds.check(ctx, id, time.Duration(seconds*float64(time.Second)))
+		})
+	return []Tool{delegate, check}
+}
@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Units is a measurement system the model should answer in.
+type Units string
+
+const (
+	UnitsMetric   Units = "metric"
+	UnitsImperial Units = "imperial"
+)
+
+// Environment tells the model the current date and time, timezone, locale,
+// and preferred units, which it cannot know on its own. Tasks override the
+// defaults per user through Params["timezone"] (an IANA name such as
+// "Europe/Berlin"), Params["locale"], and Params["units"].
+//
+// Prompts can place the values with the {current_date}, {current_time},
+// {current_weekday}, {timezone}, {locale}, and {units} placeholders; if the
+// prompt uses none of them, a short context paragraph is appended instead.
+type Environment struct {
+	Location *time.Location   // Default timezone (default time.Local)
+	Locale   string           // Default BCP 47 locale, e.g. "en-GB" (optional)
+	Units    Units            // Default measurement system (optional)
+	DateOnly bool             // Omit the time of day, e.g. for prompt caching
+	Now      func() time.Time // Clock (default time.Now)
+
+	// Format renders the appended paragraph (default DefaultEnvironmentFormat).
+	Format func(EnvironmentInfo) string
+}
+
+// EnvironmentInfo is the environment resolved for one execution.
+type EnvironmentInfo struct {
+	Now      time.Time // In the resolved timezone
+	DateOnly bool
+	Locale   string
+	Units    Units
+}
+
+// DefaultEnvironmentFormat renders e.g. "Current date and time: Wednesday,
+// 14 October 2026, 09:30 CEST (Europe/Berlin). Locale: de-DE. Use metric
+// units."
+func DefaultEnvironmentFormat(info EnvironmentInfo) string {
+	var b strings.Builder
+	if info.DateOnly {
+		fmt.Fprintf(&b, "Current date: %s (%s).", info.Now.Format("Monday, 2 January 2006"), info.Now.Location())
+	} else {
+		fmt.Fprintf(&b, "Current date and time: %s (%s).", info.Now.Format("Monday, 2 January 2006, 15:04 MST"), info.Now.Location())
+	}
+	if info.Locale != "" {
+		fmt.Fprintf(&b, " Locale: %s.", info.Locale)
+	}
+	if info.Units != "" {
+		fmt.Fprintf(&b, " Use %s units.", info.Units)
+	}
+	return b.String()
+}
+
+// Resolve returns the environment for a task, applying its Params
+// overrides. An unknown timezone name falls back to the default.
+func (e *Environment) Resolve(task *Task) EnvironmentInfo {
+	now := time.Now
+	if e.Now != nil {
+		now = e.Now
+	}
+	loc := e.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	info := EnvironmentInfo{DateOnly: e.DateOnly, Locale: e.Locale, Units: e.Units}
+	if task != nil {
+		if name, ok := task.Params["timezone"].(string); ok && name != "" {
+			if l, err := time.LoadLocation(name); err == nil {
+				loc = l
+			}
+		}
+		if locale, ok := task.Params["locale"].(string); ok && locale != "" {
+			info.Locale = locale
+		}
+		if units, ok := task.Params["units"].(string); ok && units != "" {
+			info.Units = Units(units)
+		}
+	}
+	info.Now = now().In(loc)
+	return info
+}
+
+// apply substitutes the environment placeholders in prompt, or appends the
+// formatted paragraph when there are none.
+func (e *Environment) apply(prompt string, task *Task) string {
+	info := e.Resolve(task)
+	clock := "15:04 MST"
+	if info.DateOnly {
+		clock = "MST"
+	}
+	r := strings.NewReplacer(
+		"{current_date}", info.Now.Format("2006-01-02"),
+		"{current_time}", info.Now.Format(clock),
+		"{current_weekday}", info.Now.Weekday().String(),
+		"{timezone}", info.Now.Location().String(),
+		"{locale}", info.Locale,
+		"{units}", string(info.Units),
+	)
+	if replaced := r.Replace(prompt); replaced != prompt {
+		return replaced
+	}
+	format := e.Format
+	if format == nil {
+		format = DefaultEnvironmentFormat
+	}
+	if prompt == "" {
+		return format(info)
+	}
+	return prompt + "\n\n" + format(info)
+}
@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+)
+
+// Escalated reports whether an agent in the execution requested escalation.
+func (r *Result) Escalated() bool {
+	return r.Actions != nil && r.Actions.Escalate
+}
+
+// propagateEscalation copies an escalation from a sub-result into the parent
+// result so it keeps bubbling towards the root. It reports whether the
+// sub-result escalated.
+func propagateEscalation(result, subResult *Result) bool {
+	if subResult == nil || !subResult.Escalated() {
+		return false
+	}
+	result.Actions = subResult.Actions
+	result.Error = subResult.Error
+	result.Success = false
+	return true
+}
+
+// markEscalated records an escalation on the result and emits an escalation
+// event for the agent that raised it.
+func markEscalated(ctx context.Context, task *Task, result *Result, agentName string, actions *EventActions) {
+	actions.EscalatedBy = agentName
+	result.Actions = actions
+	result.Success = false
+	result.Error = fmt.Sprintf("escalated by %s: %s", agentName, actions.EscalationReason)
+
+	ev := newEvent(ctx, task.ID, agentName, EventEscalation)
+	ev.AgentPath = pathFor(ctx, agentName)
+	ev.Content = actions.EscalationReason
+	ev.Actions = actions
+	emit(task, ev)
+}
+
+// EscalateTool lets an LLM agent signal that it cannot complete the task.
+// Workflow agents stop their sequence when a child escalates and pass the
+// escalation up to their own caller.
+type EscalateTool struct{}
+
+// NewEscalateTool creates a tool named "escalate" taking a "reason" argument.
+func NewEscalateTool() *EscalateTool {
+	return &EscalateTool{}
+}
+
+func (t *EscalateTool) Name() string {
+	return "escalate"
+}
+
+func (t *EscalateTool) Description() string {
+	return "Escalate to the supervising agent when you cannot complete the task. Explain why in reason."
+}
+
+func (t *EscalateTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"reason": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"reason"},
+	}
+}
+
+// Execute returns an *EventActions result, which LLMAgent treats as a request
+// to escalate rather than as ordinary tool output.
+func (t *EscalateTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	reason, _ := args["reason"].(string)
+	return &EventActions{Escalate: true, EscalationReason: reason}, nil
+}
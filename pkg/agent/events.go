@@ -0,0 +1,231 @@
+package agent
+
+import (
+	"context"
+	"time"
+)
+
+// EventType identifies the kind of an Event.
+type EventType string
+
+const (
+	EventPartial    EventType = "partial"     // Partial content delta from a streaming model
+	EventStep       EventType = "step"        // Completed execution step on the Task path
+	EventResponse   EventType = "response"    // Response from a SessionAgent
+	EventEscalation EventType = "escalation"  // A sub-agent escalated; see Actions.EscalationReason
+	EventBudget     EventType = "budget"      // Remaining budget at the start of a turn; see Budget
+	EventProgress   EventType = "progress"    // Progress reported by a tool or agent; see Progress
+	EventJob        EventType = "job"         // Executor job status change; Action holds the JobStatus
+	EventNeedsInput EventType = "needs_input" // The agent asked the user a question; see Question
+	EventUsage      EventType = "usage"       // Running usage and cost after a model call; see UsageReport
+)
+
+// Event is the single record shape emitted by both the Task/Result path and
+// the Session/Invocation/Response path, so persistence, streaming, and
+// observability only have to handle one type.
+type Event struct {
+	ID        string
+	TaskID    string // Task ID or session ID the event belongs to
+	Author    string // Name of the agent that produced the event
+	AgentPath string // Hierarchical path of the author, e.g. "root/researcher"
+	Type      EventType
+	Timestamp time.Time
+	Partial   bool // True for streaming deltas; Content holds only the delta
+
+	Action    string
+	Input     interface{}
+	Content   string
+	Reasoning string
+	Output    interface{}
+	ToolCalls []ToolCall
+	Artifacts []Artifact
+	Actions   *EventActions
+	Error     string
+	Finished  bool
+
+	Duration     time.Duration
+	LLMLatency   time.Duration
+	ToolsLatency time.Duration
+	Usage        *TokenUsage
+	Model        string // Model that served the step, if reported
+	Routing      string // Model routing decision, if any
+	FinishReason FinishReason
+	Budget       *BudgetStatus
+	Progress     *Progress
+	Question     *InputRequest
+	UsageReport  *UsageReport      // Running totals of the task, on EventUsage events
+	Labels       map[string]string // Task labels, on EventJob events
+}
+
+// EventHandler receives events as they are emitted.
+type EventHandler func(*Event)
+
+// newEvent creates an event with an ID and timestamp from ctx's IDGenerator
+// and Clock.
+func newEvent(ctx context.Context, taskID, author string, typ EventType) *Event {
+	return &Event{
+		ID:        NewID(ctx),
+		TaskID:    taskID,
+		Author:    author,
+		Type:      typ,
+		Timestamp: Now(ctx),
+	}
+}
+
+// EventFromStep converts an ExecutionStep into an Event.
+func EventFromStep(taskID string, step ExecutionStep) *Event {
+	return eventFromStep(context.Background(), taskID, step)
+}
+
+func eventFromStep(ctx context.Context, taskID string, step ExecutionStep) *Event {
+	ev := newEvent(ctx, taskID, step.AgentName, EventStep)
+	ev.AgentPath = step.AgentPath
+	ev.Timestamp = step.Timestamp
+	ev.Action = step.Action
+	ev.Input = step.Input
+	ev.Output = step.Output
+	if s, ok := step.Output.(string); ok {
+		ev.Content = s
+	}
+	ev.Reasoning = step.Reasoning
+	ev.ToolCalls = step.ToolCalls
+	ev.Error = step.Error
+	ev.Duration = step.Duration
+	ev.LLMLatency = step.LLMLatency
+	ev.ToolsLatency = step.ToolsLatency
+	ev.Usage = step.TokenUsage
+	ev.Model = step.Model
+	ev.Routing = step.Routing
+	ev.FinishReason = step.FinishReason
+	if len(step.StateDelta) > 0 {
+		ev.Actions = &EventActions{StateDelta: step.StateDelta}
+	}
+	return ev
+}
+
+// Step converts the event back into an ExecutionStep.
+func (e *Event) Step() ExecutionStep {
+	step := ExecutionStep{
+		AgentName:    e.Author,
+		AgentPath:    e.AgentPath,
+		Action:       e.Action,
+		Input:        e.Input,
+		Output:       e.Output,
+		Error:        e.Error,
+		Duration:     e.Duration,
+		LLMLatency:   e.LLMLatency,
+		ToolsLatency: e.ToolsLatency,
+		Timestamp:    e.Timestamp,
+		TokenUsage:   e.Usage,
+		Reasoning:    e.Reasoning,
+		ToolCalls:    e.ToolCalls,
+		Model:        e.Model,
+		Routing:      e.Routing,
+		FinishReason: e.FinishReason,
+	}
+	if step.Output == nil && e.Content != "" {
+		step.Output = e.Content
+	}
+	if e.Actions != nil {
+		step.StateDelta = e.Actions.StateDelta
+	}
+	return step
+}
+
+// EventFromResponse converts a SessionAgent Response into an Event.
+func EventFromResponse(sessionID, author string, resp *Response) *Event {
+	ev := newEvent(context.Background(), sessionID, author, EventResponse)
+	ev.Content = resp.Content
+	ev.Output = resp.Content
+	ev.ToolCalls = resp.ToolCalls
+	ev.Artifacts = resp.Artifacts
+	ev.Actions = resp.Actions
+	ev.Finished = resp.Finished
+	ev.FinishReason = resp.FinishReason
+	return ev
+}
+
+// Response converts the event into a SessionAgent Response.
+func (e *Event) Response() *Response {
+	return &Response{
+		Content:      e.Content,
+		ToolCalls:    e.ToolCalls,
+		Artifacts:    e.Artifacts,
+		Actions:      e.Actions,
+		Finished:     e.Finished,
+		FinishReason: e.FinishReason,
+	}
+}
+
+// Events returns the result's execution steps as events.
+func (r *Result) Events() []*Event {
+	events := make([]*Event, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		events = append(events, EventFromStep(r.TaskID, step))
+	}
+	return events
+}
+
+// withEvents returns a copy of the task config whose event handler also
+// calls handlers, preserving any caller handler.
+func withEvents(cfg *ExecutionConfig, handlers []EventHandler) *ExecutionConfig {
+	if len(handlers) == 0 {
+		return cfg
+	}
+	var c ExecutionConfig
+	if cfg != nil {
+		c = *cfg
+	}
+	next := c.OnEvent
+	c.OnEvent = func(ev *Event) {
+		for _, h := range handlers {
+			h(ev)
+		}
+		if next != nil {
+			next(ev)
+		}
+	}
+	return &c
+}
+
+// jobEvent reports a job's status. Finished jobs carry their output, error,
+// token usage, and total duration.
+func jobEvent(ctx context.Context, job *Job, author string) *Event {
+	ev := newEvent(ctx, job.Task.ID, author, EventJob)
+	ev.AgentPath = author
+	ev.Action = string(job.Status())
+	ev.Labels = job.Labels()
+	completedAt, ok := job.CompletedAt()
+	if !ok {
+		return ev
+	}
+	ev.Finished = true
+	ev.Duration = completedAt.Sub(job.Task.StartedAt)
+	if job.Error != nil {
+		ev.Error = job.Error.Error()
+	}
+	if job.Result != nil {
+		ev.Output = job.Result.Output
+		usage := job.Result.TotalTokenUsage
+		ev.Usage = &usage
+	}
+	return ev
+}
+
+// emit delivers an event to the handler configured on the task, if any.
+func emit(task *Task, ev *Event) {
+	if task.Config != nil && task.Config.OnEvent != nil {
+		task.Config.OnEvent(ev)
+	}
+}
+
+// recordStep appends a step to the result and emits it as an event. The
+// step's agent path is derived from ctx when not already set.
+func recordStep(ctx context.Context, task *Task, result *Result, step ExecutionStep) {
+	if step.AgentPath == "" {
+		step.AgentPath = pathFor(ctx, step.AgentName)
+	}
+	result.Steps = append(result.Steps, step)
+	emit(task, eventFromStep(ctx, task.ID, step))
+	meterStep(ctx, step)
+}
@@ -2,185 +2,394 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 // Executor manages async task execution with a pool of workers.
 type Executor struct {
 	agent       Agent
-	jobs        map[string]*Job
-	mu          sync.RWMutex
+	jobs        *jobStore
 	workerCount int
 	jobQueue    chan *Job
+	counters    *executorCounters
+	stall       *stallConfig
+	onJobDone   []func(job *Job)
+	toolMetrics *ToolMetrics
+	sinks       sinkConfig
+	onPanic     func(job *Job, err *PanicError)
+
+	warmup        warmupState
+	warmupTimeout time.Duration
+	checkpoints   CheckpointStore
+	maintenance   *Maintenance
+	workspace     *WorkspaceConfig
+	onEvent       []EventHandler
+	concurrency   *ConcurrencyLimits
+	ids           IDGenerator
+	clock         Clock
+	pricing       *Pricing
 }
 
 // Job represents a submitted task and its execution state.
 type Job struct {
 	Task   *Task
 	Result *Result
-	Status JobStatus
 	Error  error
+
+	status          atomic.Value // JobStatus; read without locking by pollers
+	lastHeartbeat   atomic.Int64 // Unix nanos of the last event
+	cancel          context.CancelFunc
+	stallCancelled  atomic.Bool
+	cancelRequested atomic.Bool
+	done            chan struct{} // Closed once the job is completed or failed
+	resume          *resumeState  // Set for jobs started by ResumeFrom
+	progress        atomic.Pointer[Progress]
+	usage           atomic.Pointer[UsageReport]
+	input           atomic.Pointer[pendingInput]
+	doneOnce        sync.Once
 }
 
 // JobStatus represents the lifecycle state of a job.
 type JobStatus string
 
 const (
-	JobPending   JobStatus = "pending"
-	JobRunning   JobStatus = "running"
-	JobCompleted JobStatus = "completed"
-	JobFailed    JobStatus = "failed"
+	JobPending    JobStatus = "pending"
+	JobRunning    JobStatus = "running"
+	JobCompleted  JobStatus = "completed"
+	JobFailed     JobStatus = "failed"
+	JobStalled    JobStatus = "stalled"     // Running, but no heartbeat within the stall interval
+	JobNeedsInput JobStatus = "needs_input" // Running, waiting for the user to answer a question
 )
 
+// Status returns the job's current lifecycle state.
+func (j *Job) Status() JobStatus {
+	s, _ := j.status.Load().(JobStatus)
+	return s
+}
+
+// CompletedAt returns when the job finished, or false while it is pending or
+// running. Unlike reading Task.CompletedAt, it is safe while the job runs:
+// the time is written before the terminal status is stored.
+func (j *Job) CompletedAt() (time.Time, bool) {
+	switch j.Status() {
+	case JobCompleted, JobFailed:
+		return j.Task.CompletedAt, true
+	}
+	return time.Time{}, false
+}
+
 // NewExecutor creates a new Executor with the given agent and worker pool size.
-func NewExecutor(agent Agent, workerCount int) *Executor {
+func NewExecutor(agent Agent, workerCount int, opts ...ExecutorOption) *Executor {
 	if workerCount == 0 {
 		workerCount = 5
 	}
 
 	ex := &Executor{
 		agent:       agent,
-		jobs:        make(map[string]*Job),
+		jobs:        newJobStore(),
 		workerCount: workerCount,
 		jobQueue:    make(chan *Job, 100),
+		counters:    newExecutorCounters(),
+		toolMetrics: DefaultToolMetrics,
+		concurrency: DefaultConcurrencyLimits,
+		sinks:       sinkConfig{timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(ex)
+	}
+	if ex.maintenance == nil {
+		ex.maintenance = NewMaintenance()
+	}
+
+	if ex.warmup.gate != nil {
+		go ex.startWarmup()
 	}
 
 	// Start workers
 	for i := 0; i < workerCount; i++ {
 		go ex.worker()
 	}
+	if ex.stall != nil && ex.stall.interval > 0 {
+		go ex.monitorStalls()
+	}
 
 	return ex
 }
 
 // Submit creates and queues a new job, returning the task ID for tracking.
-func (e *Executor) Submit(input string, params map[string]interface{}, config *ExecutionConfig) (string, error) {
-	taskID := uuid.New().String()
+// Params are validated against the agent's params schema first; a rejected
+// submission returns a *ParamsError and no job is created. While the
+// Executor is paused it returns a *MaintenanceError. Call options override
+// fields of config; see ResolveExecutionConfig.
+func (e *Executor) Submit(input string, params map[string]interface{}, config *ExecutionConfig, opts ...CallOption) (string, error) {
+	if err := e.maintenance.check(e.agent.Name()); err != nil {
+		return "", err
+	}
+	if err := ValidateParams(e.agent, params); err != nil {
+		return "", err
+	}
+
+	task := e.newTask(input, params, applyCallOptions(config, opts))
+	return e.enqueue(task, nil), nil
+}
 
+// newTask creates a task with a fresh ID whose state starts as a copy of
+// params.
+func (e *Executor) newTask(input string, params map[string]interface{}, config *ExecutionConfig) *Task {
+	ctx := e.withClock(context.Background())
 	task := &Task{
-		ID:        taskID,
+		ID:        NewID(ctx),
 		Input:     input,
 		Params:    params,
 		State:     make(map[string]interface{}),
 		Config:    config,
-		StartedAt: time.Now(),
+		StartedAt: Now(ctx),
 	}
 
 	// Copy params to state
 	for k, v := range params {
 		task.State[k] = v
 	}
+	return task
+}
 
+// enqueue registers a job for task and queues it for execution.
+func (e *Executor) enqueue(task *Task, resume *resumeState) string {
 	job := &Job{
 		Task:   task,
-		Status: JobPending,
+		done:   make(chan struct{}),
+		resume: resume,
 	}
-
-	e.mu.Lock()
-	e.jobs[taskID] = job
-	e.mu.Unlock()
+	job.status.Store(JobPending)
+	e.jobs.put(task.ID, job)
 
 	// Queue for execution
+	e.counters.queued.Add(1)
 	e.jobQueue <- job
 
-	return taskID, nil
+	return task.ID
+}
+
+// Job returns the job of a task.
+func (e *Executor) Job(taskID string) (*Job, error) {
+	job, ok := e.jobs.get(taskID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, taskID)
+	}
+	return job, nil
 }
 
 // GetStatus returns the current status of a job.
 func (e *Executor) GetStatus(taskID string) (JobStatus, error) {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
-
-	job, ok := e.jobs[taskID]
+	job, ok := e.jobs.get(taskID)
 	if !ok {
-		return "", fmt.Errorf("task not found: %s", taskID)
+		return "", fmt.Errorf("%w: %s", ErrJobNotFound, taskID)
 	}
 
-	return job.Status, nil
+	return job.Status(), nil
 }
 
 // GetResult returns the job result. It blocks until the job is complete.
 func (e *Executor) GetResult(taskID string) (*Result, error) {
-	e.mu.RLock()
-	job, ok := e.jobs[taskID]
-	e.mu.RUnlock()
-
+	job, ok := e.jobs.get(taskID)
 	if !ok {
-		return nil, fmt.Errorf("task not found: %s", taskID)
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, taskID)
 	}
 
-	// Poll until complete
-	for {
-		e.mu.RLock()
-		status := job.Status
-		e.mu.RUnlock()
-
-		if status == JobCompleted {
-			return job.Result, nil
-		}
-		if status == JobFailed {
-			return job.Result, job.Error
-		}
-
-		time.Sleep(100 * time.Millisecond)
-	}
+	<-job.done
+	return job.Result, job.Error
 }
 
-// worker processes jobs from the queue.
+// worker processes jobs from the queue. Agent panics fail only their job;
+// a panic anywhere else (e.g. in a hook or sink) fails the job if it has
+// not finished yet, and the worker is replaced so the pool keeps its size.
 func (e *Executor) worker() {
+	var current *Job
+	var start time.Time
+	defer func() {
+		if r := recover(); r != nil {
+			e.failPanicked(current, start, newPanicError(r))
+			go e.worker()
+		}
+	}()
+	if e.warmup.gate != nil {
+		<-e.warmup.gate
+	}
 	for job := range e.jobQueue {
+		current, start = job, time.Now()
 		e.executeJob(job)
 	}
 }
 
-func (e *Executor) executeJob(job *Job) {
-	// Update status
-	e.mu.Lock()
-	job.Status = JobRunning
-	e.mu.Unlock()
+// failPanicked handles a panic that escaped executeJob. A job that had not
+// finished is failed with the panic so waiters and pollers are released;
+// otherwise the panic is only counted and reported.
+func (e *Executor) failPanicked(job *Job, start time.Time, perr *PanicError) {
+	if job == nil {
+		e.counters.panics.Add(1)
+		if e.onPanic != nil {
+			e.onPanic(nil, perr)
+		}
+		return
+	}
+	switch job.Status() {
+	case JobCompleted, JobFailed:
+		e.counters.panics.Add(1)
+		if e.onPanic != nil {
+			e.onPanic(job, perr)
+		}
+		return
+	case JobPending:
+		// Panicked before it was counted as running
+		e.counters.queued.Add(-1)
+		e.counters.running.Add(1)
+	}
+	job.Result = e.recordPanic(job, job.Result, perr)
+	job.Error = perr
+	job.Task.CompletedAt = Now(e.withClock(context.Background()))
+	job.status.Store(JobFailed)
+	job.finish()
+	e.counters.recordFinish(time.Since(start), true)
+}
 
-	// Create context with timeout
-	ctx := context.Background()
+// finish releases the job's waiters. It is safe to call more than once.
+func (j *Job) finish() {
+	j.doneOnce.Do(func() { close(j.done) })
+}
+
+func (e *Executor) executeJob(job *Job) {
+	// Create context with timeout; the cancel func lets stall detection stop the job
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if job.resume != nil {
+		ctx = context.WithValue(ctx, resumeKey{}, job.resume)
+	}
 	if job.Task.Config != nil && job.Task.Config.TimeoutSeconds > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, time.Duration(job.Task.Config.TimeoutSeconds)*time.Second)
-		defer cancel()
+		var timeoutCancel context.CancelFunc
+		ctx, timeoutCancel = context.WithTimeout(ctx, time.Duration(job.Task.Config.TimeoutSeconds)*time.Second)
+		defer timeoutCancel()
+	}
+	ctx = withLabels(withTrace(e.withClock(ctx), job.Task.Config), job.Task.Config)
+	ctx = withProgress(ctx, job.Task, func(p *Progress) { job.progress.Store(p) })
+	ctx = withUsage(ctx, job.Task, e.pricing, func(u *UsageReport) { job.usage.Store(u) })
+	ctx = e.withInput(ctx, job)
+	job.Task.Config = withHeartbeat(withEvents(job.Task.Config, e.onEvent), job)
+	job.beat()
+
+	job.cancel = cancel
+	job.status.Store(JobRunning)
+	e.counters.queued.Add(-1)
+	e.counters.running.Add(1)
+	start := time.Now()
+	emit(job.Task, jobEvent(ctx, job, e.agent.Name()))
+
+	// Execute agent, unless the job was cancelled while pending
+	var result *Result
+	var err error
+	ws, wsErr := e.workspace.open(job.Task)
+	switch {
+	case job.cancelRequested.Load():
+		err = ErrJobCancelled
+		result = &Result{TaskID: job.Task.ID, Error: err.Error(), Metadata: map[string]interface{}{}}
+	case wsErr != nil:
+		err = wsErr
+		result = &Result{TaskID: job.Task.ID, Error: err.Error(), Metadata: map[string]interface{}{}}
+	default:
+		if ws != nil {
+			ctx = ContextWithWorkspace(ctx, ws)
+		}
+		result, err = e.runAgent(ctx, job)
+	}
+	if ws != nil {
+		e.workspace.finish(ws, result)
+	}
+	recordTrace(ctx, result)
+	recordLabels(ctx, result)
+	clearTempKeys(job.Task.State)
+	if result != nil {
+		clearTempKeys(result.State)
+	}
+	var perr *PanicError
+	switch {
+	case errors.Is(err, ErrJobCancelled):
+	case errors.As(err, &perr):
+		result = e.recordPanic(job, result, perr)
+	case err != nil && job.cancelRequested.Load():
+		err = fmt.Errorf("%w: %v", ErrJobCancelled, err)
+	case err != nil && job.stallCancelled.Load():
+		err = fmt.Errorf("%w: %v", ErrJobStalled, err)
 	}
 
-	// Execute agent
-	result, err := e.agent.Execute(ctx, job.Task)
-
-	job.Task.CompletedAt = time.Now()
+	job.Task.CompletedAt = Now(ctx)
 	job.Result = result
 	job.Error = err
 
 	// Update final status
-	e.mu.Lock()
 	if err != nil {
-		job.Status = JobFailed
+		job.status.Store(JobFailed)
 	} else {
-		job.Status = JobCompleted
+		job.status.Store(JobCompleted)
+	}
+	job.finish()
+	e.counters.recordFinish(time.Since(start), err != nil)
+	emit(job.Task, jobEvent(ctx, job, e.agent.Name()))
+
+	e.deliverResults(job)
+	for _, fn := range e.onJobDone {
+		fn(job)
 	}
-	e.mu.Unlock()
 }
 
 // ExecuteSync executes a task synchronously and returns the result directly.
-func (e *Executor) ExecuteSync(ctx context.Context, input string, params map[string]interface{}) (*Result, error) {
+func (e *Executor) ExecuteSync(ctx context.Context, input string, params map[string]interface{}, opts ...CallOption) (*Result, error) {
+	if err := e.maintenance.check(e.agent.Name()); err != nil {
+		return nil, err
+	}
+	if err := ValidateParams(e.agent, params); err != nil {
+		return nil, err
+	}
+
+	ctx = e.withClock(ctx)
 	task := &Task{
-		ID:        uuid.New().String(),
+		ID:        NewID(ctx),
 		Input:     input,
 		Params:    params,
 		State:     make(map[string]interface{}),
-		StartedAt: time.Now(),
+		Config:    applyCallOptions(nil, opts),
+		StartedAt: Now(ctx),
 	}
 
 	for k, v := range params {
 		task.State[k] = v
 	}
 
-	return e.agent.Execute(ctx, task)
+	ctx, release, err := e.concurrency.Acquire(withLabels(withTrace(ctx, task.Config), task.Config), e.agent.Name())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ws, err := e.workspace.open(task)
+	if err != nil {
+		return nil, err
+	}
+	if ws != nil {
+		ctx = ContextWithWorkspace(ctx, ws)
+	}
+	result, err := e.agent.Execute(withUsage(withProgress(ctx, task, nil), task, e.pricing, nil), task)
+	task.CompletedAt = Now(ctx)
+	if ws != nil {
+		e.workspace.finish(ws, result)
+	}
+	recordTrace(ctx, result)
+	recordLabels(ctx, result)
+	clearTempKeys(task.State)
+	if result != nil {
+		clearTempKeys(result.State)
+	}
+	return result, err
 }
@@ -0,0 +1,86 @@
+package agent
+
+import "time"
+
+// ExecutorOption configures an Executor.
+type ExecutorOption func(*Executor)
+
+// WithStallDetection marks a running job JobStalled when it has not emitted a
+// heartbeat (any event: step, partial content, tool call) within interval.
+// onStall, if non-nil, is called once per stall. With cancel set, stalled jobs
+// are cancelled and fail with ErrJobStalled.
+func WithStallDetection(interval time.Duration, cancel bool, onStall func(job *Job)) ExecutorOption {
+	return func(e *Executor) {
+		e.stall = &stallConfig{interval: interval, cancel: cancel, onStall: onStall}
+	}
+}
+
+// WithOnJobDone registers a hook called after each async job reaches
+// JobCompleted or JobFailed, e.g. to notify a channel.
+func WithOnJobDone(fn func(job *Job)) ExecutorOption {
+	return func(e *Executor) {
+		e.onJobDone = append(e.onJobDone, fn)
+	}
+}
+
+// WithToolMetrics sets the collector reported in Stats().Tools, for agents
+// configured with their own LLMAgentConfig.ToolMetrics.
+func WithToolMetrics(m *ToolMetrics) ExecutorOption {
+	return func(e *Executor) {
+		e.toolMetrics = m
+	}
+}
+
+// WithConcurrencyLimits sets the registry whose per-agent limits jobs wait
+// on and Stats().Concurrency reports, instead of DefaultConcurrencyLimits.
+func WithConcurrencyLimits(l *ConcurrencyLimits) ExecutorOption {
+	return func(e *Executor) {
+		e.concurrency = l
+	}
+}
+
+// WithPostProcessors runs the final output of every job, async or
+// ExecuteSync, through procs; see WithPostProcessing.
+func WithPostProcessors(procs ...PostProcessor) ExecutorOption {
+	return func(e *Executor) {
+		e.agent = WithPostProcessing(e.agent, procs...)
+	}
+}
+
+// WithResultSink adds a sink that receives every finished async job, before
+// the WithOnJobDone hooks run.
+func WithResultSink(sink ResultSink) ExecutorOption {
+	return func(e *Executor) {
+		e.sinks.sinks = append(e.sinks.sinks, sink)
+	}
+}
+
+// WithSinkErrorHandler sets the callback for failed sink deliveries and the
+// per-delivery timeout (0 keeps the 30s default).
+func WithSinkErrorHandler(timeout time.Duration, onError func(job *Job, err error)) ExecutorOption {
+	return func(e *Executor) {
+		if timeout > 0 {
+			e.sinks.timeout = timeout
+		}
+		e.sinks.onError = onError
+	}
+}
+
+// WithEvents sends every event of every async job to handler, in addition
+// to the task's own OnEvent, including EventJob status changes when a job
+// starts and finishes. handler must be safe for concurrent use.
+func WithEvents(handler EventHandler) ExecutorOption {
+	return func(e *Executor) {
+		e.onEvent = append(e.onEvent, handler)
+	}
+}
+
+// WithPanicHandler sets a callback for panics recovered in workers. Agent and
+// tool panics fail their job with a *PanicError (stack trace in
+// Metadata["panic_stack"]). Panics in hooks and sinks are reported with the
+// job being delivered.
+func WithPanicHandler(fn func(job *Job, err *PanicError)) ExecutorOption {
+	return func(e *Executor) {
+		e.onPanic = fn
+	}
+}
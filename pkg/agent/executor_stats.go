@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ExecutorStats is a point-in-time view of Executor activity. Durations and
+// throughput are computed over the rolling window.
+type ExecutorStats struct {
+	Queued     int64
+	Running    int64
+	Completed  int64         // Total since the Executor started
+	Failed     int64         // Total since the Executor started
+	Stalled    int64         // Stall detections since the Executor started
+	Panics     int64         // Recovered worker panics since the Executor started
+	Throughput float64       // Jobs finished per second over the retained samples within Window
+	P50        time.Duration // Median job duration within Window
+	P95        time.Duration
+	Window     time.Duration
+	Tools      map[string]ToolStats // Per-tool stats across runs
+
+	Concurrency map[string]ConcurrencyStats // Per-agent concurrency limits and usage
+}
+
+// jobSample is a finished job's completion time and duration.
+type jobSample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// executorCounters track live job counts and a rolling window of durations.
+type executorCounters struct {
+	queued    atomic.Int64
+	running   atomic.Int64
+	completed atomic.Int64
+	failed    atomic.Int64
+	stalled   atomic.Int64
+	panics    atomic.Int64
+
+	mu      sync.Mutex
+	window  time.Duration
+	maxSize int
+	samples []jobSample
+}
+
+func newExecutorCounters() *executorCounters {
+	return &executorCounters{window: 5 * time.Minute, maxSize: 10000}
+}
+
+func (c *executorCounters) recordFinish(duration time.Duration, failed bool) {
+	c.running.Add(-1)
+	if failed {
+		c.failed.Add(1)
+	} else {
+		c.completed.Add(1)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.samples = append(c.samples, jobSample{at: time.Now(), duration: duration})
+	if len(c.samples) > c.maxSize {
+		c.samples = c.samples[len(c.samples)-c.maxSize:]
+	}
+}
+
+func (c *executorCounters) snapshot() ExecutorStats {
+	stats := ExecutorStats{
+		Queued:    c.queued.Load(),
+		Running:   c.running.Load(),
+		Completed: c.completed.Load(),
+		Failed:    c.failed.Load(),
+		Stalled:   c.stalled.Load(),
+		Panics:    c.panics.Load(),
+		Window:    c.window,
+	}
+
+	c.mu.Lock()
+	now := time.Now()
+	cutoff := now.Add(-c.window)
+	i := sort.Search(len(c.samples), func(i int) bool { return !c.samples[i].at.Before(cutoff) })
+	c.samples = c.samples[i:]
+	durations := make([]time.Duration, len(c.samples))
+	for i, s := range c.samples {
+		durations[i] = s.duration
+	}
+	// The rate is over the time the samples actually cover: the buffer may
+	// hold less than the window when it is full, or shortly after start-up
+	var span time.Duration
+	if len(c.samples) > 0 {
+		span = now.Sub(c.samples[0].at)
+	}
+	c.mu.Unlock()
+
+	if len(durations) == 0 {
+		return stats
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	stats.P50 = percentile(durations, 0.50)
+	stats.P95 = percentile(durations, 0.95)
+	stats.Throughput = float64(len(durations)) / max(span, time.Second).Seconds()
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(p*float64(len(sorted))+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// Stats returns live job counters and rolling-window latency percentiles,
+// suitable for embedding in admin UIs.
+func (e *Executor) Stats() ExecutorStats {
+	stats := e.counters.snapshot()
+	stats.Tools = e.toolMetrics.Snapshot()
+	stats.Concurrency = e.concurrency.Stats()
+	return stats
+}
@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// benchAgent returns at once with one step, so benchmarks measure the
+// Executor rather than any model.
+type benchAgent struct{}
+
+func (benchAgent) Name() string       { return "bench" }
+func (benchAgent) SubAgents() []Agent { return nil }
+
+func (benchAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
+	return &Result{
+		TaskID:  task.ID,
+		Success: true,
+		Output:  task.Input,
+		Steps:   []ExecutionStep{{AgentName: "bench", Action: "reasoning", Timestamp: time.Now()}},
+	}, nil
+}
+
+// BenchmarkExecutor submits b.N jobs to 64 workers and waits for all of them.
+func BenchmarkExecutor(b *testing.B) {
+	exec := NewExecutor(benchAgent{}, 64)
+	ids := make([]string, b.N)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range ids {
+		id, err := exec.Submit("job "+strconv.Itoa(i), nil, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		ids[i] = id
+	}
+	for _, id := range ids {
+		if _, err := exec.GetResult(id); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "jobs/s")
+}
+
+// BenchmarkExecutorPolled is BenchmarkExecutor with status pollers running,
+// as behind GET /tasks/{id}.
+func BenchmarkExecutorPolled(b *testing.B) {
+	exec := NewExecutor(benchAgent{}, 64)
+	ids := make([]string, b.N)
+	var submitted atomic.Int64
+	stop := make(chan struct{})
+	for p := 0; p < 16; p++ {
+		go func(p int) {
+			for i := p; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if n := submitted.Load(); n > 0 {
+					exec.GetStatus(ids[i%int(n)])
+				}
+			}
+		}(p)
+	}
+	defer close(stop)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range ids {
+		id, err := exec.Submit("job "+strconv.Itoa(i), nil, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		ids[i] = id
+		submitted.Store(int64(i + 1))
+	}
+	for _, id := range ids {
+		if _, err := exec.GetResult(id); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "jobs/s")
+}
+
+// BenchmarkJobStore reads and writes the sharded job map from all
+// goroutines at once, 90% reads.
+func BenchmarkJobStore(b *testing.B) {
+	store := newJobStore()
+	ids := make([]string, 10000)
+	for i := range ids {
+		ids[i] = "task-" + strconv.Itoa(i)
+		store.put(ids[i], &Job{})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		job := &Job{}
+		for i := 0; pb.Next(); i++ {
+			id := ids[(i*7919)%len(ids)]
+			if i%10 == 0 {
+				store.put(id, job)
+			} else {
+				store.get(id)
+			}
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package agent
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// ParamSessionID is the Task.Params key naming the conversation a task
+// belongs to. Tasks run through AsSessionAgent carry the invocation's
+// session ID.
+const ParamSessionID = "session_id"
+
+// ParamCohorts is the Task.Params key that pins a task's experiment cohorts,
+// e.g. Params["cohorts"] = map[string]string{"web_search": CohortControl},
+// overriding the hashed assignment (used by eval.RunExperiment).
+const ParamCohorts = "cohorts"
+
+// Cohorts of a ToolExperiment.
+const (
+	CohortControl   = "control"   // The experiment's tools are removed
+	CohortTreatment = "treatment" // The experiment's tools are available
+)
+
+// ToolExperiment is an A/B test of tool availability: units (sessions, by
+// default) in the treatment cohort get Tools, the control cohort runs
+// without them. Assignment hashes the experiment name and unit, so every
+// turn of a conversation lands in the same cohort.
+type ToolExperiment struct {
+	Name      string
+	Tools     []string                // Tools under test, removed for the control cohort
+	Treatment float64                 // Share of units in the treatment cohort, 0 to 1 (default 0.5)
+	Unit      func(task *Task) string // Assignment key (default SessionUnit)
+}
+
+// SessionUnit is the default experiment unit: Params["session_id"], else
+// the caller's user ID, else the task ID.
+func SessionUnit(task *Task) string {
+	if id, ok := task.Params[ParamSessionID].(string); ok && id != "" {
+		return id
+	}
+	if task.Config != nil && task.Config.UserID != "" {
+		return task.Config.UserID
+	}
+	return task.ID
+}
+
+// Cohort returns the cohort unit is assigned to.
+func (x *ToolExperiment) Cohort(unit string) string {
+	share := x.Treatment
+	if share == 0 {
+		share = 0.5
+	}
+	h := fnv.New64a()
+	h.Write([]byte(x.Name + "/" + unit))
+	if float64(h.Sum64()%10000) < share*10000 {
+		return CohortTreatment
+	}
+	return CohortControl
+}
+
+func (x *ToolExperiment) cohortFor(task *Task) string {
+	switch pinned := task.Params[ParamCohorts].(type) {
+	case map[string]string:
+		if c := pinned[x.Name]; c == CohortControl || c == CohortTreatment {
+			return c
+		}
+	case map[string]interface{}:
+		if c, _ := pinned[x.Name].(string); c == CohortControl || c == CohortTreatment {
+			return c
+		}
+	}
+	unit := SessionUnit
+	if x.Unit != nil {
+		unit = x.Unit
+	}
+	return x.Cohort(unit(task))
+}
+
+// CohortStats summarizes the runs of one experiment cohort.
+type CohortStats struct {
+	Runs        int
+	Successes   int
+	SuccessRate float64
+	ToolCalls   int // Calls of the experiment's tools
+	Usage       TokenUsage
+	AvgDuration time.Duration
+}
+
+// ExperimentMetrics collects outcomes per experiment and cohort. It is safe
+// for concurrent use.
+type ExperimentMetrics struct {
+	mu    sync.Mutex
+	stats map[string]map[string]*cohortRecord
+}
+
+type cohortRecord struct {
+	CohortStats
+	total time.Duration
+}
+
+// NewExperimentMetrics creates an empty collector.
+func NewExperimentMetrics() *ExperimentMetrics {
+	return &ExperimentMetrics{stats: make(map[string]map[string]*cohortRecord)}
+}
+
+// DefaultExperimentMetrics is the process-wide collector used by
+// WithToolExperiments when none is given.
+var DefaultExperimentMetrics = NewExperimentMetrics()
+
+// Record adds one run of experiment in cohort.
+func (m *ExperimentMetrics) Record(x *ToolExperiment, cohort string, result *Result, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cohorts, ok := m.stats[x.Name]
+	if !ok {
+		cohorts = make(map[string]*cohortRecord)
+		m.stats[x.Name] = cohorts
+	}
+	rec, ok := cohorts[cohort]
+	if !ok {
+		rec = &cohortRecord{}
+		cohorts[cohort] = rec
+	}
+	rec.Runs++
+	rec.total += d
+	if result == nil {
+		return
+	}
+	if result.Success {
+		rec.Successes++
+	}
+	rec.Usage = addUsage(rec.Usage, result.TotalTokenUsage)
+	for _, step := range result.Steps {
+		for _, tc := range step.ToolCalls {
+			if containsAny(x.Tools, tc.Name) {
+				rec.ToolCalls++
+			}
+		}
+	}
+}
+
+// Snapshot returns the stats of every experiment, by name and cohort.
+func (m *ExperimentMetrics) Snapshot() map[string]map[string]CohortStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]map[string]CohortStats, len(m.stats))
+	for name, cohorts := range m.stats {
+		out[name] = make(map[string]CohortStats, len(cohorts))
+		for cohort, rec := range cohorts {
+			s := rec.CohortStats
+			s.SuccessRate = float64(s.Successes) / float64(s.Runs)
+			s.AvgDuration = rec.total / time.Duration(s.Runs)
+			out[name][cohort] = s
+		}
+	}
+	return out
+}
+
+type experimentKey struct{}
+
+// experimentAssignment is a task's cohorts and the tools they remove.
+type experimentAssignment struct {
+	cohorts  map[string]string
+	disabled map[string]bool
+}
+
+// ExperimentCohorts returns the running task's cohort per experiment, or
+// nil outside WithToolExperiments.
+func ExperimentCohorts(ctx context.Context) map[string]string {
+	if a, ok := ctx.Value(experimentKey{}).(*experimentAssignment); ok {
+		return a.cohorts
+	}
+	return nil
+}
+
+// experimentAgent decorates an agent with tool experiments.
+type experimentAgent struct {
+	Agent
+	experiments []*ToolExperiment
+	metrics     *ExperimentMetrics
+}
+
+// WithToolExperiments wraps ag so each task is assigned a cohort of every
+// experiment, and LLMAgents in the tree drop the tools of the experiments
+// whose control cohort it is in. Result.Metadata["experiments"] holds the
+// cohorts, and each run's outcome is recorded in metrics (default
+// DefaultExperimentMetrics).
+func WithToolExperiments(ag Agent, metrics *ExperimentMetrics, experiments ...*ToolExperiment) Agent {
+	if metrics == nil {
+		metrics = DefaultExperimentMetrics
+	}
+	return &experimentAgent{Agent: ag, experiments: experiments, metrics: metrics}
+}
+
+func (a *experimentAgent) Unwrap() Agent {
+	return a.Agent
+}
+
+func (a *experimentAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
+	assignment := &experimentAssignment{cohorts: make(map[string]string), disabled: make(map[string]bool)}
+	for _, x := range a.experiments {
+		cohort := x.cohortFor(task)
+		assignment.cohorts[x.Name] = cohort
+		if cohort == CohortControl {
+			for _, name := range x.Tools {
+				assignment.disabled[name] = true
+			}
+		}
+	}
+
+	start := time.Now()
+	result, err := a.Agent.Execute(context.WithValue(ctx, experimentKey{}, assignment), task)
+	d := time.Since(start)
+	if result != nil {
+		if result.Metadata == nil {
+			result.Metadata = make(map[string]interface{})
+		}
+		result.Metadata["experiments"] = assignment.cohorts
+	}
+	for _, x := range a.experiments {
+		a.metrics.Record(x, assignment.cohorts[x.Name], result, d)
+	}
+	return result, err
+}
+
+// experimentTools removes the tools disabled by the task's control cohorts.
+func experimentTools(ctx context.Context, tools []Tool) []Tool {
+	a, ok := ctx.Value(experimentKey{}).(*experimentAssignment)
+	if !ok || len(a.disabled) == 0 {
+		return tools
+	}
+	filtered := make([]Tool, 0, len(tools))
+	for _, t := range tools {
+		if !a.disabled[t.Name()] {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
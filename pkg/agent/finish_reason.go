@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FinishReason says why the model stopped generating.
+type FinishReason string
+
+const (
+	FinishStop          FinishReason = "stop"           // Natural end of the answer or a stop sequence
+	FinishLength        FinishReason = "length"         // Cut off by the max token limit
+	FinishToolCalls     FinishReason = "tool_calls"     // Stopped to call tools
+	FinishContentFilter FinishReason = "content_filter" // Blocked or cut short by the provider's safety filter
+	FinishError         FinishReason = "error"          // The backend failed mid-generation
+)
+
+// ParseFinishReason maps a provider's finish or stop reason to a
+// FinishReason, covering OpenAI ("stop", "length", "tool_calls",
+// "content_filter"), Anthropic ("end_turn", "max_tokens", "tool_use",
+// "refusal"), and Gemini ("STOP", "MAX_TOKENS", "SAFETY") values. Unknown
+// values are returned lowercased as is; "" stays "".
+func ParseFinishReason(s string) FinishReason {
+	switch v := strings.ToLower(s); v {
+	case "stop", "end_turn", "stop_sequence", "eos", "complete":
+		return FinishStop
+	case "length", "max_tokens", "model_length", "max_output_tokens":
+		return FinishLength
+	case "tool_calls", "function_call", "tool_use", "tool_call":
+		return FinishToolCalls
+	case "content_filter", "safety", "recitation", "refusal", "blocklist", "prohibited_content", "spii":
+		return FinishContentFilter
+	case "error", "malformed_function_call":
+		return FinishError
+	default:
+		return FinishReason(v)
+	}
+}
+
+// Reason returns the response's finish reason. For providers that do not
+// set FinishReason it is derived from ToolCalls and Finished, and is ""
+// when unknown.
+func (r *ModelResponse) Reason() FinishReason {
+	switch {
+	case r.FinishReason != "":
+		return r.FinishReason
+	case len(r.ToolCalls) > 0:
+		return FinishToolCalls
+	case r.Finished:
+		return FinishStop
+	}
+	return ""
+}
+
+// Truncated reports whether the response was cut off by the token limit.
+func (r *ModelResponse) Truncated() bool {
+	return r.Reason() == FinishLength
+}
+
+// IncompleteResponseError is returned when the model's answer was blocked by
+// a content filter or ended by a backend error.
+type IncompleteResponseError struct {
+	Agent  string
+	Reason FinishReason
+}
+
+func (e *IncompleteResponseError) Error() string {
+	if e.Reason == FinishContentFilter {
+		return fmt.Sprintf("agent %s: response blocked by content filter", e.Agent)
+	}
+	return fmt.Sprintf("agent %s: response incomplete (finish reason %s)", e.Agent, e.Reason)
+}
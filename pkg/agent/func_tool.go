@@ -0,0 +1,31 @@
+package agent
+
+import "context"
+
+// FuncTool is a Tool backed by a function, typically a closure over request
+// scope passed with WithTools:
+//
+//	reply := agent.NewFuncTool("reply_to_customer", "Send a reply to the customer", schema,
+//		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+//			return nil, support.Reply(ctx, ticketID, args["text"].(string))
+//		})
+//	exec.Submit(input, params, nil, agent.WithTools(reply))
+type FuncTool struct {
+	name        string
+	description string
+	schema      interface{}
+	fn          func(ctx context.Context, args map[string]interface{}) (interface{}, error)
+}
+
+// NewFuncTool creates a tool that runs fn when called.
+func NewFuncTool(name, description string, schema interface{}, fn func(ctx context.Context, args map[string]interface{}) (interface{}, error)) *FuncTool {
+	return &FuncTool{name: name, description: description, schema: schema, fn: fn}
+}
+
+func (t *FuncTool) Name() string        { return t.name }
+func (t *FuncTool) Description() string { return t.description }
+func (t *FuncTool) Schema() interface{} { return t.schema }
+
+func (t *FuncTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	return t.fn(ctx, args)
+}
@@ -0,0 +1,214 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GuardrailAction selects what GuardrailAgent does with a violating output.
+type GuardrailAction int
+
+const (
+	// GuardrailBlock fails the execution with a *GuardrailError.
+	GuardrailBlock GuardrailAction = iota
+	// GuardrailRedact masks banned patterns and truncates overlong output.
+	// Violations that cannot be redacted (schema, validators) still block.
+	GuardrailRedact
+	// GuardrailRevise re-runs the inner agent with the violations as feedback,
+	// up to MaxRevisions times, then blocks.
+	GuardrailRevise
+)
+
+// GuardrailConfig holds configuration for creating a GuardrailAgent.
+type GuardrailConfig struct {
+	Name           string
+	Agent          Agent                     // Inner agent whose output is checked
+	BannedPatterns []*regexp.Regexp          // Output must not match any of these
+	MaxLength      int                       // Max output length in characters (0 = unlimited)
+	RequiredSchema map[string]interface{}    // Output must satisfy this JSON schema (optional)
+	Validators     []func(interface{}) error // Custom checks; a non-nil error is a violation
+	Action         GuardrailAction
+	MaxRevisions   int    // Revision attempts for GuardrailRevise (default 2)
+	RedactWith     string // Replacement text for GuardrailRedact (default "[REDACTED]")
+}
+
+// GuardrailError reports an output that violated the guardrail policy.
+type GuardrailError struct {
+	Agent      string
+	Violations []string
+}
+
+func (e *GuardrailError) Error() string {
+	return fmt.Sprintf("guardrail %s blocked output: %s", e.Agent, strings.Join(e.Violations, "; "))
+}
+
+// GuardrailAgent wraps an agent and enforces output policies on its final
+// output before it reaches the caller.
+type GuardrailAgent struct {
+	cfg GuardrailConfig
+}
+
+// NewGuardrailAgent creates a new GuardrailAgent from the given configuration.
+func NewGuardrailAgent(cfg GuardrailConfig) *GuardrailAgent {
+	if cfg.MaxRevisions == 0 {
+		cfg.MaxRevisions = 2
+	}
+	if cfg.RedactWith == "" {
+		cfg.RedactWith = "[REDACTED]"
+	}
+	if cfg.Name == "" {
+		cfg.Name = cfg.Agent.Name() + "-guardrail"
+	}
+	return &GuardrailAgent{cfg: cfg}
+}
+
+func (a *GuardrailAgent) Name() string {
+	return a.cfg.Name
+}
+
+func (a *GuardrailAgent) SubAgents() []Agent {
+	return []Agent{a.cfg.Agent}
+}
+
+func (a *GuardrailAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
+	ctx = EnterAgent(ctx, a.cfg.Name)
+	result := &Result{
+		TaskID:   task.ID,
+		Success:  false,
+		Metadata: make(map[string]interface{}),
+		Steps:    []ExecutionStep{},
+	}
+
+	originalInput := task.Input
+	defer func() { task.Input = originalInput }()
+
+	for attempt := 0; ; attempt++ {
+		subResult, err := a.cfg.Agent.Execute(ctx, task)
+		if subResult != nil {
+			result.appendSteps(ctx, subResult.Steps)
+		}
+		if err != nil {
+			result.Error = fmt.Sprintf("agent %s failed: %v", a.cfg.Agent.Name(), err)
+			if subResult != nil {
+				result.Artifacts = subResult.Artifacts
+			}
+			result.markPartial(task.State)
+			return result, err
+		}
+		if propagateEscalation(result, subResult) {
+			result.aggregateMetrics()
+			return result, nil
+		}
+
+		stepStart := Now(ctx)
+		violations := a.check(subResult.Output)
+		step := ExecutionStep{
+			AgentName: a.cfg.Name,
+			Action:    "guardrail",
+			Input:     subResult.Output,
+			Timestamp: stepStart,
+		}
+
+		if len(violations) == 0 {
+			step.Output = "passed"
+			step.Duration = Since(ctx, stepStart)
+			recordStep(ctx, task, result, step)
+			return a.pass(result, subResult, attempt), nil
+		}
+
+		step.Output = violations
+		step.Duration = Since(ctx, stepStart)
+		recordStep(ctx, task, result, step)
+
+		switch {
+		case a.cfg.Action == GuardrailRedact && a.redactable(subResult.Output):
+			subResult.Output = a.redact(subResult.Output.(string))
+			subResult.Outputs = reparseOutputs(subResult.Outputs, subResult.Output)
+			result.Metadata["guardrail_redacted"] = violations
+			return a.pass(result, subResult, attempt), nil
+
+		case a.cfg.Action == GuardrailRevise && attempt < a.cfg.MaxRevisions:
+			task.Input = fmt.Sprintf("%s\n\nYour previous answer was rejected for the following reasons:\n- %s\nRevise your answer to address them.",
+				originalInput, strings.Join(violations, "\n- "))
+			continue
+		}
+
+		gerr := &GuardrailError{Agent: a.cfg.Name, Violations: violations}
+		result.Error = gerr.Error()
+		result.aggregateMetrics()
+		return result, gerr
+	}
+}
+
+// pass copies the accepted sub-result into the guardrail's result.
+func (a *GuardrailAgent) pass(result, subResult *Result, revisions int) *Result {
+	result.Output = subResult.Output
+	result.Outputs = subResult.Outputs
+	result.Artifacts = subResult.Artifacts
+	result.Success = subResult.Success
+	result.Metadata["guardrail_revisions"] = revisions
+	result.aggregateMetrics()
+	return result
+}
+
+// check returns a description of every policy the output violates.
+func (a *GuardrailAgent) check(output interface{}) []string {
+	var violations []string
+	text := fmt.Sprint(output)
+	if s, ok := output.(string); ok {
+		text = s
+	}
+
+	for _, re := range a.cfg.BannedPatterns {
+		if re.MatchString(text) {
+			violations = append(violations, fmt.Sprintf("output matches banned pattern %q", re.String()))
+		}
+	}
+	if a.cfg.MaxLength > 0 && len([]rune(text)) > a.cfg.MaxLength {
+		violations = append(violations, fmt.Sprintf("output exceeds %d characters", a.cfg.MaxLength))
+	}
+	if a.cfg.RequiredSchema != nil {
+		if err := ValidateSchema(a.cfg.RequiredSchema, output); err != nil {
+			violations = append(violations, fmt.Sprintf("output does not match schema: %v", err))
+		}
+	}
+	for _, validate := range a.cfg.Validators {
+		if err := validate(output); err != nil {
+			violations = append(violations, err.Error())
+		}
+	}
+	return violations
+}
+
+// redactable reports whether redaction alone can fix the output, i.e. it is a
+// string and only pattern or length rules fail.
+func (a *GuardrailAgent) redactable(output interface{}) bool {
+	s, ok := output.(string)
+	if !ok {
+		return false
+	}
+	redacted := a.redact(s)
+	if a.cfg.RequiredSchema != nil && ValidateSchema(a.cfg.RequiredSchema, redacted) != nil {
+		return false
+	}
+	for _, validate := range a.cfg.Validators {
+		if validate(redacted) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *GuardrailAgent) redact(s string) string {
+	for _, re := range a.cfg.BannedPatterns {
+		s = re.ReplaceAllString(s, a.cfg.RedactWith)
+	}
+	if a.cfg.MaxLength > 0 {
+		if r := []rune(s); len(r) > a.cfg.MaxLength {
+			s = string(r[:a.cfg.MaxLength])
+		}
+	}
+	return s
+}
@@ -0,0 +1,207 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// History is an immutable, append-only conversation. Append returns a new
+// History that shares every earlier message with the original, so turns and
+// sub-agents can hold on to a history without defensive copies of large
+// content. The zero value is an empty history.
+type History struct {
+	log *historyLog
+	n   int
+}
+
+// historyLog is the backing store shared by every History derived from the
+// same root. Only the History whose length equals len(msgs) may extend it in
+// place; appending to an older version forks a new log.
+type historyLog struct {
+	mu   sync.Mutex
+	msgs []Message
+}
+
+// NewHistory creates a history holding msgs.
+func NewHistory(msgs ...Message) History {
+	return newHistory(len(msgs), msgs...)
+}
+
+// newHistory creates a history with room for capacity messages before the
+// backing store has to grow.
+func newHistory(capacity int, msgs ...Message) History {
+	if capacity < len(msgs) {
+		capacity = len(msgs)
+	}
+	log := &historyLog{msgs: make([]Message, len(msgs), capacity)}
+	copy(log.msgs, msgs)
+	return History{log: log, n: len(msgs)}
+}
+
+// Append returns a history with msgs added after h's messages. h itself is
+// unchanged.
+func (h History) Append(msgs ...Message) History {
+	if len(msgs) == 0 {
+		return h
+	}
+	if h.log == nil {
+		return NewHistory(msgs...)
+	}
+
+	h.log.mu.Lock()
+	defer h.log.mu.Unlock()
+	if h.n == len(h.log.msgs) {
+		// h is the newest version: extend the shared store in place. Views
+		// handed out earlier are capped at their own length, so they never
+		// observe the new messages.
+		h.log.msgs = append(h.log.msgs, msgs...)
+		return History{log: h.log, n: len(h.log.msgs)}
+	}
+
+	// Another version already extended the store past h; fork
+	forked := make([]Message, h.n, h.n+len(msgs))
+	copy(forked, h.log.msgs[:h.n])
+	forked = append(forked, msgs...)
+	return History{log: &historyLog{msgs: forked}, n: len(forked)}
+}
+
+// Messages returns the messages as a slice that shares memory with the
+// history. Its capacity is capped at its length, so appending to it copies
+// rather than overwriting later messages; callers must not modify elements.
+func (h History) Messages() []Message {
+	if h.log == nil {
+		return nil
+	}
+	h.log.mu.Lock()
+	msgs := h.log.msgs[:h.n:h.n]
+	h.log.mu.Unlock()
+	return msgs
+}
+
+// Len returns the number of messages.
+func (h History) Len() int {
+	return h.n
+}
+
+// Last returns the most recent message, or false if the history is empty.
+func (h History) Last() (Message, bool) {
+	msgs := h.Messages()
+	if len(msgs) == 0 {
+		return Message{}, false
+	}
+	return msgs[len(msgs)-1], true
+}
+
+// ErrInvalidHistory is matched by the *HistoryError returned for histories
+// that break the message ordering rules.
+var ErrInvalidHistory = errors.New("invalid history")
+
+// HistoryError reports the first message of a history that breaks the
+// ordering rules checked by ValidateHistory.
+type HistoryError struct {
+	Index  int // Position of the offending message
+	Role   Role
+	Reason string
+}
+
+func (e *HistoryError) Error() string {
+	return fmt.Sprintf("invalid history at message %d (%s): %s", e.Index, e.Role, e.Reason)
+}
+
+func (e *HistoryError) Is(target error) bool {
+	return target == ErrInvalidHistory
+}
+
+// ValidateHistory checks the rules providers enforce on a conversation, so
+// malformed histories fail with a precise *HistoryError instead of a
+// provider's 400:
+//
+//   - every role is one of RoleSystem, RoleUser, RoleAssistant, RoleTool
+//   - there is at most one system message, and it comes first
+//   - the conversation starts with a user message
+//   - user and assistant messages alternate
+//   - tool messages follow an assistant message with tool calls (or another
+//     tool message), and such an assistant message is followed by its results
+//   - a tool message's ToolCallID, when set, is the ID of one of those calls
+//
+// LLMAgent validates every request before calling the model.
+func ValidateHistory(msgs []Message) error {
+	prev := Role("")
+	var calls []ToolCall // Of the last assistant message
+	for i, m := range msgs {
+		fail := func(reason string, args ...interface{}) error {
+			return &HistoryError{Index: i, Role: m.Role, Reason: fmt.Sprintf(reason, args...)}
+		}
+		switch {
+		case !m.Role.Valid():
+			return fail("unknown role %q", m.Role)
+		case m.Role == RoleSystem && i > 0:
+			return fail("system message must be the first message")
+		case prev == "" || prev == RoleSystem:
+			if m.Role != RoleUser && m.Role != RoleSystem {
+				return fail("conversation must start with a user message")
+			}
+		case m.Role == RoleTool:
+			if prev != RoleTool && (prev != RoleAssistant || len(msgs[i-1].ToolCalls) == 0) {
+				return fail("tool message must follow an assistant message with tool calls")
+			}
+			if m.ToolCallID != "" && !hasToolCall(calls, m.ToolCallID) {
+				return fail("result for unknown tool call %q", m.ToolCallID)
+			}
+		case prev == RoleAssistant && len(msgs[i-1].ToolCalls) > 0:
+			if m.Role != RoleUser {
+				return fail("tool calls of message %d have no results", i-1)
+			}
+		case m.Role == prev:
+			return fail("two %s messages in a row", m.Role)
+		}
+		if m.Role == RoleAssistant {
+			calls = m.ToolCalls
+		}
+		prev = m.Role
+	}
+	return nil
+}
+
+func hasToolCall(calls []ToolCall, id string) bool {
+	for _, tc := range calls {
+		if tc.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ToolResultsAsUser rewrites the RoleTool messages of a history as user
+// messages, for providers without a tool role. Each run of results becomes
+// one user message with a "[id] name result: ..." line per call, carrying
+// the results' parts.
+func ToolResultsAsUser(msgs []Message) []Message {
+	out := make([]Message, 0, len(msgs))
+	var calls []ToolCall
+	for i, m := range msgs {
+		if m.Role == RoleAssistant {
+			calls = m.ToolCalls
+		}
+		if m.Role != RoleTool {
+			out = append(out, m)
+			continue
+		}
+		name := ""
+		for _, tc := range calls {
+			if tc.ID == m.ToolCallID {
+				name = tc.Name
+			}
+		}
+		line := fmt.Sprintf("[%s] %s result: %s", m.ToolCallID, name, m.Content)
+		if i > 0 && msgs[i-1].Role == RoleTool {
+			last := &out[len(out)-1]
+			last.Content += "\n" + line
+			last.Parts = append(append([]Part(nil), last.Parts...), m.Parts...)
+			continue
+		}
+		out = append(out, Message{Role: RoleUser, Content: line, Parts: m.Parts})
+	}
+	return out
+}
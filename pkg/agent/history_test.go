@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+const benchTurns = 60
+
+// benchTurn is one tool turn: an assistant message with a call and its
+// result, with a few KB of content as tool output tends to have.
+func benchTurn() []Message {
+	return []Message{
+		{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "call", Name: "search"}}},
+		{Role: RoleTool, ToolCallID: "call", Content: strings.Repeat("result ", 512)},
+	}
+}
+
+// BenchmarkHistory runs 60-turn sessions in which every turn hands the
+// history to a request and to a sub-agent, which both hold on to it (as
+// request logs, events, and checkpoints do). "shared" uses History;
+// "copied" is the previous approach of a plain slice copied defensively at
+// each hand-off.
+func BenchmarkHistory(b *testing.B) {
+	start := []Message{{Role: RoleSystem, Content: "system"}, {Role: RoleUser, Content: "task"}}
+	turn := benchTurn()
+
+	b.Run("shared", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			held := make([][]Message, 0, 2*benchTurns)
+			h := newHistory(len(start)+2*benchTurns, start...)
+			for i := 0; i < benchTurns; i++ {
+				held = append(held, h.Messages(), h.Messages())
+				h = h.Append(turn...)
+			}
+		}
+	})
+
+	b.Run("copied", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			held := make([][]Message, 0, 2*benchTurns)
+			h := make([]Message, 0, len(start)+2*benchTurns)
+			h = append(h, start...)
+			for i := 0; i < benchTurns; i++ {
+				held = append(held, append([]Message(nil), h...), append([]Message(nil), h...))
+				h = append(h, turn...)
+			}
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InputRequest is a clarifying question an agent asked the user. While it
+// is pending the job has status JobNeedsInput.
+type InputRequest struct {
+	ID        string    `json:"id"`
+	Question  string    `json:"question"`
+	Choices   []string  `json:"choices,omitempty"` // Suggested answers, if any
+	AgentPath string    `json:"agent_path,omitempty"`
+	AskedAt   time.Time `json:"asked_at"`
+}
+
+// ErrInputUnavailable is returned by AskUser when nobody can answer, e.g.
+// under ExecuteSync or a direct Execute call.
+var ErrInputUnavailable = errors.New("user input is not available for this execution")
+
+// ErrNotWaitingForInput is returned by Executor.Answer for jobs without a
+// pending question, or when the answer is for a question no longer pending.
+var ErrNotWaitingForInput = &jobStateError{msg: "task is not waiting for input", status: http.StatusConflict}
+
+type inputKey struct{}
+
+// pendingInput is a question waiting for its answer.
+type pendingInput struct {
+	req    InputRequest
+	answer chan string
+}
+
+// jobInput lets the agents of a job ask the user questions, one at a time.
+type jobInput struct {
+	job *Job
+	e   *Executor
+	mu  sync.Mutex
+}
+
+// AskUser asks the user a clarifying question and waits for the answer,
+// e.g. from a tool when a task is underspecified. The job reports
+// JobNeedsInput and emits an EventNeedsInput until Executor.Answer is
+// called; questions from concurrent agents of a job are asked in turn. It
+// fails with ctx's error if the job is cancelled or times out first, and
+// with ErrInputUnavailable outside async jobs.
+func AskUser(ctx context.Context, question string, choices ...string) (string, error) {
+	in, ok := ctx.Value(inputKey{}).(*jobInput)
+	if !ok {
+		return "", ErrInputUnavailable
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	job := in.job
+	p := &pendingInput{
+		req: InputRequest{
+			ID:        NewID(ctx),
+			Question:  question,
+			Choices:   choices,
+			AgentPath: AgentPathFromContext(ctx),
+			AskedAt:   Now(ctx),
+		},
+		answer: make(chan string, 1),
+	}
+	job.input.Store(p)
+	job.status.Store(JobNeedsInput)
+
+	ev := newEvent(ctx, job.Task.ID, in.e.agent.Name(), EventNeedsInput)
+	ev.AgentPath = p.req.AgentPath
+	ev.Content = question
+	ev.Question = &p.req
+	emit(job.Task, ev)
+
+	select {
+	case answer := <-p.answer:
+		job.status.Store(JobRunning)
+		job.beat()
+		return answer, nil
+	case <-ctx.Done():
+		job.input.CompareAndSwap(p, nil)
+		job.status.Store(JobRunning)
+		return "", fmt.Errorf("waiting for user input: %w", ctx.Err())
+	}
+}
+
+// withInput lets the agents of job ask the user questions.
+func (e *Executor) withInput(ctx context.Context, job *Job) context.Context {
+	return context.WithValue(ctx, inputKey{}, &jobInput{job: job, e: e})
+}
+
+// PendingInput returns the question a job is waiting on, or nil if it is
+// not waiting for input.
+func (e *Executor) PendingInput(taskID string) (*InputRequest, error) {
+	job, ok := e.jobs.get(taskID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, taskID)
+	}
+	if p := job.input.Load(); p != nil {
+		req := p.req
+		return &req, nil
+	}
+	return nil, nil
+}
+
+// Answer supplies the answer to a job's pending question and resumes it.
+// requestID, if not empty, must match the pending InputRequest's ID so a
+// stale answer is not applied to a newer question.
+func (e *Executor) Answer(taskID, requestID, answer string) error {
+	job, ok := e.jobs.get(taskID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, taskID)
+	}
+	p := job.input.Load()
+	if p == nil || requestID != "" && p.req.ID != requestID || !job.input.CompareAndSwap(p, nil) {
+		return ErrNotWaitingForInput
+	}
+	p.answer <- answer
+	return nil
+}
+
+// askUserSchema is the parameter schema of the ask_user tool.
+var askUserSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"question": map[string]interface{}{"type": "string", "description": "The question to ask the user"},
+		"choices": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"description": "Suggested answers, if the question has a few likely ones",
+		},
+	},
+	"required": []string{"question"},
+}
+
+// NewAskUserTool returns an "ask_user" tool that lets a model ask the user a
+// clarifying question through AskUser; the answer is the tool result.
+func NewAskUserTool() *FuncTool {
+	return NewFuncTool("ask_user",
+		"Ask the user a clarifying question when the task is ambiguous or missing information you cannot look up. Returns the user's answer.",
+		askUserSchema,
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			question, _ := args["question"].(string)
+			if strings.TrimSpace(question) == "" {
+				return nil, errors.New("question is required")
+			}
+			var choices []string
+			if list, ok := args["choices"].([]interface{}); ok {
+				for _, c := range list {
+					if s, ok := c.(string); ok {
+						choices = append(choices, s)
+					}
+				}
+			}
+			return AskUser(ctx, question, choices...)
+		})
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// jobShardCount is the number of independently locked job map shards.
+const jobShardCount = 64
+
+// jobStore is a sharded map of jobs by task ID, so lookups from many
+// concurrent status pollers don't contend on a single lock.
+type jobStore struct {
+	seed   maphash.Seed
+	shards [jobShardCount]jobShard
+}
+
+type jobShard struct {
+	mu   sync.RWMutex
+	jobs map[string]*Job
+}
+
+func newJobStore() *jobStore {
+	s := &jobStore{seed: maphash.MakeSeed()}
+	for i := range s.shards {
+		s.shards[i].jobs = make(map[string]*Job)
+	}
+	return s
+}
+
+func (s *jobStore) shard(id string) *jobShard {
+	return &s.shards[maphash.String(s.seed, id)%jobShardCount]
+}
+
+func (s *jobStore) get(id string) (*Job, bool) {
+	sh := s.shard(id)
+	sh.mu.RLock()
+	job, ok := sh.jobs[id]
+	sh.mu.RUnlock()
+	return job, ok
+}
+
+func (s *jobStore) put(id string, job *Job) {
+	sh := s.shard(id)
+	sh.mu.Lock()
+	sh.jobs[id] = job
+	sh.mu.Unlock()
+}
+
+// each calls fn for every job, holding one shard's read lock at a time.
+func (s *jobStore) each(fn func(job *Job)) {
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		for _, job := range sh.jobs {
+			fn(job)
+		}
+		sh.mu.RUnlock()
+	}
+}
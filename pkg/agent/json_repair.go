@@ -0,0 +1,162 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrUnrepairableJSON is returned when output cannot be turned into valid JSON.
+var ErrUnrepairableJSON = errors.New("output is not repairable JSON")
+
+// JSONRepairer fixes near-valid JSON emitted by a model before it is parsed
+// as structured output.
+type JSONRepairer interface {
+	Repair(text string) (string, error)
+}
+
+// JSONRepairFunc adapts a function to the JSONRepairer interface.
+type JSONRepairFunc func(text string) (string, error)
+
+func (f JSONRepairFunc) Repair(text string) (string, error) {
+	return f(text)
+}
+
+// DefaultJSONRepairer strips markdown fences and surrounding prose, removes
+// trailing commas, quotes bare object keys, and closes truncated strings,
+// objects, and arrays.
+var DefaultJSONRepairer JSONRepairer = JSONRepairFunc(RepairJSON)
+
+// NoJSONRepair disables repair on an LLMAgent.
+var NoJSONRepair JSONRepairer = JSONRepairFunc(func(text string) (string, error) {
+	if json.Valid([]byte(text)) {
+		return text, nil
+	}
+	return "", ErrUnrepairableJSON
+})
+
+// RepairJSON applies the default repairs to text and returns the result if
+// it is valid JSON.
+func RepairJSON(text string) (string, error) {
+	text = stripCodeFence(strings.TrimSpace(text))
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return "", ErrUnrepairableJSON
+	}
+	text = text[start:]
+
+	var out strings.Builder
+	out.Grow(len(text) + 8)
+	var stack []byte
+	inString, escaped := false, false
+
+scan:
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			out.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out.WriteByte(c)
+		case c == '{' || c == '[':
+			stack = append(stack, c)
+			out.WriteByte(c)
+		case c == '}' || c == ']':
+			trimTrailingComma(&out)
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			out.WriteByte(c)
+			if len(stack) == 0 {
+				break scan // Drop anything after the top-level value
+			}
+		case isKeyStart(c) && len(stack) > 0 && stack[len(stack)-1] == '{' && expectsKey(out.String()):
+			j := i
+			for j < len(text) && isKeyChar(text[j]) {
+				j++
+			}
+			out.WriteByte('"')
+			out.WriteString(text[i:j])
+			out.WriteByte('"')
+			i = j - 1
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	// Close whatever a truncated response left open
+	if inString {
+		if escaped {
+			out.WriteByte('\\')
+		}
+		out.WriteByte('"')
+	}
+	trimTrailingComma(&out)
+	if s := strings.TrimRight(out.String(), " \t\r\n"); strings.HasSuffix(s, ":") {
+		out.WriteString("null")
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == '{' {
+			out.WriteByte('}')
+		} else {
+			out.WriteByte(']')
+		}
+	}
+
+	repaired := out.String()
+	if !json.Valid([]byte(repaired)) {
+		return "", ErrUnrepairableJSON
+	}
+	return repaired, nil
+}
+
+// stripCodeFence removes a surrounding ```json ... ``` block if present.
+func stripCodeFence(text string) string {
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if nl := strings.IndexByte(text, '\n'); nl >= 0 {
+		text = text[nl+1:]
+	}
+	return strings.TrimSuffix(strings.TrimSpace(text), "```")
+}
+
+// trimTrailingComma removes a comma (and trailing whitespace) at the end of
+// the output written so far.
+func trimTrailingComma(out *strings.Builder) {
+	s := strings.TrimRight(out.String(), " \t\r\n")
+	if !strings.HasSuffix(s, ",") {
+		return
+	}
+	s = strings.TrimSuffix(s, ",")
+	out.Reset()
+	out.WriteString(s)
+}
+
+// expectsKey reports whether the next token in an object is a key, i.e. the
+// last significant character written opens the object or separates members.
+func expectsKey(written string) bool {
+	s := strings.TrimRight(written, " \t\r\n")
+	return strings.HasSuffix(s, "{") || strings.HasSuffix(s, ",")
+}
+
+func isKeyStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isKeyChar(c byte) bool {
+	return isKeyStart(c) || c == '-' || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BaggageHeader is the W3C Baggage header InjectTrace fills with the task's
+// labels, so downstream services can attribute the calls it makes.
+const BaggageHeader = "baggage"
+
+// WithLabels adds labels to the call's task, e.g. {"team": "search",
+// "env": "prod"}. Labels from later layers win per key.
+func WithLabels(labels map[string]string) CallOption {
+	return func(c *ExecutionConfig) {
+		c.Labels = mergeLabels(c.Labels, labels)
+	}
+}
+
+// mergeLabels returns base with extra added, without modifying either.
+func mergeLabels(base, extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(extra))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
+// Labels returns the job's labels.
+func (j *Job) Labels() map[string]string {
+	if j.Task.Config == nil {
+		return nil
+	}
+	return j.Task.Config.Labels
+}
+
+type labelsKey struct{}
+
+// LabelsFromContext returns the labels of the running task, or nil.
+func LabelsFromContext(ctx context.Context) map[string]string {
+	labels, _ := ctx.Value(labelsKey{}).(map[string]string)
+	return labels
+}
+
+// withLabels carries the task's labels in ctx.
+func withLabels(ctx context.Context, cfg *ExecutionConfig) context.Context {
+	if cfg == nil || len(cfg.Labels) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, labelsKey{}, cfg.Labels)
+}
+
+// recordLabels stores ctx's labels in Metadata["labels"].
+func recordLabels(ctx context.Context, result *Result) {
+	if labels := LabelsFromContext(ctx); len(labels) > 0 && result != nil && result.Metadata != nil {
+		result.Metadata["labels"] = labels
+	}
+}
+
+// baggage encodes labels as a W3C Baggage header value.
+func baggage(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	members := make([]string, len(keys))
+	for i, k := range keys {
+		members[i] = baggageEscape(k) + "=" + baggageEscape(labels[k])
+	}
+	return strings.Join(members, ",")
+}
+
+// baggageEscape percent-encodes s for a baggage member.
+func baggageEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// JobFilter selects jobs for ListJobs. Zero fields match every job.
+type JobFilter struct {
+	Labels map[string]string // Every label must be set to this value
+	Status []JobStatus       // Any of these statuses
+	Since  time.Time         // Started at or after
+	UserID string            // Submitted by this caller (see WithUserID)
+	Limit  int               // Most recently started jobs returned (0 = all)
+}
+
+func (f JobFilter) match(job *Job) bool {
+	labels := job.Labels()
+	for k, v := range f.Labels {
+		if got, ok := labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	if len(f.Status) > 0 && !containsStatus(f.Status, job.Status()) {
+		return false
+	}
+	if f.UserID != "" && (job.Task.Config == nil || job.Task.Config.UserID != f.UserID) {
+		return false
+	}
+	return f.Since.IsZero() || !job.Task.StartedAt.Before(f.Since)
+}
+
+func containsStatus(list []JobStatus, s JobStatus) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ListJobs returns the jobs matching filter, most recently started first.
+func (e *Executor) ListJobs(filter JobFilter) []*Job {
+	var jobs []*Job
+	e.jobs.each(func(job *Job) {
+		if filter.match(job) {
+			jobs = append(jobs, job)
+		}
+	})
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Task.StartedAt.After(jobs[j].Task.StartedAt) })
+	if filter.Limit > 0 && len(jobs) > filter.Limit {
+		jobs = jobs[:filter.Limit]
+	}
+	return jobs
+}
+
+// LabelUsage is the usage attributed to one label value.
+type LabelUsage struct {
+	Jobs   int
+	Failed int
+	Usage  TokenUsage
+	Cost   float64 // USD, when a SpendTracker recorded Metadata["cost_usd"]
+}
+
+// UsageByLabel totals the finished jobs of the Executor by the value of the
+// label key, e.g. UsageByLabel("team"). Jobs without the label are counted
+// under "".
+func (e *Executor) UsageByLabel(key string) map[string]LabelUsage {
+	out := make(map[string]LabelUsage)
+	for _, job := range e.ListJobs(JobFilter{Status: []JobStatus{JobCompleted, JobFailed}}) {
+		u := out[job.Labels()[key]]
+		u.Jobs++
+		if job.Status() == JobFailed {
+			u.Failed++
+		}
+		if job.Result != nil {
+			u.Usage = addUsage(u.Usage, job.Result.TotalTokenUsage)
+			cost, _ := job.Result.Metadata["cost_usd"].(float64)
+			u.Cost += cost
+		}
+		out[job.Labels()[key]] = u
+	}
+	return out
+}
@@ -0,0 +1,268 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// LanguageDetector identifies the language of a text as an ISO 639-1 code
+// with a confidence from 0 to 1. It returns "" when it cannot tell.
+type LanguageDetector interface {
+	Detect(text string) (lang string, confidence float64)
+}
+
+// LanguagePolicy requires the final output to be in one language. The
+// model is told to answer in it, and a text answer detected as another
+// language is rewritten by a translation pass, so English-centric models can
+// serve non-English deployments. Tasks can override the language with
+// Params["language"].
+type LanguagePolicy struct {
+	Language      string           // Required ISO 639-1 code, e.g. "id" or "de"
+	Detector      LanguageDetector // Default DefaultLanguageDetector
+	MinConfidence float64          // Detections below this are accepted as is (default 0.6)
+	Translator    ModelProvider    // Model for the translation pass; defaults to the agent's own model
+	NoInstruction bool             // Do not add the language instruction to the system prompt
+
+	// Strict fails the execution with a *LanguageError when the output is
+	// still in the wrong language after translation, instead of returning it
+	// with Metadata["language_error"] set.
+	Strict bool
+}
+
+// LanguageError reports an output in the wrong language.
+type LanguageError struct {
+	Agent    string
+	Required string
+	Detected string
+}
+
+func (e *LanguageError) Error() string {
+	return fmt.Sprintf("agent %s answered in %s instead of %s", e.Agent, LanguageName(e.Detected), LanguageName(e.Required))
+}
+
+var languageNames = map[string]string{
+	"ar": "Arabic", "de": "German", "el": "Greek", "en": "English", "es": "Spanish",
+	"fr": "French", "he": "Hebrew", "hi": "Hindi", "id": "Indonesian", "it": "Italian",
+	"ja": "Japanese", "ko": "Korean", "nl": "Dutch", "pl": "Polish", "pt": "Portuguese",
+	"ru": "Russian", "sv": "Swedish", "th": "Thai", "tr": "Turkish", "zh": "Chinese",
+}
+
+// LanguageName returns the English name of an ISO 639-1 code, or the code
+// itself if it is not known.
+func LanguageName(code string) string {
+	if name, ok := languageNames[strings.ToLower(code)]; ok {
+		return name
+	}
+	return code
+}
+
+// language returns the language required for task.
+func (p *LanguagePolicy) language(task *Task) string {
+	if lang, ok := task.Params["language"].(string); ok && lang != "" {
+		return lang
+	}
+	return p.Language
+}
+
+// instruct adds the language instruction to the system prompt.
+func (p *LanguagePolicy) instruct(prompt string, task *Task) string {
+	lang := p.language(task)
+	if p.NoInstruction || lang == "" {
+		return prompt
+	}
+	instruction := fmt.Sprintf("Always write your final answer in %s, whatever the language of the input or tool results.", LanguageName(lang))
+	if prompt == "" {
+		return instruction
+	}
+	return prompt + "\n\n" + instruction
+}
+
+// wrong returns the detected language of output if it is confidently not
+// the required one, else "".
+func (p *LanguagePolicy) wrong(output string, required string) string {
+	detector := p.Detector
+	if detector == nil {
+		detector = DefaultLanguageDetector
+	}
+	minConfidence := p.MinConfidence
+	if minConfidence == 0 {
+		minConfidence = 0.6
+	}
+	lang, confidence := detector.Detect(output)
+	if lang == "" || confidence < minConfidence || strings.EqualFold(lang, required) {
+		return ""
+	}
+	return lang
+}
+
+const translatePrompt = `Rewrite the answer below in %s. Translate all prose, but keep code, URLs, names, numbers, and formatting unchanged. Reply with the rewritten answer only.
+
+Answer:
+%s`
+
+// enforce checks a text output's language and translates it when it is in
+// the wrong one, recording a "translate" step. Non-text outputs are left
+// alone.
+func (p *LanguagePolicy) enforce(ctx context.Context, defaultModel ModelProvider, agentName string, task *Task, result *Result, output interface{}) (interface{}, error) {
+	text, ok := output.(string)
+	required := p.language(task)
+	if !ok || required == "" {
+		return output, nil
+	}
+	detected := p.wrong(text, required)
+	if detected == "" {
+		return output, nil
+	}
+
+	model := p.Translator
+	if model == nil {
+		model = defaultModel
+	}
+	start := Now(ctx)
+	step := ExecutionStep{AgentName: agentName, Action: "translate", Input: text, Timestamp: start}
+	resp, err := model.Complete(ctx, &CompletionRequest{
+		History: []Message{{Role: RoleUser, Content: fmt.Sprintf(translatePrompt, LanguageName(required), text)}},
+	})
+	step.LLMLatency = Since(ctx, start)
+	step.Duration = step.LLMLatency
+	if err == nil {
+		step.TokenUsage = resp.Usage
+		step.Output = resp.Content
+		if still := p.wrong(resp.Content, required); still == "" {
+			recordStep(ctx, task, result, step)
+			result.Metadata["translated_from"] = detected
+			return resp.Content, nil
+		}
+		err = &LanguageError{Agent: agentName, Required: required, Detected: detected}
+	}
+	step.Error = err.Error()
+	recordStep(ctx, task, result, step)
+	result.Metadata["language_error"] = err.Error()
+	if p.Strict {
+		if _, isLang := err.(*LanguageError); !isLang {
+			err = fmt.Errorf("%w (translation failed: %v)", &LanguageError{Agent: agentName, Required: required, Detected: detected}, err)
+		}
+		return output, err
+	}
+	return output, nil
+}
+
+// DefaultLanguageDetector is a dependency-free heuristic detector: non-Latin
+// scripts are identified by their Unicode script, Latin-script languages by
+// common function words. Texts under a few words are not detected.
+var DefaultLanguageDetector LanguageDetector = heuristicDetector{}
+
+type heuristicDetector struct{}
+
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "of", "to", "that", "it", "for", "with", "you", "this", "was", "not", "be", "have", "on", "can", "will"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "ich", "zu", "mit", "sie", "es", "den", "auf", "für", "sich", "auch", "wird"},
+	"fr": {"le", "la", "les", "et", "est", "un", "une", "des", "du", "que", "pas", "pour", "dans", "vous", "ce", "qui", "sur", "avec", "sont"},
+	"es": {"el", "la", "los", "las", "y", "es", "un", "una", "que", "del", "no", "para", "por", "con", "se", "está", "son", "como", "pero"},
+	"it": {"il", "la", "che", "di", "e", "è", "un", "una", "non", "per", "con", "sono", "del", "della", "gli", "si", "anche", "questo", "nel"},
+	"pt": {"o", "os", "as", "e", "é", "um", "uma", "que", "não", "para", "com", "do", "da", "em", "você", "são", "mais", "como", "isso"},
+	"nl": {"de", "het", "een", "en", "is", "van", "niet", "dat", "ik", "je", "op", "te", "met", "zijn", "voor", "er", "ook", "wordt", "naar"},
+	"id": {"yang", "dan", "di", "ini", "itu", "dengan", "untuk", "tidak", "dari", "dalam", "akan", "ada", "saya", "anda", "adalah", "juga", "bisa", "ke", "atau"},
+	"tr": {"ve", "bir", "bu", "için", "ile", "ne", "çok", "değil", "olarak", "daha", "gibi", "ben", "sen", "var", "mı", "ama", "olan"},
+	"pl": {"i", "w", "nie", "na", "się", "jest", "że", "z", "do", "jak", "ale", "co", "tak", "po", "są", "dla", "jego"},
+	"sv": {"och", "att", "det", "som", "en", "är", "på", "för", "med", "inte", "har", "jag", "av", "till", "den", "om", "kan"},
+}
+
+var stopwordIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, w := range words {
+			index[w] = append(index[w], lang)
+		}
+	}
+	return index
+}()
+
+var (
+	codeSpan  = regexp.MustCompile("(?s)```.*?```|`[^`]*`|https?://\\S+")
+	wordSplit = func(r rune) bool { return !unicode.IsLetter(r) && r != '\'' }
+)
+
+func (heuristicDetector) Detect(text string) (string, float64) {
+	text = codeSpan.ReplaceAllString(text, " ")
+
+	scripts := map[string]int{}
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			scripts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			scripts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			scripts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			scripts["ru"]++
+		case unicode.Is(unicode.Arabic, r):
+			scripts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			scripts["he"]++
+		case unicode.Is(unicode.Greek, r):
+			scripts["el"]++
+		case unicode.Is(unicode.Thai, r):
+			scripts["th"]++
+		case unicode.Is(unicode.Devanagari, r):
+			scripts["hi"]++
+		}
+	}
+	if letters == 0 {
+		return "", 0
+	}
+	nonLatin := 0
+	for _, n := range scripts {
+		nonLatin += n
+	}
+	if nonLatin*2 > letters {
+		// Japanese mixes kana with Han characters
+		if scripts["ja"] > 0 {
+			scripts["ja"] += scripts["zh"]
+			delete(scripts, "zh")
+		}
+		lang, best := "", 0
+		for l, n := range scripts {
+			if n > best {
+				lang, best = l, n
+			}
+		}
+		return lang, float64(best) / float64(nonLatin)
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), wordSplit)
+	if len(words) < 4 {
+		return "", 0
+	}
+	scores := map[string]int{}
+	for _, w := range words {
+		for _, lang := range stopwordIndex[w] {
+			scores[lang]++
+		}
+	}
+	lang, best, second := "", 0, 0
+	for l, n := range scores {
+		switch {
+		case n > best || n == best && l < lang:
+			if lang != "" {
+				second = max(second, best)
+			}
+			lang, best = l, n
+		case n > second:
+			second = n
+		}
+	}
+	if best < 2 {
+		return "", 0
+	}
+	return lang, float64(best) / float64(best+second)
+}
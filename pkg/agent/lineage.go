@@ -0,0 +1,75 @@
+package agent
+
+import "sync"
+
+// ArtifactSource identifies what produced an artifact. It is recorded in
+// Artifact.Metadata under the keys "agent", "step_index", "tool", and
+// "tool_call_id".
+type ArtifactSource struct {
+	Agent      string
+	StepIndex  int // Index into the producing agent's own Result.Steps
+	Tool       string
+	ToolCallID string
+}
+
+// lineage tracks which tool call last wrote each state key. It is shared by
+// pointer between task copies, so it carries its own lock.
+type lineage struct {
+	mu      sync.Mutex
+	sources map[string]ArtifactSource
+}
+
+func (t *Task) lineageMap() *lineage {
+	if t.lineage == nil {
+		t.lineage = &lineage{sources: make(map[string]ArtifactSource)}
+	}
+	return t.lineage
+}
+
+func (l *lineage) set(key string, src ArtifactSource) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sources[key] = src
+}
+
+func (l *lineage) get(key string) (ArtifactSource, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	src, ok := l.sources[key]
+	return src, ok
+}
+
+// Lineage returns the recorded source of an artifact.
+func (a Artifact) Lineage() (ArtifactSource, bool) {
+	if a.Metadata == nil {
+		return ArtifactSource{}, false
+	}
+	id, ok := a.Metadata["tool_call_id"].(string)
+	if !ok {
+		return ArtifactSource{}, false
+	}
+	src := ArtifactSource{ToolCallID: id}
+	src.Agent, _ = a.Metadata["agent"].(string)
+	src.Tool, _ = a.Metadata["tool"].(string)
+	src.StepIndex, _ = a.Metadata["step_index"].(int)
+	return src, true
+}
+
+// ProducingStep finds the step and tool call in the result that produced the
+// artifact, so it can be traced back to the model output and arguments that
+// created it.
+func (r *Result) ProducingStep(a Artifact) (*ExecutionStep, *ToolCall, bool) {
+	src, ok := a.Lineage()
+	if !ok {
+		return nil, nil, false
+	}
+	for i := range r.Steps {
+		step := &r.Steps[i]
+		for j := range step.ToolCalls {
+			if step.ToolCalls[j].ID == src.ToolCallID {
+				return step, &step.ToolCalls[j], true
+			}
+		}
+	}
+	return nil, nil, false
+}
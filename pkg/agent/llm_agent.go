@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -14,10 +15,35 @@ type LLMAgent struct {
 	description  string
 	prompt       string
 	outputSchema map[string]interface{} // JSON schema for structured output
+	inputSchema  map[string]interface{} // JSON schema the task input must satisfy
+	paramsSchema map[string]interface{} // JSON schema the task params must satisfy
 	model        ModelProvider
 	tools        []Tool
 	subAgents    []Agent
 	maxTurns     int
+	injection    *InjectionGuard
+	reasoning    bool
+	temperature  TemperatureSchedule
+	shouldStop   func(turn int, resp *ModelResponse, state map[string]interface{}) bool
+	selfEval     *SelfEvaluation
+	defaults     *ExecutionConfig
+	registry     *ToolRegistry
+	toolMetrics  *ToolMetrics
+	statePolicy  *StatePolicy
+	jsonRepair   JSONRepairer
+	constraints  *DecodingConstraints
+	prefetch     []Predictor
+	moderation   *Moderation
+	budget       *Budget
+	environment  *Environment
+	language     *LanguagePolicy
+	continuation *Continuation
+	ctxWindow    *ContextWindow
+	stateScopes  map[string]*StateScope
+	defaultScope *StateScope
+	outputKey    string
+	delegation   *AsyncDelegation
+	toolFallback ToolFallback
 }
 
 // LLMAgentConfig holds configuration for creating an LLMAgent.
@@ -25,11 +51,115 @@ type LLMAgentConfig struct {
 	Name         string
 	Description  string
 	Prompt       string                 // System prompt/instruction
-	OutputSchema map[string]interface{} // JSON schema for structured output (optional)
+	OutputSchema map[string]interface{} // JSON schema for structured output; its properties become Result.Outputs (optional)
+	InputSchema  map[string]interface{} // JSON schema for accepted input, checked by workflow agents (optional)
+	ParamsSchema map[string]interface{} // JSON schema for Task.Params, checked on submission (optional)
 	Model        ModelProvider
 	Tools        []Tool
 	SubAgents    []Agent
-	MaxTurns     int
+	MaxTurns     int // Default turn limit; same as Defaults.MaxIterations
+
+	// Defaults are agent-level execution settings, overridden by Task.Config
+	// and per-call options (see ResolveExecutionConfig). OnEvent is only
+	// read from Task.Config (optional).
+	Defaults *ExecutionConfig
+
+	// ToolRegistry resolves tool groups requested per task through
+	// Params["toolsets"], added to Tools for that execution (optional).
+	ToolRegistry *ToolRegistry
+
+	// ToolMetrics receives every tool call (default DefaultToolMetrics).
+	ToolMetrics *ToolMetrics
+
+	// StatePolicy controls which state keys are injected into the system
+	// prompt, their format, size caps, and redaction (default
+	// DefaultStatePolicy).
+	StatePolicy *StatePolicy
+
+	// JSONRepair fixes near-valid JSON output when OutputSchema is set;
+	// Metadata["json_repaired"] notes when it changed the output (default
+	// DefaultJSONRepairer, NoJSONRepair to disable).
+	JSONRepair JSONRepairer
+
+	// Constraints are sent on every completion request for backends that
+	// support constrained decoding (optional).
+	Constraints *DecodingConstraints
+
+	// Prefetch predictors speculatively run idempotent tools from the task
+	// input in parallel with the first model call; matching tool calls use
+	// the prefetched result (optional).
+	Prefetch []Predictor
+
+	// InjectionGuard screens tool results and text files for prompt
+	// injection before they enter the prompt (optional).
+	InjectionGuard *InjectionGuard
+
+	// IncludeReasoning records ModelResponse.Reasoning on each step. Off by
+	// default since reasoning can echo sensitive prompt content.
+	IncludeReasoning bool
+
+	// TemperatureSchedule picks the temperature per turn, e.g. high for early
+	// exploration and low for the final answer. A temperature resolved from
+	// Defaults, Task.Config, or call options takes precedence (optional).
+	TemperatureSchedule TemperatureSchedule
+
+	// ShouldStop is evaluated after every turn (after tool results are applied
+	// to state). Returning true ends execution successfully with the turn's
+	// content as output (optional).
+	ShouldStop func(turn int, resp *ModelResponse, state map[string]interface{}) bool
+
+	// SelfEvaluation adds a final step where the model scores its answer
+	// into Result.Metadata["confidence"] (optional).
+	SelfEvaluation *SelfEvaluation
+
+	// Moderation screens the final output, recording category scores and
+	// optionally blocking it (optional).
+	Moderation *Moderation
+
+	// Budget tells the model each turn how many turns, tokens, and how much
+	// time it has left (optional).
+	Budget *Budget
+
+	// OutputKey stores the final output in task state under this key, so
+	// later agents in a workflow can reference it in their prompts as
+	// {key} (optional).
+	OutputKey string
+
+	// Environment adds the current date and time, timezone, locale, and
+	// units to the system prompt (optional).
+	Environment *Environment
+
+	// Language requires the final answer in one language, translating it
+	// when the model answers in another (optional).
+	Language *LanguagePolicy
+
+	// Continuation continues answers cut off by the token limit and
+	// stitches the pieces together (optional).
+	Continuation *Continuation
+
+	// ContextWindow checks each request against the model's context window
+	// before it is sent, compacting the history or failing with a
+	// *ContextLimitError (optional).
+	ContextWindow *ContextWindow
+
+	// ToolStateScopes limits, by tool name, the state each tool can read
+	// through StateFromContext and write through its view or a map result,
+	// e.g. {"mcp_search": agent.ReadOnlyState("public:*")}. Tools not listed
+	// use their StateScoped declaration, else DefaultToolStateScope.
+	ToolStateScopes map[string]*StateScope
+
+	// DefaultToolStateScope applies to tools without a scope of their own
+	// (default: full access).
+	DefaultToolStateScope *StateScope
+
+	// AsyncDelegation gives the agent delegate_async and check_delegation
+	// tools to run SubAgents in the background (optional).
+	AsyncDelegation *AsyncDelegation
+
+	// ToolFallback switches to a prompted JSON tool protocol (see
+	// PromptedToolModel) for providers without native tool calling, so the
+	// same agent works across backends (default ToolFallbackOff).
+	ToolFallback ToolFallback
 }
 
 // NewLLMAgent creates a new LLMAgent from the given configuration.
@@ -37,15 +167,49 @@ func NewLLMAgent(cfg LLMAgentConfig) *LLMAgent {
 	if cfg.MaxTurns == 0 {
 		cfg.MaxTurns = 10
 	}
+	if cfg.ToolMetrics == nil {
+		cfg.ToolMetrics = DefaultToolMetrics
+	}
+	if cfg.StatePolicy == nil {
+		cfg.StatePolicy = DefaultStatePolicy
+	}
+	if cfg.JSONRepair == nil {
+		cfg.JSONRepair = DefaultJSONRepairer
+	}
 	return &LLMAgent{
 		name:         cfg.Name,
 		description:  cfg.Description,
 		prompt:       cfg.Prompt,
 		outputSchema: cfg.OutputSchema,
+		inputSchema:  cfg.InputSchema,
+		paramsSchema: cfg.ParamsSchema,
 		model:        cfg.Model,
 		tools:        cfg.Tools,
 		subAgents:    cfg.SubAgents,
 		maxTurns:     cfg.MaxTurns,
+		injection:    cfg.InjectionGuard,
+		reasoning:    cfg.IncludeReasoning,
+		temperature:  cfg.TemperatureSchedule,
+		shouldStop:   cfg.ShouldStop,
+		selfEval:     cfg.SelfEvaluation,
+		defaults:     cfg.Defaults,
+		registry:     cfg.ToolRegistry,
+		toolMetrics:  cfg.ToolMetrics,
+		statePolicy:  cfg.StatePolicy,
+		jsonRepair:   cfg.JSONRepair,
+		constraints:  cfg.Constraints,
+		prefetch:     cfg.Prefetch,
+		moderation:   cfg.Moderation,
+		budget:       cfg.Budget,
+		outputKey:    cfg.OutputKey,
+		environment:  cfg.Environment,
+		language:     cfg.Language,
+		continuation: cfg.Continuation,
+		ctxWindow:    cfg.ContextWindow,
+		stateScopes:  cfg.ToolStateScopes,
+		defaultScope: cfg.DefaultToolStateScope,
+		delegation:   cfg.AsyncDelegation,
+		toolFallback: cfg.ToolFallback,
 	}
 }
 
@@ -61,7 +225,18 @@ func (a *LLMAgent) OutputSchema() map[string]interface{} {
 	return a.outputSchema
 }
 
+func (a *LLMAgent) InputSchema() map[string]interface{} {
+	return a.inputSchema
+}
+
+func (a *LLMAgent) ParamsSchema() map[string]interface{} {
+	return a.paramsSchema
+}
+
 func (a *LLMAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
+	ctx = EnterAgent(ctx, a.name)
+	ctx = withProgress(ctx, task, nil)
+	ctx = withUsage(ctx, task, nil, nil)
 	result := &Result{
 		TaskID:   task.ID,
 		Success:  false,
@@ -69,31 +244,75 @@ func (a *LLMAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
 		Steps:    []ExecutionStep{},
 	}
 
+	ctx, release, err := DefaultConcurrencyLimits.Acquire(ctx, a.name)
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+	defer release()
+
+	tools, err := a.toolsFor(task)
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+	tools = experimentTools(ctx, allowTools(ctx, tools))
+
 	// Build initial prompt with state injection
 	systemPrompt := a.injectState(task.State)
+	if a.environment != nil {
+		systemPrompt = a.environment.apply(systemPrompt, task)
+	}
+	if a.language != nil {
+		systemPrompt = a.language.instruct(systemPrompt, task)
+	}
 
 	// Build user message with files
+	files, parts, err := a.prepareFiles(ctx, result, task.Files)
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
 	userMsg := Message{
-		Role:    "user",
+		Role:    RoleUser,
 		Content: task.Input,
-		Parts:   []Part{},
+		Parts:   parts,
 	}
 
-	// Add file parts to user message
-	for _, file := range task.Files {
-		userMsg.Parts = append(userMsg.Parts, Part{
-			Type: file.Type,
-			Data: file.Content,
-		})
+	// Agent defaults < Task.Config (which already carries per-call overrides)
+	cfg := ResolveExecutionConfig(&ExecutionConfig{MaxIterations: a.maxTurns}, a.defaults, task.Config)
+	if cfg.UserID != "" {
+		ctx = ContextWithUserID(ctx, cfg.UserID)
+	}
+	if cfg.TimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.TimeoutSeconds)*time.Second)
+		defer cancel()
 	}
 
-	history := []Message{
-		{Role: "system", Content: systemPrompt},
-		userMsg,
+	// Background delegations share the execution's deadline and end with it
+	var delegations *delegations
+	if a.delegation != nil && len(a.subAgents) > 0 {
+		delegations = a.delegation.start(ctx, task, a.subAgents)
+		defer delegations.stop()
+		tools = mergeTools(delegations.tools(), tools)
 	}
 
-	for turn := 0; turn < a.maxTurns; turn++ {
-		stepStart := time.Now()
+	// Each tool turn appends an assistant and a user message; size the
+	// history once so appends do not reallocate it every turn
+	history := newHistory(2+2*cfg.MaxIterations,
+		Message{Role: RoleSystem, Content: systemPrompt},
+		userMsg,
+	)
+
+	// Speculative calls are abandoned once execution returns
+	prefetchCtx, cancelPrefetch := context.WithCancel(ctx)
+	defer cancelPrefetch()
+	prefetched := startPrefetch(prefetchCtx, a.prefetch, task, tools)
+	seenProgress := progressSince(ctx, nil) // Reported before this agent started
+
+	for turn := 0; turn < cfg.MaxIterations; turn++ {
+		stepStart := Now(ctx)
 		step := ExecutionStep{
 			AgentName: a.name,
 			Timestamp: stepStart,
@@ -101,34 +320,62 @@ func (a *LLMAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
 		}
 
 		// Call LLM and track latency
-		llmStart := time.Now()
+		llmStart := Now(ctx)
 
 		// Build completion request
 		req := &CompletionRequest{
 			Prompt:       task.Input,
-			Files:        task.Files,
-			Tools:        a.tools,
-			History:      history,
+			Files:        files,
+			Tools:        tools,
+			History:      history.Messages(),
 			OutputSchema: a.outputSchema,
+			Constraints:  a.constraints,
+		}
+		if tenant := TenantFromContext(ctx); tenant != nil {
+			req.Model = tenant.ModelName
+		}
+		if cfg.Model != "" {
+			req.Model = cfg.Model
+		}
+		if a.budget != nil {
+			a.budget.inject(ctx, task, a.name, req, a.budget.status(ctx, turn, cfg.MaxIterations, result))
 		}
 
-		// Add temperature from config if available
-		if task.Config != nil && task.Config.Temperature > 0 {
-			req.Temperature = &task.Config.Temperature
+		// Add temperature from config if available, else from the schedule
+		if cfg.Temperature > 0 {
+			req.Temperature = &cfg.Temperature
+		} else if a.temperature != nil {
+			temp := a.temperature(turn)
+			req.Temperature = &temp
 		}
 
-		resp, err := a.model.Complete(ctx, req)
-		step.LLMLatency = time.Since(llmStart)
+		err = a.ctxWindow.fit(ctx, a.name, req, result)
+		var resp *ModelResponse
+		if err == nil {
+			resp, err = a.complete(ctx, req, task)
+		}
+		if err == nil && a.continuation != nil && resp.Truncated() {
+			resp, err = a.continuation.extend(ctx, a, req, resp, task, result)
+		}
+		step.LLMLatency = Since(ctx, llmStart)
 		if err != nil {
 			step.Error = err.Error()
-			step.Duration = time.Since(stepStart)
-			result.Steps = append(result.Steps, step)
+			step.Duration = Since(ctx, stepStart)
+			recordStep(ctx, task, result, step)
 			result.Error = fmt.Sprintf("LLM error: %v", err)
+			result.Artifacts = a.extractArtifacts(task)
+			result.markPartial(task.State)
 			return result, err
 		}
 
 		// Record token usage from response
 		step.TokenUsage = resp.Usage
+		step.Model = resp.Model
+		step.Routing = resp.Routing
+		step.FinishReason = resp.Reason()
+		if a.reasoning {
+			step.Reasoning = resp.Reasoning
+		}
 
 		step.Action = "reasoning"
 		step.Output = resp.Content
@@ -136,51 +383,110 @@ func (a *LLMAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
 		// Handle tool calls
 		if len(resp.ToolCalls) > 0 {
 			step.Action = "tool_execution"
+			step.ToolCalls = make([]ToolCall, 0, len(resp.ToolCalls))
 			var totalToolsLatency time.Duration
+			var escalation *EventActions
+			malformed := 0
+			assignToolCallIDs(ctx, resp.ToolCalls)
 
 			for i := range resp.ToolCalls {
 				tc := &resp.ToolCalls[i]
-				tool := a.findTool(tc.Name)
 
-				if tool == nil {
-					tc.Error = fmt.Errorf("tool not found: %s", tc.Name)
+				// Unknown tools and bad arguments are sent back for correction
+				tool, verr := validateToolCall(tools, *tc)
+				if verr != nil {
+					tc.Error = verr
+					malformed++
+					step.ToolCalls = append(step.ToolCalls, *tc)
 					continue
 				}
 
-				tcStart := time.Now()
-				tcResult, tcErr := tool.Execute(ctx, tc.Arguments)
-				tc.Duration = time.Since(tcStart)
+				scope := a.toolStateScope(tool)
+				toolCtx, view := withStateView(ctx, task, &step, scope)
+				tcResult, tcErr := a.executeTool(toolCtx, prefetched, result, tool, tc)
+				denied := view.denied
 				totalToolsLatency += tc.Duration
+				a.toolMetrics.Record(tc.Name, tc.Duration, tcErr)
 				tc.Result = tcResult
 				tc.Error = tcErr
 
-				// Update task state with result
+				// Tools may return actions instead of plain output
+				if actions, ok := tcResult.(*EventActions); ok && tcErr == nil {
+					for k, v := range actions.StateDelta {
+						if !scope.writable(k) {
+							denied++
+							continue
+						}
+						setState(task, &step, k, v)
+					}
+					recordDeniedWrites(result, denied)
+					if actions.Escalate {
+						escalation = actions
+					}
+					step.ToolCalls = append(step.ToolCalls, *tc)
+					continue
+				}
+
+				// Screen the output the model will see; state keeps the raw value
+				if tcErr == nil && tcResult != nil && a.injection != nil {
+					tc.Result = a.screen(ctx, result, "tool:"+tc.Name, tcResult)
+				}
+
+				// Update task state with result, remembering which call wrote each key
 				if tcErr == nil && tcResult != nil {
+					src := ArtifactSource{Agent: a.name, StepIndex: len(result.Steps), Tool: tc.Name, ToolCallID: tc.ID}
 					if resultMap, ok := tcResult.(map[string]interface{}); ok {
 						for k, v := range resultMap {
-							task.State[k] = v
+							if !scope.writable(k) {
+								denied++
+								continue
+							}
+							setState(task, &step, k, v)
+							task.lineageMap().set(k, src)
 						}
 					} else {
-						task.State[tc.Name+"_result"] = tcResult
+						setState(task, &step, tc.Name+"_result", tcResult)
+						task.lineageMap().set(tc.Name+"_result", src)
 					}
 				}
 
+				recordDeniedWrites(result, denied)
 				step.ToolCalls = append(step.ToolCalls, *tc)
 			}
 			step.ToolsLatency = totalToolsLatency
+			if p := progressSince(ctx, seenProgress); p != nil {
+				setState(task, &step, StateProgress, *p)
+				seenProgress = p
+			}
+
+			if escalation != nil {
+				step.Action = "escalate"
+				step.Duration = Since(ctx, stepStart)
+				recordStep(ctx, task, result, step)
+				markEscalated(ctx, task, result, a.name, escalation)
+				result.aggregateMetrics()
+				return result, nil
+			}
+
+			// Add the calls and one tool message per result to the conversation
+			msgs := append(make([]Message, 0, 1+len(resp.ToolCalls)),
+				Message{Role: RoleAssistant, Content: formatToolCalls(resp.ToolCalls), ToolCalls: toolCallRequests(resp.ToolCalls)})
+			msgs = append(msgs, toolResultMessages(resp.ToolCalls)...)
+			if malformed > 0 {
+				msgs[len(msgs)-1].Content += "\n\n" + toolCallCorrection(tools, resp.ToolCalls)
+				count, _ := result.Metadata["malformed_tool_calls"].(int)
+				result.Metadata["malformed_tool_calls"] = count + malformed
+			}
+			history = history.Append(msgs...)
+
+			step.Duration = Since(ctx, stepStart)
+			recordStep(ctx, task, result, step)
+			delegations.merge(ctx, a, result)
 
-			// Add results to conversation
-			history = append(history, Message{
-				Role:    "assistant",
-				Content: formatToolCalls(resp.ToolCalls),
-			})
-			history = append(history, Message{
-				Role:    "user",
-				Content: formatToolResults(resp.ToolCalls),
-			})
-
-			step.Duration = time.Since(stepStart)
-			result.Steps = append(result.Steps, step)
+			if a.shouldStop != nil && a.shouldStop(turn, resp, task.State) {
+				result.Metadata["stop_reason"] = "should_stop"
+				return a.finish(ctx, task, result, resp.Content)
+			}
 			continue
 		}
 
@@ -190,56 +496,283 @@ func (a *LLMAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
 				if strings.Contains(strings.ToLower(resp.Content), strings.ToLower(sub.Name())) {
 					step.Action = "delegate"
 					step.Output = fmt.Sprintf("Delegating to %s", sub.Name())
-					step.Duration = time.Since(stepStart)
-					result.Steps = append(result.Steps, step)
+					step.Duration = Since(ctx, stepStart)
+					recordStep(ctx, task, result, step)
 
 					// Execute sub-agent
 					subResult, subErr := sub.Execute(ctx, task)
+					a.mergeDelegated(ctx, result, subResult)
 					if subErr != nil {
 						result.Error = fmt.Sprintf("sub-agent failed: %v", subErr)
+						result.markPartial(task.State)
 						return result, subErr
 					}
 
-					// Merge results
-					result.Steps = append(result.Steps, subResult.Steps...)
+					result.Metadata["delegated_to"] = sub.Name()
+					if propagateEscalation(result, subResult) {
+						result.aggregateMetrics()
+						return result, nil
+					}
 					result.Output = subResult.Output
-					result.Artifacts = subResult.Artifacts
+					result.Outputs = subResult.Outputs
 					result.Success = subResult.Success
+					result.aggregateMetrics()
 					return result, nil
 				}
 			}
 		}
 
+		// A filtered or failed generation is not an answer
+		if reason := step.FinishReason; reason == FinishContentFilter || reason == FinishError {
+			ierr := &IncompleteResponseError{Agent: a.name, Reason: reason}
+			step.Error = ierr.Error()
+			step.Duration = Since(ctx, stepStart)
+			recordStep(ctx, task, result, step)
+			result.Error = ierr.Error()
+			result.Artifacts = a.extractArtifacts(task)
+			result.markPartial(task.State)
+			return result, ierr
+		}
+
 		// Task complete
-		step.Duration = time.Since(stepStart)
-		result.Steps = append(result.Steps, step)
-		result.Output = resp.Content
-		result.Success = true
+		step.Duration = Since(ctx, stepStart)
+		recordStep(ctx, task, result, step)
+		if step.FinishReason == FinishLength {
+			result.Metadata["truncated"] = true
+		}
+		return a.finish(ctx, task, result, resp.Content)
+	}
 
-		// Extract artifacts from state
-		result.Artifacts = a.extractArtifacts(task.State)
+	result.Error = "max iterations reached"
+	result.Artifacts = a.extractArtifacts(task)
+	result.markPartial(task.State)
+	return result, fmt.Errorf("max iterations reached")
+}
 
-		// Aggregate metrics
-		result.aggregateMetrics()
+// mergeDelegated folds a delegated execution into the parent result: steps
+// keep their path under this agent, artifacts are kept, and metadata keys the
+// parent has not set are copied. Token usage and latency come along with the
+// steps and are totalled by aggregateMetrics.
+func (a *LLMAgent) mergeDelegated(ctx context.Context, result, subResult *Result) {
+	if subResult == nil {
+		return
+	}
+	result.appendSteps(ctx, subResult.Steps)
+	result.Artifacts = append(result.Artifacts, subResult.Artifacts...)
+	for k, v := range subResult.Metadata {
+		if _, exists := result.Metadata[k]; !exists {
+			result.Metadata[k] = v
+		}
+	}
+}
 
-		return result, nil
+// finish marks the result successful with the given output, runs the
+// optional self-evaluation, extracts artifacts from state, and aggregates
+// metrics. Structured output is repaired first if it is not valid JSON, and
+// moderated when the agent has a Moderation policy.
+func (a *LLMAgent) finish(ctx context.Context, task *Task, result *Result, output interface{}) (*Result, error) {
+	if text, ok := output.(string); ok && a.outputSchema != nil && !json.Valid([]byte(text)) {
+		if repaired, err := a.jsonRepair.Repair(text); err == nil {
+			output = repaired
+			result.Metadata["json_repaired"] = true
+		}
 	}
+	if a.language != nil {
+		var err error
+		if output, err = a.language.enforce(ctx, modelFor(ctx, a.model), a.name, task, result, output); err != nil {
+			result.Error = err.Error()
+			result.Artifacts = a.extractArtifacts(task)
+			result.markPartial(task.State)
+			return result, err
+		}
+	}
+	if a.moderation != nil {
+		if err := a.moderation.check(ctx, a.name, output, result); err != nil {
+			result.Error = err.Error()
+			result.Artifacts = a.extractArtifacts(task)
+			result.markPartial(task.State)
+			return result, err
+		}
+	}
+	result.Output = output
+	result.Outputs = namedOutputs(a.outputSchema, output)
+	result.Success = true
 
-	result.Error = "max iterations reached"
-	return result, fmt.Errorf("max iterations reached")
+	if a.selfEval != nil {
+		a.selfEval.evaluate(ctx, modelFor(ctx, a.model), a.name, task, result)
+	}
+	storeOutput(task, result, a.outputKey)
+
+	// Extract artifacts from state
+	result.Artifacts = a.extractArtifacts(task)
+
+	// Aggregate metrics
+	result.aggregateMetrics()
+
+	return result, nil
+}
+
+// complete validates the request history and calls the model, streaming
+// partial content deltas when both the provider and the execution config ask
+// for it. With prompted tools (see ToolFallback), tool call blocks are held
+// back from the stream.
+func (a *LLMAgent) complete(ctx context.Context, req *CompletionRequest, task *Task) (*ModelResponse, error) {
+	if err := ValidateHistory(req.History); err != nil {
+		return nil, err
+	}
+	model := modelFor(ctx, a.model)
+	if a.toolFallback.usePromptedTools(model, req) {
+		model = NewPromptedToolModel(model)
+	}
+	sp, ok := model.(StreamingModelProvider)
+	cfg := task.Config
+	if !ok || cfg == nil || cfg.StreamingMode == StreamingModeNone || cfg.OnEvent == nil {
+		return model.Complete(ctx, req)
+	}
+
+	var content strings.Builder
+	resp, err := sp.CompleteStream(ctx, req, func(delta string) {
+		content.WriteString(delta)
+		ev := newEvent(ctx, task.ID, a.name, EventPartial)
+		ev.AgentPath = AgentPathFromContext(ctx)
+		ev.Partial = true
+		ev.Content = delta
+		emit(task, ev)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Fall back to the assembled deltas if the provider left Content empty
+	if resp.Content == "" {
+		resp.Content = content.String()
+	}
+	return resp, nil
+}
+
+// screen runs the injection guard over untrusted content, recording any
+// findings in the result metadata. Content is returned unchanged when no guard
+// is configured or nothing is found.
+func (a *LLMAgent) screen(ctx context.Context, result *Result, source string, content interface{}) interface{} {
+	if a.injection == nil {
+		return content
+	}
+	text, ok := content.(string)
+	if !ok {
+		text = fmt.Sprint(content)
+	}
+
+	screened, findings, err := a.injection.Screen(ctx, source, text)
+	if err != nil {
+		result.Metadata["injection_errors"] = append(toStrings(result.Metadata["injection_errors"]), err.Error())
+	}
+	if len(findings) == 0 {
+		return content
+	}
+
+	existing, _ := result.Metadata["injection_findings"].([]InjectionFinding)
+	result.Metadata["injection_findings"] = append(existing, findings...)
+	return screened
+}
+
+// maxScreenedStream is the largest streamed text file buffered for
+// injection screening.
+const maxScreenedStream = 1 << 20
+
+// prepareFiles builds the user message parts for the task's files. Streamed
+// files are uploaded when the model is a FileUploader and otherwise passed
+// as the FileInput, buffered unless the reader can seek; text files are
+// screened for injection when a guard is set.
+func (a *LLMAgent) prepareFiles(ctx context.Context, result *Result, files []FileInput) ([]FileInput, []Part, error) {
+	if len(files) == 0 {
+		return files, nil, nil
+	}
+	prepared := make([]FileInput, len(files))
+	parts := make([]Part, 0, len(files))
+	for i, file := range files {
+		text := strings.HasPrefix(file.Type, "text/")
+
+		// Small streamed text must be buffered so it can be screened
+		if file.ContentReader != nil && text && a.injection != nil {
+			if n := file.Len(); n >= 0 && n <= maxScreenedStream {
+				content, err := file.Bytes()
+				if err != nil {
+					return nil, nil, fmt.Errorf("read file %s: %w", file.Name, err)
+				}
+				file.Content, file.ContentReader = content, nil
+			}
+		}
+
+		var data interface{} = file.Content
+		switch {
+		case file.ContentReader != nil:
+			if uploader, ok := modelFor(ctx, a.model).(FileUploader); ok {
+				uri, err := uploader.UploadFile(ctx, file)
+				if err != nil {
+					return nil, nil, fmt.Errorf("upload file %s: %w", file.Name, err)
+				}
+				file.URI, file.ContentReader = uri, nil
+				data = uri
+				break
+			}
+			var err error
+			if file, err = file.buffered(); err != nil {
+				return nil, nil, fmt.Errorf("read file %s: %w", file.Name, err)
+			}
+			data = file.Content
+			if file.ContentReader != nil {
+				data = file // Rewound by each encode
+			}
+		case a.injection != nil && text:
+			data = a.screen(ctx, result, "file:"+file.Name, string(file.Content))
+		}
+		prepared[i] = file
+		parts = append(parts, Part{Type: file.Type, Data: data})
+	}
+	return prepared, parts, nil
+}
+
+func toStrings(v interface{}) []string {
+	s, _ := v.([]string)
+	return s
 }
 
 func (a *LLMAgent) injectState(state map[string]interface{}) string {
-	prompt := a.prompt
-	for key, val := range state {
-		placeholder := fmt.Sprintf("{%s}", key)
-		prompt = strings.ReplaceAll(prompt, placeholder, fmt.Sprint(val))
+	return a.statePolicy.apply(a.prompt, state)
+}
+
+// toolsFor returns the per-call tools and the agent's tools, plus any tool
+// groups the task requests.
+func (a *LLMAgent) toolsFor(task *Task) ([]Tool, error) {
+	tools := a.tools
+	if task.Config != nil {
+		tools = mergeTools(task.Config.Tools, tools)
+	}
+	groups := requestedToolsets(task.Params)
+	if len(groups) == 0 {
+		return tools, nil
+	}
+	if a.registry == nil {
+		return nil, &ParamsError{Agent: a.name, Err: fmt.Errorf("toolsets requested but agent has no tool registry")}
 	}
-	return prompt
+	extra, err := a.registry.Resolve(groups)
+	if err != nil {
+		return nil, &ParamsError{Agent: a.name, Err: err}
+	}
+	return mergeTools(tools, extra), nil
+}
+
+// setState writes a task state key and records it in the step's StateDelta.
+func setState(task *Task, step *ExecutionStep, key string, value interface{}) {
+	task.State[key] = value
+	if step.StateDelta == nil {
+		step.StateDelta = make(map[string]interface{})
+	}
+	step.StateDelta[key] = value
 }
 
-func (a *LLMAgent) findTool(name string) Tool {
-	for _, t := range a.tools {
+func findTool(tools []Tool, name string) Tool {
+	for _, t := range tools {
 		if t.Name() == name {
 			return t
 		}
@@ -247,11 +780,11 @@ func (a *LLMAgent) findTool(name string) Tool {
 	return nil
 }
 
-func (a *LLMAgent) extractArtifacts(state map[string]interface{}) []Artifact {
+func (a *LLMAgent) extractArtifacts(task *Task) []Artifact {
 	var artifacts []Artifact
 
 	// Look for known artifact patterns in state
-	for key, val := range state {
+	for key, val := range task.State {
 		if strings.HasPrefix(key, "artifact_") ||
 			strings.HasSuffix(key, "_content") ||
 			strings.HasSuffix(key, "_output") {
@@ -261,6 +794,12 @@ func (a *LLMAgent) extractArtifacts(state map[string]interface{}) []Artifact {
 				Content:  val,
 				Metadata: map[string]interface{}{"key": key},
 			}
+			if src, ok := task.lineageMap().get(key); ok {
+				artifact.Metadata["agent"] = src.Agent
+				artifact.Metadata["step_index"] = src.StepIndex
+				artifact.Metadata["tool"] = src.Tool
+				artifact.Metadata["tool_call_id"] = src.ToolCallID
+			}
 			artifacts = append(artifacts, artifact)
 		}
 	}
@@ -283,28 +822,77 @@ func inferType(key string, val interface{}) string {
 	}
 }
 
+// assignToolCallIDs keeps the IDs providers assigned and generates one for
+// every call without a unique ID, so each result can be matched to its call.
+func assignToolCallIDs(ctx context.Context, calls []ToolCall) {
+	seen := make(map[string]bool, len(calls))
+	for i := range calls {
+		if calls[i].ID == "" || seen[calls[i].ID] {
+			calls[i].ID = "call_" + NewID(ctx)
+		}
+		seen[calls[i].ID] = true
+	}
+}
+
+// toolCallRequests copies the calls as the model made them, without results,
+// for the assistant message providers echo back.
+func toolCallRequests(calls []ToolCall) []ToolCall {
+	reqs := make([]ToolCall, len(calls))
+	for i, tc := range calls {
+		reqs[i] = ToolCall{ID: tc.ID, Name: tc.Name, Arguments: tc.Arguments}
+	}
+	return reqs
+}
+
 func formatToolCalls(calls []ToolCall) string {
-	var parts []string
-	for _, tc := range calls {
-		parts = append(parts, fmt.Sprintf("Calling: %s(%v)", tc.Name, tc.Arguments))
+	var b strings.Builder
+	b.Grow(64 * len(calls))
+	for i, tc := range calls {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "Calling [%s]: %s(%v)", tc.ID, tc.Name, tc.Arguments)
 	}
-	return strings.Join(parts, "\n")
+	return b.String()
 }
 
-func formatToolResults(calls []ToolCall) string {
-	var parts []string
-	for _, tc := range calls {
-		if tc.Error != nil {
-			parts = append(parts, fmt.Sprintf("%s failed: %v", tc.Name, tc.Error))
-		} else {
-			parts = append(parts, fmt.Sprintf("%s result: %v", tc.Name, tc.Result))
+// toolResultMessages returns a RoleTool message for each call, answering it
+// by ID with its result or error. Parts returned by tools (e.g.
+// screenshots) are attached so vision models receive them.
+func toolResultMessages(calls []ToolCall) []Message {
+	msgs := make([]Message, len(calls))
+	for i, tc := range calls {
+		msgs[i] = Message{Role: RoleTool, ToolCallID: tc.ID, Content: toolResultContent(tc)}
+		if p, ok := tc.Result.(Part); ok && tc.Error == nil {
+			msgs[i].Parts = []Part{p}
 		}
 	}
-	return strings.Join(parts, "\n")
+	return msgs
 }
 
-// aggregateMetrics aggregates token usage and latencies from all steps.
+// toolResultContent renders a call's result, or its error payload, as text.
+func toolResultContent(tc ToolCall) string {
+	if tc.Error != nil {
+		return toolErrorPayload(tc.Error)
+	}
+	switch r := tc.Result.(type) {
+	case string:
+		return r
+	case Part:
+		if r.Data != nil {
+			return "[" + r.Type + " attached]"
+		}
+	}
+	return fmt.Sprint(tc.Result)
+}
+
+// aggregateMetrics aggregates token usage, latencies, and per-tool stats
+// from all steps.
 func (r *Result) aggregateMetrics() {
+	r.TotalLLMLatency = 0
+	r.TotalToolsLatency = 0
+	r.TotalTokenUsage = TokenUsage{}
+
 	for _, step := range r.Steps {
 		r.TotalLLMLatency += step.LLMLatency
 		r.TotalToolsLatency += step.ToolsLatency
@@ -313,6 +901,14 @@ func (r *Result) aggregateMetrics() {
 			r.TotalTokenUsage.PromptTokens += step.TokenUsage.PromptTokens
 			r.TotalTokenUsage.CompletionTokens += step.TokenUsage.CompletionTokens
 			r.TotalTokenUsage.TotalTokens += step.TokenUsage.TotalTokens
+			r.TotalTokenUsage.ReasoningTokens += step.TokenUsage.ReasoningTokens
+		}
+	}
+
+	if stats := toolStatsFromSteps(r.Steps); len(stats) > 0 {
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]interface{})
 		}
+		r.Metadata["tool_stats"] = stats
 	}
 }
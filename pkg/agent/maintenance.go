@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrMaintenance is matched by the *MaintenanceError returned for tasks
+// submitted while intake is paused.
+var ErrMaintenance = errors.New("maintenance mode")
+
+// MaintenanceError rejects a submission while intake is paused.
+type MaintenanceError struct {
+	Reason string
+	Since  time.Time
+}
+
+func (e *MaintenanceError) Error() string {
+	if e.Reason == "" {
+		return "task intake paused for maintenance"
+	}
+	return fmt.Sprintf("task intake paused for maintenance: %s", e.Reason)
+}
+
+func (e *MaintenanceError) Is(target error) bool {
+	return target == ErrMaintenance
+}
+
+// StatusCode returns the HTTP status servers should respond with.
+func (e *MaintenanceError) StatusCode() int {
+	return http.StatusServiceUnavailable
+}
+
+// MaintenanceStatus describes the current state of a Maintenance switch.
+type MaintenanceStatus struct {
+	Enabled bool      `json:"enabled"`
+	Reason  string    `json:"reason,omitempty"`
+	Since   time.Time `json:"since,omitzero"`
+	Allow   []string  `json:"allow,omitempty"` // Agents still accepting tasks
+}
+
+// Maintenance is a kill switch for task intake. One switch can be shared by
+// every Executor in a process (WithMaintenance) so operators can stop them
+// all at once, e.g. while a misbehaving prompt is burning budget. Jobs
+// already queued or running are not affected; cancel them with
+// Executor.Cancel.
+type Maintenance struct {
+	mu     sync.RWMutex
+	status MaintenanceStatus
+	allow  map[string]bool
+}
+
+// NewMaintenance creates a switch with intake enabled.
+func NewMaintenance() *Maintenance {
+	return &Maintenance{}
+}
+
+// Enable pauses intake for every Executor using the switch, except those
+// whose agent is named in allow.
+func (m *Maintenance) Enable(reason string, allow ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status = MaintenanceStatus{Enabled: true, Reason: reason, Since: time.Now(), Allow: append([]string(nil), allow...)}
+	m.allow = make(map[string]bool, len(allow))
+	for _, name := range allow {
+		m.allow[name] = true
+	}
+}
+
+// Disable resumes intake.
+func (m *Maintenance) Disable() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status = MaintenanceStatus{}
+	m.allow = nil
+}
+
+// Status returns the current state of the switch.
+func (m *Maintenance) Status() MaintenanceStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s := m.status
+	s.Allow = append([]string(nil), s.Allow...)
+	return s
+}
+
+// check returns a *MaintenanceError if intake for the named agent is paused.
+func (m *Maintenance) check(agentName string) error {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if !m.status.Enabled || m.allow[agentName] {
+		return nil
+	}
+	return &MaintenanceError{Reason: m.status.Reason, Since: m.status.Since}
+}
+
+// WithMaintenance shares a maintenance switch with the Executor. Without it
+// each Executor has its own.
+func WithMaintenance(m *Maintenance) ExecutorOption {
+	return func(e *Executor) {
+		e.maintenance = m
+	}
+}
+
+// Maintenance returns the Executor's maintenance switch.
+func (e *Executor) Maintenance() *Maintenance {
+	return e.maintenance
+}
+
+// Pause stops the Executor accepting tasks (Submit, ExecuteSync, and
+// ResumeFrom return a *MaintenanceError) unless its agent is named in
+// allow. It flips the shared switch when one was set with WithMaintenance.
+func (e *Executor) Pause(reason string, allow ...string) {
+	e.maintenance.Enable(reason, allow...)
+}
+
+// Resume lifts a Pause.
+func (e *Executor) Resume() {
+	e.maintenance.Disable()
+}
@@ -0,0 +1,220 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Memory is a durable fact about a user, kept across sessions.
+type Memory struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	Key       string    `json:"key,omitempty"` // Storing a memory with an existing key replaces it
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Score     float64   `json:"score,omitempty"` // Relevance to the query, set by Search
+}
+
+// MemoryService stores memories per user. Every method is scoped to
+// userID, so one user's memories are never visible to another.
+// Implementations back it with a database or a vector store;
+// NewInMemoryMemoryService keeps memories in memory.
+type MemoryService interface {
+	// Store saves m for userID, replacing the memory with the same Key if
+	// there is one, and returns the stored memory.
+	Store(ctx context.Context, userID string, m Memory) (*Memory, error)
+
+	// Search returns up to limit of userID's memories most relevant to
+	// query, most relevant first. An empty query returns the most recent.
+	Search(ctx context.Context, userID, query string, limit int) ([]Memory, error)
+
+	Delete(ctx context.Context, userID, id string) error
+}
+
+// ErrMemoryNotFound is returned for unknown memory IDs.
+var ErrMemoryNotFound = &jobStateError{msg: "memory not found", status: http.StatusNotFound}
+
+// ErrNoUser is returned by the memory tools when the execution has no user
+// ID (ExecutionConfig.UserID) to scope memories to.
+var ErrNoUser = errors.New("memory requires a user ID")
+
+type userIDKey struct{}
+
+// ContextWithUserID returns ctx carrying the caller's user ID. LLMAgent sets
+// it from the resolved ExecutionConfig.UserID.
+func ContextWithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the caller's user ID, or "".
+func UserIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(userIDKey{}).(string)
+	return id
+}
+
+// InMemoryMemoryService is a MemoryService for tests and single-process
+// deployments. Search ranks memories by how many query words they contain.
+// It is safe for concurrent use.
+type InMemoryMemoryService struct {
+	mu    sync.RWMutex
+	users map[string][]*Memory
+}
+
+// NewInMemoryMemoryService creates an empty in-memory MemoryService.
+func NewInMemoryMemoryService() *InMemoryMemoryService {
+	return &InMemoryMemoryService{users: make(map[string][]*Memory)}
+}
+
+func (s *InMemoryMemoryService) Store(ctx context.Context, userID string, m Memory) (*Memory, error) {
+	if strings.TrimSpace(m.Content) == "" {
+		return nil, errors.New("memory content is required")
+	}
+	now := Now(ctx)
+	m.UserID, m.Score, m.UpdatedAt = userID, 0, now
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, old := range s.users[userID] {
+		if m.Key != "" && old.Key == m.Key || m.ID != "" && old.ID == m.ID {
+			m.ID, m.CreatedAt = old.ID, old.CreatedAt
+			s.users[userID][i] = &m
+			c := m
+			return &c, nil
+		}
+	}
+	if m.ID == "" {
+		m.ID = NewID(ctx)
+	}
+	m.CreatedAt = now
+	s.users[userID] = append(s.users[userID], &m)
+	c := m
+	return &c, nil
+}
+
+func (s *InMemoryMemoryService) Search(ctx context.Context, userID, query string, limit int) ([]Memory, error) {
+	terms := memoryTerms(query)
+	s.mu.RLock()
+	hits := []Memory{}
+	for _, m := range s.users[userID] {
+		hit := *m
+		if len(terms) > 0 {
+			words := make(map[string]bool)
+			for _, w := range memoryTerms(m.Key + " " + m.Content) {
+				words[w] = true
+			}
+			matched := 0
+			for _, t := range terms {
+				if words[t] {
+					matched++
+				}
+			}
+			if matched == 0 {
+				continue
+			}
+			hit.Score = float64(matched) / float64(len(terms))
+		}
+		hits = append(hits, hit)
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].UpdatedAt.After(hits[j].UpdatedAt)
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+func (s *InMemoryMemoryService) Delete(ctx context.Context, userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, m := range s.users[userID] {
+		if m.ID == id {
+			s.users[userID] = append(s.users[userID][:i], s.users[userID][i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrMemoryNotFound, id)
+}
+
+// memoryTerms splits text into lowercase words.
+func memoryTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// memoryStoreSchema and memorySearchSchema are the parameter schemas of the
+// memory tools.
+var (
+	memoryStoreSchema = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"content": map[string]interface{}{"type": "string", "description": "The fact to remember, as a self-contained sentence"},
+			"key":     map[string]interface{}{"type": "string", "description": "Short stable name for the fact, e.g. \"preferred_language\"; storing the same key again replaces it"},
+		},
+		"required": []string{"content"},
+	}
+	memorySearchSchema = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{"type": "string", "description": "What to look for; empty returns the most recent memories"},
+			"limit": map[string]interface{}{"type": "integer", "description": "Maximum number of memories to return (default 5)"},
+		},
+	}
+)
+
+// NewMemoryTools returns a "memory_store" tool that saves a durable fact
+// about the user and a "memory_search" tool that retrieves them, both bound
+// to svc and scoped to the execution's user (UserIDFromContext). Without a
+// user ID they fail with ErrNoUser rather than share memories.
+func NewMemoryTools(svc MemoryService) []Tool {
+	store := NewFuncTool("memory_store",
+		"Remember a durable fact about the user (preferences, details they shared) for future conversations.",
+		memoryStoreSchema,
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			userID := UserIDFromContext(ctx)
+			if userID == "" {
+				return nil, ErrNoUser
+			}
+			content, _ := args["content"].(string)
+			key, _ := args["key"].(string)
+			m, err := svc.Store(ctx, userID, Memory{Key: key, Content: content})
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"id": m.ID, "stored": true}, nil
+		})
+	search := NewFuncTool("memory_search",
+		"Search what you remember about the user from earlier conversations.",
+		memorySearchSchema,
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			userID := UserIDFromContext(ctx)
+			if userID == "" {
+				return nil, ErrNoUser
+			}
+			query, _ := args["query"].(string)
+			limit := 5
+			if n, ok := args["limit"].(float64); ok && n > 0 {
+				limit = int(n)
+			}
+			memories, err := svc.Search(ctx, userID, query, limit)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"memories": memories}, nil
+		})
+	return []Tool{store, search}
+}
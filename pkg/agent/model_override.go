@@ -0,0 +1,35 @@
+package agent
+
+import "context"
+
+type modelOverrideKey struct{}
+
+// modelOverride decorates an agent so its subtree runs on another model.
+type modelOverride struct {
+	Agent
+	model ModelProvider
+}
+
+// WithModel wraps ag so every LLMAgent in its subtree (ag itself, or the
+// children of a workflow agent) calls provider instead of its configured
+// model, e.g. to run a critic on a cheaper model without rebuilding it. The
+// innermost override wins when decorators are nested.
+func WithModel(ag Agent, provider ModelProvider) Agent {
+	return &modelOverride{Agent: ag, model: provider}
+}
+
+func (a *modelOverride) Unwrap() Agent {
+	return a.Agent
+}
+
+func (a *modelOverride) Execute(ctx context.Context, task *Task) (*Result, error) {
+	return a.Agent.Execute(context.WithValue(ctx, modelOverrideKey{}, a.model), task)
+}
+
+// modelFor returns the model set by an enclosing WithModel, or fallback.
+func modelFor(ctx context.Context, fallback ModelProvider) ModelProvider {
+	if m, ok := ctx.Value(modelOverrideKey{}).(ModelProvider); ok {
+		return m
+	}
+	return fallback
+}
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ModerationResult is a moderator's verdict on a text.
+type ModerationResult struct {
+	Flagged    bool               `json:"flagged"`    // The moderator's own verdict
+	Categories map[string]float64 `json:"categories"` // Score in [0, 1] per category, e.g. "harassment"
+}
+
+// Moderator classifies text for harmful content. See package
+// providers/openai for the OpenAI moderation endpoint.
+type Moderator interface {
+	Moderate(ctx context.Context, text string) (*ModerationResult, error)
+}
+
+// ModeratorFunc adapts a function to the Moderator interface.
+type ModeratorFunc func(ctx context.Context, text string) (*ModerationResult, error)
+
+func (f ModeratorFunc) Moderate(ctx context.Context, text string) (*ModerationResult, error) {
+	return f(ctx, text)
+}
+
+// Moderation runs a moderator over an LLMAgent's final output. Scores are
+// recorded in Result.Metadata["moderation"]; with thresholds or BlockFlagged
+// set, offending outputs fail the execution with a *ModerationError.
+type Moderation struct {
+	Moderator Moderator
+
+	// Thresholds block outputs whose category score reaches the threshold.
+	// The "*" entry applies to categories without their own (optional).
+	Thresholds map[string]float64
+
+	// BlockFlagged blocks outputs the moderator itself flags.
+	BlockFlagged bool
+
+	// FailClosed blocks outputs when the moderator errors. By default the
+	// error is recorded in Metadata["moderation_error"] and the output passes.
+	FailClosed bool
+}
+
+// ModerationError reports an output blocked by moderation.
+type ModerationError struct {
+	Agent      string
+	Categories []string // Categories over threshold; empty if only flagged or the moderator failed
+	Err        error    // Moderator error when failing closed
+}
+
+func (e *ModerationError) Error() string {
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("moderation of %s output failed: %v", e.Agent, e.Err)
+	case len(e.Categories) > 0:
+		return fmt.Sprintf("moderation blocked %s output: %s", e.Agent, strings.Join(e.Categories, ", "))
+	default:
+		return fmt.Sprintf("moderation blocked %s output: flagged", e.Agent)
+	}
+}
+
+func (e *ModerationError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the HTTP status servers should respond with.
+func (e *ModerationError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// check moderates output, recording scores on the result. It returns a
+// *ModerationError if the output must be blocked.
+func (m *Moderation) check(ctx context.Context, agentName string, output interface{}, result *Result) error {
+	text, ok := output.(string)
+	if !ok {
+		text = fmt.Sprint(output)
+	}
+	res, err := m.Moderator.Moderate(ctx, text)
+	if err != nil {
+		if m.FailClosed {
+			return &ModerationError{Agent: agentName, Err: err}
+		}
+		result.Metadata["moderation_error"] = err.Error()
+		return nil
+	}
+	result.Metadata["moderation"] = res
+
+	var over []string
+	for category, score := range res.Categories {
+		threshold, ok := m.Thresholds[category]
+		if !ok {
+			threshold, ok = m.Thresholds["*"]
+		}
+		if ok && score >= threshold {
+			over = append(over, category)
+		}
+	}
+	if len(over) == 0 && !(m.BlockFlagged && res.Flagged) {
+		return nil
+	}
+	sort.Strings(over)
+	return &ModerationError{Agent: agentName, Categories: over}
+}
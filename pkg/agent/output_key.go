@@ -0,0 +1,49 @@
+package agent
+
+import "context"
+
+// outputKeyAgent stores its agent's final output in task state.
+type outputKeyAgent struct {
+	Agent
+	key string
+}
+
+// WithOutputKey stores ag's output in task state under key when it
+// succeeds, so later stages of a Sequential or Pipeline composition can
+// reference it in their prompts as {key}. For an LLMAgent, setting
+// LLMAgentConfig.OutputKey does the same.
+func WithOutputKey(ag Agent, key string) Agent {
+	return &outputKeyAgent{Agent: ag, key: key}
+}
+
+func (a *outputKeyAgent) Unwrap() Agent {
+	return a.Agent
+}
+
+func (a *outputKeyAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
+	result, err := a.Agent.Execute(ctx, task)
+	if err == nil && result != nil && result.Success {
+		storeOutput(task, result, a.key)
+	}
+	return result, err
+}
+
+// storeOutput writes the result's output to task state under key and adds
+// it to the last step's StateDelta, so ParallelAgent merges it back like
+// any other state write.
+func storeOutput(task *Task, result *Result, key string) {
+	if key == "" {
+		return
+	}
+	task.State[key] = result.Output
+	if n := len(result.Steps); n > 0 {
+		// The delta may already be shared with emitted events; replace it
+		step := &result.Steps[n-1]
+		delta := make(map[string]interface{}, len(step.StateDelta)+1)
+		for k, v := range step.StateDelta {
+			delta[k] = v
+		}
+		delta[key] = result.Output
+		step.StateDelta = delta
+	}
+}
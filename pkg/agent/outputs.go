@@ -0,0 +1,147 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrNoOutput is returned by Result.DecodeOutput for outputs the result does
+// not have.
+var ErrNoOutput = errors.New("no such output")
+
+// namedOutputs splits a structured final answer into the top-level
+// properties declared by schema, for Result.Outputs. It returns nil when the
+// schema declares no properties or output is not a JSON object.
+func namedOutputs(schema map[string]interface{}, output interface{}) map[string]interface{} {
+	props, _ := schema["properties"].(map[string]interface{})
+	if len(props) == 0 {
+		return nil
+	}
+	obj := jsonObject(output)
+	if obj == nil {
+		return nil
+	}
+	outputs := make(map[string]interface{}, len(props))
+	for name := range props {
+		if v, ok := obj[name]; ok {
+			outputs[name] = v
+		}
+	}
+	return outputs
+}
+
+// reparseOutputs re-reads the named outputs from output after it was
+// rewritten, e.g. redacted, keeping the names outputs already had.
+func reparseOutputs(outputs map[string]interface{}, output interface{}) map[string]interface{} {
+	if outputs == nil {
+		return nil
+	}
+	obj := jsonObject(output)
+	if obj == nil {
+		return nil
+	}
+	updated := make(map[string]interface{}, len(outputs))
+	for name := range outputs {
+		if v, ok := obj[name]; ok {
+			updated[name] = v
+		}
+	}
+	return updated
+}
+
+func jsonObject(output interface{}) map[string]interface{} {
+	switch v := output.(type) {
+	case map[string]interface{}:
+		return v
+	case string:
+		var obj map[string]interface{}
+		if json.Unmarshal([]byte(v), &obj) != nil {
+			return nil
+		}
+		return obj
+	}
+	return nil
+}
+
+// OutputValue returns the named output, or false if the result lacks it.
+func (r *Result) OutputValue(name string) (interface{}, bool) {
+	v, ok := r.Outputs[name]
+	return v, ok
+}
+
+// OutputString returns the named output if it is a string.
+func (r *Result) OutputString(name string) (string, bool) {
+	s, ok := r.Outputs[name].(string)
+	return s, ok
+}
+
+// OutputFloat returns the named output if it is a number.
+func (r *Result) OutputFloat(name string) (float64, bool) {
+	switch v := r.Outputs[name].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// OutputInt returns the named output if it is a whole number.
+func (r *Result) OutputInt(name string) (int, bool) {
+	f, ok := r.OutputFloat(name)
+	if !ok || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// OutputBool returns the named output if it is a boolean.
+func (r *Result) OutputBool(name string) (bool, bool) {
+	b, ok := r.Outputs[name].(bool)
+	return b, ok
+}
+
+// OutputStrings returns the named output if it is a list of strings.
+func (r *Result) OutputStrings(name string) ([]string, bool) {
+	switch v := r.Outputs[name].(type) {
+	case []string:
+		return v, true
+	case []interface{}:
+		list := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			list[i] = s
+		}
+		return list, true
+	}
+	return nil, false
+}
+
+// DecodeOutput decodes the named output into v, typically a pointer to a
+// struct or slice, through its JSON encoding.
+func (r *Result) DecodeOutput(name string, v interface{}) error {
+	out, ok := r.Outputs[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoOutput, name)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("output %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("output %s: %w", name, err)
+	}
+	return nil
+}
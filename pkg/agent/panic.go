@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error of a job whose agent or tool panicked. The panic
+// is recovered so the worker survives; Stack holds the goroutine trace.
+type PanicError struct {
+	Value interface{}
+	Stack string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+func newPanicError(v interface{}) *PanicError {
+	return &PanicError{Value: v, Stack: string(debug.Stack())}
+}
+
+// recoverPanic converts a panic in the calling goroutine into a *PanicError
+// stored in err. It must be deferred directly.
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = newPanicError(r)
+	}
+}
+
+// runAgent executes a job's task once the agent has a concurrency slot,
+// recovering a panic from the agent or its tools into a *PanicError.
+func (e *Executor) runAgent(ctx context.Context, job *Job) (result *Result, err error) {
+	ctx, release, err := e.concurrency.Acquire(ctx, e.agent.Name())
+	if err != nil {
+		return &Result{TaskID: job.Task.ID, Error: err.Error(), Metadata: map[string]interface{}{}}, err
+	}
+	defer release()
+	defer recoverPanic(&err)
+	return e.agent.Execute(ctx, job.Task)
+}
+
+// recordPanic fails a job's result with the panic value and stack trace in
+// its metadata, and reports it to the panic handler.
+func (e *Executor) recordPanic(job *Job, result *Result, perr *PanicError) *Result {
+	e.counters.panics.Add(1)
+	if result == nil {
+		result = &Result{TaskID: job.Task.ID}
+	}
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]interface{})
+	}
+	result.Success = false
+	result.Error = perr.Error()
+	result.Metadata["panic"] = fmt.Sprint(perr.Value)
+	result.Metadata["panic_stack"] = perr.Stack
+	if e.onPanic != nil {
+		e.onPanic(job, perr)
+	}
+	return result
+}
@@ -0,0 +1,25 @@
+package agent
+
+import "context"
+
+// markPartial flags a failed result as partial. Completed steps and
+// accumulated artifacts are kept and the last good state is snapshotted, so
+// callers can resume or salvage work instead of getting only an error.
+func (r *Result) markPartial(state map[string]interface{}) {
+	r.Partial = true
+	r.Success = false
+	r.State = make(map[string]interface{}, len(state))
+	for k, v := range state {
+		r.State[k] = v
+	}
+	r.aggregateMetrics()
+}
+
+// mergePartial folds in whatever a failed sub-agent completed before failing.
+func (r *Result) mergePartial(ctx context.Context, sub *Result) {
+	if sub == nil {
+		return
+	}
+	r.appendSteps(ctx, sub.Steps)
+	r.Artifacts = append(r.Artifacts, sub.Artifacts...)
+}
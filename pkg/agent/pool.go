@@ -0,0 +1,256 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AgentPoolConfig configures an AgentPool.
+type AgentPoolConfig struct {
+	Name string // Agent name reported by the pool
+
+	// New builds one instance, e.g. an LLMAgent with its toolkits loaded.
+	// Instances are warmed like Executor.Warmup warms its agent before they
+	// are handed out.
+	New func(ctx context.Context) (Agent, error)
+
+	Size          int           // Warm instances kept ready (default 2)
+	MaxActive     int           // Instances checked out at once; more wait (0 = no limit)
+	MaxUses       int           // Checkouts before an instance is retired (0 = no limit)
+	WarmupTimeout time.Duration // Per-instance build and warmup limit for refills (default 1m)
+}
+
+// AgentPoolStats describes an AgentPool's instances and checkouts.
+type AgentPoolStats struct {
+	Idle       int   // Warm instances ready for checkout
+	Active     int64 // Instances checked out
+	Created    int64 // Instances built
+	WarmHits   int64 // Checkouts served by a warm instance
+	ColdStarts int64 // Checkouts that had to build an instance
+	Retired    int64 // Instances dropped after MaxUses or a panic
+	RefillErr  error // Error of the last failed background refill, if any
+}
+
+// ErrPoolClosed is returned by AgentPool.Acquire after Close.
+var ErrPoolClosed = errors.New("agent pool is closed")
+
+// AgentPool keeps pre-built, warmed agent instances ready so interactive
+// requests skip their initialization: each execution checks an instance out
+// and returns it afterwards, and the pool refills itself in the background.
+// The pool is itself an Agent, so it can back an Executor or server
+// directly, and a Warmer, so WithWarmup fills it at startup. It is safe for
+// concurrent use.
+type AgentPool struct {
+	cfg    AgentPoolConfig
+	idle   chan *pooledAgent
+	active chan struct{} // Slots for MaxActive; nil without a limit
+
+	fillMu    sync.Mutex // Serializes fills so they do not overbuild
+	closed    atomic.Bool
+	refilling atomic.Bool
+	refillErr atomic.Pointer[error]
+
+	inUse      atomic.Int64
+	created    atomic.Int64
+	warmHits   atomic.Int64
+	coldStarts atomic.Int64
+	retired    atomic.Int64
+}
+
+type pooledAgent struct {
+	agent Agent
+	uses  int
+}
+
+// NewAgentPool creates a pool and starts filling it in the background.
+func NewAgentPool(cfg AgentPoolConfig) *AgentPool {
+	if cfg.Size <= 0 {
+		cfg.Size = 2
+	}
+	if cfg.WarmupTimeout <= 0 {
+		cfg.WarmupTimeout = time.Minute
+	}
+	p := &AgentPool{cfg: cfg, idle: make(chan *pooledAgent, cfg.Size)}
+	if cfg.MaxActive > 0 {
+		p.active = make(chan struct{}, cfg.MaxActive)
+	}
+	p.refill()
+	return p
+}
+
+func (p *AgentPool) Name() string {
+	return p.cfg.Name
+}
+
+func (p *AgentPool) SubAgents() []Agent {
+	return nil
+}
+
+// Execute runs task on a checked-out instance.
+func (p *AgentPool) Execute(ctx context.Context, task *Task) (*Result, error) {
+	ag, release, err := p.Acquire(ctx)
+	if err != nil {
+		return &Result{TaskID: task.ID, Error: err.Error(), Metadata: map[string]interface{}{}}, err
+	}
+	// A panicking instance may be half-updated; retire it
+	defer func() {
+		r := recover()
+		var perr *PanicError
+		release(r != nil || errors.As(err, &perr))
+		if r != nil {
+			panic(r)
+		}
+	}()
+	result, err := ag.Execute(ctx, task)
+	return result, err
+}
+
+// Acquire checks out an instance, building one if none is warm, and waits
+// while MaxActive instances are out. The returned func gives the instance
+// back; passing true retires it instead, e.g. after a failure that may have
+// left it in a bad state. It fails when ctx is done first, when building an
+// instance fails, or after Close.
+func (p *AgentPool) Acquire(ctx context.Context) (Agent, func(retire bool), error) {
+	if p.closed.Load() {
+		return nil, nil, ErrPoolClosed
+	}
+	if p.active != nil {
+		select {
+		case p.active <- struct{}{}:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	var inst *pooledAgent
+	select {
+	case inst = <-p.idle:
+		p.warmHits.Add(1)
+	default:
+		p.coldStarts.Add(1)
+		ag, err := p.build(ctx)
+		if err != nil {
+			if p.active != nil {
+				<-p.active
+			}
+			return nil, nil, err
+		}
+		inst = &pooledAgent{agent: ag}
+	}
+	inst.uses++
+	p.inUse.Add(1)
+	p.refill()
+
+	var once sync.Once
+	release := func(retire bool) {
+		once.Do(func() {
+			p.inUse.Add(-1)
+			if p.active != nil {
+				<-p.active
+			}
+			if retire || p.closed.Load() || p.cfg.MaxUses > 0 && inst.uses >= p.cfg.MaxUses {
+				p.retired.Add(1)
+				p.refill()
+				return
+			}
+			select {
+			case p.idle <- inst:
+			default: // Pool is full; drop the extra instance
+			}
+		})
+	}
+	return inst.agent, release, nil
+}
+
+// build creates and warms one instance.
+func (p *AgentPool) build(ctx context.Context) (Agent, error) {
+	ag, err := p.cfg.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("build pooled agent %s: %w", p.cfg.Name, err)
+	}
+	p.created.Add(1)
+	var errs []error
+	for _, w := range collectWarmers(ag) {
+		if err := w.Warmup(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", w, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("warm up pooled agent %s: %w", p.cfg.Name, err)
+	}
+	return ag, nil
+}
+
+// refill tops the idle instances up to Size in the background.
+func (p *AgentPool) refill() {
+	if len(p.idle) >= p.cfg.Size || p.closed.Load() || !p.refilling.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer p.refilling.Store(false)
+		ctx, cancel := context.WithTimeout(context.Background(), p.cfg.WarmupTimeout)
+		defer cancel()
+		if err := p.fill(ctx); err != nil {
+			p.refillErr.Store(&err)
+		} else {
+			p.refillErr.Store(nil)
+		}
+	}()
+}
+
+// fill builds instances until Size are idle.
+func (p *AgentPool) fill(ctx context.Context) error {
+	p.fillMu.Lock()
+	defer p.fillMu.Unlock()
+	for len(p.idle) < p.cfg.Size && !p.closed.Load() {
+		ag, err := p.build(ctx)
+		if err != nil {
+			return err
+		}
+		select {
+		case p.idle <- &pooledAgent{agent: ag}:
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// Warmup fills the pool to Size, waiting for the instances to be built and
+// warmed.
+func (p *AgentPool) Warmup(ctx context.Context) error {
+	return p.fill(ctx)
+}
+
+// Stats returns the pool's current counts.
+func (p *AgentPool) Stats() AgentPoolStats {
+	s := AgentPoolStats{
+		Idle:       len(p.idle),
+		Active:     p.inUse.Load(),
+		Created:    p.created.Load(),
+		WarmHits:   p.warmHits.Load(),
+		ColdStarts: p.coldStarts.Load(),
+		Retired:    p.retired.Load(),
+	}
+	if err := p.refillErr.Load(); err != nil {
+		s.RefillErr = *err
+	}
+	return s
+}
+
+// Close stops refilling and drops the idle instances. Checked-out
+// instances are dropped when released.
+func (p *AgentPool) Close() {
+	p.closed.Store(true)
+	for {
+		select {
+		case <-p.idle:
+		default:
+			return
+		}
+	}
+}
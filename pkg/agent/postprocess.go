@@ -0,0 +1,394 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PostProcessor transforms an agent's final output, e.g. to normalize
+// formatting or remove unwanted content. Processors that only handle text
+// return other outputs unchanged. A non-nil error fails the execution.
+type PostProcessor interface {
+	Name() string
+	Process(ctx context.Context, output interface{}) (interface{}, error)
+}
+
+// PostProcessError reports a post-processor that failed.
+type PostProcessError struct {
+	Processor string
+	Err       error
+}
+
+func (e *PostProcessError) Error() string {
+	return fmt.Sprintf("post-processor %s failed: %v", e.Processor, e.Err)
+}
+
+func (e *PostProcessError) Unwrap() error {
+	return e.Err
+}
+
+type funcPostProcessor struct {
+	name string
+	fn   func(ctx context.Context, output interface{}) (interface{}, error)
+}
+
+// NewPostProcessor creates a PostProcessor from a function.
+func NewPostProcessor(name string, fn func(ctx context.Context, output interface{}) (interface{}, error)) PostProcessor {
+	return &funcPostProcessor{name: name, fn: fn}
+}
+
+// TextPostProcessor creates a PostProcessor that rewrites string outputs and
+// passes other outputs through.
+func TextPostProcessor(name string, fn func(ctx context.Context, text string) (string, error)) PostProcessor {
+	return NewPostProcessor(name, func(ctx context.Context, output interface{}) (interface{}, error) {
+		text, ok := output.(string)
+		if !ok {
+			return output, nil
+		}
+		return fn(ctx, text)
+	})
+}
+
+func (p *funcPostProcessor) Name() string {
+	return p.name
+}
+
+func (p *funcPostProcessor) Process(ctx context.Context, output interface{}) (interface{}, error) {
+	return p.fn(ctx, output)
+}
+
+// ApplyPostProcessors runs output through procs in order. It returns the
+// final output and the names of the processors that changed it.
+func ApplyPostProcessors(ctx context.Context, output interface{}, procs ...PostProcessor) (interface{}, []string, error) {
+	var changed []string
+	for _, p := range procs {
+		before := output
+		next, err := p.Process(ctx, output)
+		if err != nil {
+			return output, changed, &PostProcessError{Processor: p.Name(), Err: err}
+		}
+		output = next
+		if fmt.Sprint(before) != fmt.Sprint(next) {
+			changed = append(changed, p.Name())
+		}
+	}
+	return output, changed, nil
+}
+
+// postProcessed decorates an agent so its successful outputs pass through a
+// chain of post-processors.
+type postProcessed struct {
+	Agent
+	procs []PostProcessor
+}
+
+// WithPostProcessing wraps ag so each successful output is run through
+// procs before it reaches the caller. The chain is recorded as a
+// "postprocess" step, and the processors that changed the output are listed
+// in Metadata["postprocessed"]. Build the chain once and share it across
+// agents, or apply it to every job with the WithPostProcessors option.
+func WithPostProcessing(ag Agent, procs ...PostProcessor) Agent {
+	return &postProcessed{Agent: ag, procs: procs}
+}
+
+func (a *postProcessed) Unwrap() Agent {
+	return a.Agent
+}
+
+func (a *postProcessed) Execute(ctx context.Context, task *Task) (*Result, error) {
+	result, err := a.Agent.Execute(ctx, task)
+	if err != nil || result == nil || !result.Success || result.Escalated() {
+		return result, err
+	}
+
+	stepStart := Now(ctx)
+	output, changed, err := ApplyPostProcessors(ctx, result.Output, a.procs...)
+	step := ExecutionStep{
+		AgentName: a.Agent.Name(),
+		Action:    "postprocess",
+		Input:     result.Output,
+		Output:    output,
+		Timestamp: stepStart,
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	step.Duration = Since(ctx, stepStart)
+	recordStep(ctx, task, result, step)
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]interface{})
+	}
+	if err != nil {
+		result.Error = err.Error()
+		result.markPartial(task.State)
+		return result, err
+	}
+	result.Output = output
+	result.Outputs = reparseOutputs(result.Outputs, output)
+	if len(changed) > 0 {
+		result.Metadata["postprocessed"] = changed
+	}
+	return result, nil
+}
+
+var (
+	fenceLine   = regexp.MustCompile("^\\s*(```+|~~~+)")
+	bulletLine  = regexp.MustCompile(`^(\s*)[*+](\s+)`)
+	headingLine = regexp.MustCompile(`^(#{1,6})([^#\s])`)
+)
+
+// NormalizeMarkdown returns a post-processor that tidies Markdown text:
+// line endings become \n, trailing whitespace is removed, runs of blank
+// lines collapse to one, "*" and "+" bullets become "-", headings get a space
+// after the #s, and an unterminated code fence is closed. Code blocks are
+// left untouched.
+func NormalizeMarkdown() PostProcessor {
+	return TextPostProcessor("markdown", func(ctx context.Context, text string) (string, error) {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		var out []string
+		var fence string
+		blank := false
+		for _, line := range strings.Split(text, "\n") {
+			if m := fenceLine.FindStringSubmatch(line); m != nil {
+				switch {
+				case fence == "":
+					fence = m[1]
+				case strings.HasPrefix(m[1], fence[:1]) && len(m[1]) >= len(fence):
+					fence = ""
+				}
+				out = append(out, strings.TrimRightFunc(line, unicode.IsSpace))
+				blank = false
+				continue
+			}
+			if fence != "" {
+				out = append(out, line)
+				continue
+			}
+			line = strings.TrimRightFunc(line, unicode.IsSpace)
+			if line == "" {
+				if !blank && len(out) > 0 {
+					out = append(out, "")
+				}
+				blank = true
+				continue
+			}
+			blank = false
+			line = bulletLine.ReplaceAllString(line, "$1-$2")
+			line = headingLine.ReplaceAllString(line, "$1 $2")
+			out = append(out, line)
+		}
+		for len(out) > 0 && out[len(out)-1] == "" {
+			out = out[:len(out)-1]
+		}
+		if fence != "" {
+			out = append(out, fence)
+		}
+		return strings.Join(out, "\n"), nil
+	})
+}
+
+// TrimLength returns a post-processor that shortens text longer than max
+// characters, cutting at a word boundary when one is near and ending with
+// an ellipsis. The result is at most max characters.
+func TrimLength(max int) PostProcessor {
+	return TextPostProcessor("trim", func(ctx context.Context, text string) (string, error) {
+		if max <= 0 || utf8.RuneCountInString(text) <= max {
+			return text, nil
+		}
+		const ellipsis = "…"
+		runes := []rune(text)[:max-1]
+		cut := len(runes)
+		for i := len(runes) - 1; i >= len(runes)*4/5; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+		return strings.TrimRightFunc(string(runes[:cut]), func(r rune) bool {
+			return unicode.IsSpace(r) || unicode.IsPunct(r)
+		}) + ellipsis, nil
+	})
+}
+
+// FilterProfanity returns a post-processor that masks each whole-word,
+// case-insensitive occurrence of words with asterisks.
+func FilterProfanity(words ...string) PostProcessor {
+	quoted := make([]string, 0, len(words))
+	for _, w := range words {
+		if w != "" {
+			quoted = append(quoted, regexp.QuoteMeta(w))
+		}
+	}
+	var re *regexp.Regexp
+	if len(quoted) > 0 {
+		re = regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+	}
+	return TextPostProcessor("profanity", func(ctx context.Context, text string) (string, error) {
+		if re == nil {
+			return text, nil
+		}
+		return re.ReplaceAllStringFunc(text, func(w string) string {
+			return strings.Repeat("*", utf8.RuneCountInString(w))
+		}), nil
+	})
+}
+
+// LinkPolicy selects what ValidateLinks does with broken links.
+type LinkPolicy int
+
+const (
+	// LinkRemove unlinks broken Markdown links, keeping their text, and
+	// replaces broken bare URLs with LinkConfig.Replacement.
+	LinkRemove LinkPolicy = iota
+	// LinkFail fails the execution, listing the broken links.
+	LinkFail
+)
+
+// LinkConfig configures ValidateLinks.
+type LinkConfig struct {
+	Client       *http.Client  // Client for checks (default http.DefaultClient)
+	Timeout      time.Duration // Per-link check timeout (default 5s)
+	AllowedHosts []string      // If set, links to other hosts are broken without being fetched
+	NoFetch      bool          // Only apply AllowedHosts; make no requests
+	Policy       LinkPolicy
+	Replacement  string // Text for removed bare URLs (default "[link removed]")
+}
+
+var (
+	markdownLink = regexp.MustCompile(`\[([^\]]*)\]\((https?://[^\s)]+)\)`)
+	bareLink     = regexp.MustCompile(`https?://[^\s<>()\[\]"']+`)
+)
+
+// ValidateLinks returns a post-processor that checks the http(s) links in
+// text and handles unreachable ones (request errors or 4xx/5xx statuses)
+// according to cfg.Policy. Each distinct URL is checked once, concurrently.
+func ValidateLinks(cfg LinkConfig) PostProcessor {
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.Replacement == "" {
+		cfg.Replacement = "[link removed]"
+	}
+	return TextPostProcessor("links", func(ctx context.Context, text string) (string, error) {
+		var urls []string
+		for _, u := range bareLink.FindAllString(text, -1) {
+			urls = append(urls, strings.TrimRight(u, ".,;:!?"))
+		}
+		broken := cfg.check(ctx, urls)
+		if len(broken) == 0 {
+			return text, nil
+		}
+
+		if cfg.Policy == LinkFail {
+			var list []string
+			for _, u := range urls {
+				if broken[u] && !slices.Contains(list, u) {
+					list = append(list, u)
+				}
+			}
+			return text, fmt.Errorf("broken links: %s", strings.Join(list, ", "))
+		}
+
+		text = markdownLink.ReplaceAllStringFunc(text, func(link string) string {
+			m := markdownLink.FindStringSubmatch(link)
+			if broken[m[2]] {
+				return m[1]
+			}
+			return link
+		})
+		return bareLink.ReplaceAllStringFunc(text, func(u string) string {
+			trimmed := strings.TrimRight(u, ".,;:!?")
+			if broken[trimmed] {
+				return cfg.Replacement + u[len(trimmed):]
+			}
+			return u
+		}), nil
+	})
+}
+
+// check returns the set of broken URLs among urls.
+func (cfg *LinkConfig) check(ctx context.Context, urls []string) map[string]bool {
+	broken := make(map[string]bool)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 8)
+	seen := make(map[string]bool)
+	for _, u := range urls {
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		if !cfg.allowed(u) {
+			broken[u] = true
+			continue
+		}
+		if cfg.NoFetch {
+			continue
+		}
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if !cfg.reachable(ctx, u) {
+				mu.Lock()
+				broken[u] = true
+				mu.Unlock()
+			}
+		}(u)
+	}
+	wg.Wait()
+	return broken
+}
+
+func (cfg *LinkConfig) allowed(raw string) bool {
+	if len(cfg.AllowedHosts) == 0 {
+		return true
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	for _, h := range cfg.AllowedHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// reachable sends a HEAD request, retrying with GET for servers that do not
+// support HEAD.
+func (cfg *LinkConfig) reachable(ctx context.Context, u string) bool {
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req, err := http.NewRequestWithContext(ctx, method, u, nil)
+		if err != nil {
+			return false
+		}
+		resp, err := cfg.Client.Do(req)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+			continue
+		}
+		return resp.StatusCode < 400
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sync"
+)
+
+// Prediction is a tool call expected to be requested by the model.
+type Prediction struct {
+	Tool      string
+	Arguments map[string]interface{}
+}
+
+// Predictor guesses tool calls from the task input so they can run while
+// the first model call is in flight.
+type Predictor func(task *Task) []Prediction
+
+// IdempotentTool marks tools that are safe to run speculatively. Only tools
+// reporting Idempotent() == true are prefetched.
+type IdempotentTool interface {
+	Tool
+	Idempotent() bool
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"')\]]+`)
+
+// PredictURLFetch predicts a call to tool with {argName: url} for every URL
+// in the task input, e.g. PredictURLFetch("fetch", "url").
+func PredictURLFetch(tool, argName string) Predictor {
+	return func(task *Task) []Prediction {
+		var predictions []Prediction
+		for _, url := range urlPattern.FindAllString(task.Input, -1) {
+			predictions = append(predictions, Prediction{Tool: tool, Arguments: map[string]interface{}{argName: url}})
+		}
+		return predictions
+	}
+}
+
+// prefetchEntry is a speculative tool call, running or finished.
+type prefetchEntry struct {
+	done   chan struct{}
+	result interface{}
+	err    error
+}
+
+// prefetcher holds the speculative calls for one execution.
+type prefetcher struct {
+	mu      sync.Mutex
+	entries map[string]*prefetchEntry
+}
+
+// startPrefetch runs every predicted call for an idempotent tool in the
+// background. Calls are abandoned when ctx is cancelled.
+func startPrefetch(ctx context.Context, predictors []Predictor, task *Task, tools []Tool) *prefetcher {
+	if len(predictors) == 0 {
+		return nil
+	}
+	p := &prefetcher{entries: map[string]*prefetchEntry{}}
+	for _, predict := range predictors {
+		for _, pred := range predict(task) {
+			tool, ok := findTool(tools, pred.Tool).(IdempotentTool)
+			if !ok || !tool.Idempotent() {
+				continue
+			}
+			key := toolCallKey(pred.Tool, pred.Arguments)
+			if _, exists := p.entries[key]; exists {
+				continue
+			}
+			entry := &prefetchEntry{done: make(chan struct{})}
+			p.entries[key] = entry
+			go func(args map[string]interface{}) {
+				defer close(entry.done)
+				defer recoverPanic(&entry.err)
+				entry.result, entry.err = tool.Execute(ctx, args)
+			}(pred.Arguments)
+		}
+	}
+	return p
+}
+
+// take returns the prefetched result for a call, waiting for it to finish.
+// Each prefetched result is used at most once.
+func (p *prefetcher) take(ctx context.Context, name string, args map[string]interface{}) (interface{}, bool, error) {
+	if p == nil {
+		return nil, false, nil
+	}
+	key := toolCallKey(name, args)
+	p.mu.Lock()
+	entry, ok := p.entries[key]
+	delete(p.entries, key)
+	p.mu.Unlock()
+	if !ok {
+		return nil, false, nil
+	}
+	select {
+	case <-entry.done:
+		return entry.result, true, entry.err
+	case <-ctx.Done():
+		return nil, true, ctx.Err()
+	}
+}
+
+// toolCallKey identifies a call by tool name and JSON-encoded arguments,
+// which sorts keys and unifies numeric types.
+func toolCallKey(name string, args map[string]interface{}) string {
+	data, _ := json.Marshal(args)
+	return name + "\x00" + string(data)
+}
+
+// executeTool runs a tool call, using a prefetched result when available.
+func (a *LLMAgent) executeTool(ctx context.Context, prefetched *prefetcher, result *Result, tool Tool, tc *ToolCall) (interface{}, error) {
+	start := Now(ctx)
+	defer func() { tc.Duration = Since(ctx, start) }()
+	if res, ok, err := prefetched.take(ctx, tool.Name(), tc.Arguments); ok {
+		hits, _ := result.Metadata["prefetch_hits"].(int)
+		result.Metadata["prefetch_hits"] = hits + 1
+		return res, err
+	}
+	if ct, ok := tool.(CancellableTool); ok {
+		return runCancellable(ctx, result, ct, tc)
+	}
+	return tool.Execute(ctx, tc.Arguments)
+}
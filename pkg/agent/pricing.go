@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"strings"
+	"sync"
+)
+
+// ModelPrice is a model's list price in USD per million tokens.
+type ModelPrice struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// Cost returns the price of usage in USD.
+func (p ModelPrice) Cost(usage TokenUsage) float64 {
+	return (float64(usage.PromptTokens)*p.Prompt + float64(usage.CompletionTokens)*p.Completion) / 1e6
+}
+
+// Pricing maps model names to prices. Like ContextWindows, names match
+// exactly or by their longest registered prefix.
+type Pricing struct {
+	mu     sync.RWMutex
+	prices map[string]ModelPrice
+}
+
+// NewPricing creates a price list holding prices.
+func NewPricing(prices map[string]ModelPrice) *Pricing {
+	p := &Pricing{prices: make(map[string]ModelPrice, len(prices))}
+	for model, price := range prices {
+		p.prices[model] = price
+	}
+	return p
+}
+
+// DefaultPricing holds list prices of common hosted models. Prices change;
+// override entries with Set to match your contract.
+var DefaultPricing = NewPricing(map[string]ModelPrice{
+	"gpt-3.5-turbo":     {Prompt: 0.50, Completion: 1.50},
+	"gpt-4o":            {Prompt: 2.50, Completion: 10},
+	"gpt-4o-mini":       {Prompt: 0.15, Completion: 0.60},
+	"gpt-4.1":           {Prompt: 2, Completion: 8},
+	"gpt-4.1-mini":      {Prompt: 0.40, Completion: 1.60},
+	"gpt-4.1-nano":      {Prompt: 0.10, Completion: 0.40},
+	"o3":                {Prompt: 2, Completion: 8},
+	"o4-mini":           {Prompt: 1.10, Completion: 4.40},
+	"claude-3-5-haiku":  {Prompt: 0.80, Completion: 4},
+	"claude-3-5-sonnet": {Prompt: 3, Completion: 15},
+	"claude-sonnet-4":   {Prompt: 3, Completion: 15},
+	"claude-opus-4":     {Prompt: 15, Completion: 75},
+	"gemini-1.5-flash":  {Prompt: 0.075, Completion: 0.30},
+	"gemini-1.5-pro":    {Prompt: 1.25, Completion: 5},
+	"gemini-2.0-flash":  {Prompt: 0.10, Completion: 0.40},
+	"deepseek-chat":     {Prompt: 0.27, Completion: 1.10},
+})
+
+// Set registers the price of model.
+func (p *Pricing) Set(model string, price ModelPrice) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prices[model] = price
+}
+
+// Price returns the price of model.
+func (p *Pricing) Price(model string) (ModelPrice, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return longestPrefix(p.prices, model)
+}
+
+// Cost returns the price in USD of the token usage of every step of result
+// served by a priced model.
+func (p *Pricing) Cost(result *Result) float64 {
+	total := 0.0
+	for _, step := range result.Steps {
+		if step.TokenUsage == nil {
+			continue
+		}
+		if price, ok := p.Price(step.Model); ok {
+			total += price.Cost(*step.TokenUsage)
+		}
+	}
+	return total
+}
+
+// longestPrefix looks key up exactly, else by the longest key of m that is
+// a prefix of it.
+func longestPrefix[V any](m map[string]V, key string) (V, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	var best string
+	var value V
+	for name, v := range m {
+		if len(name) > len(best) && strings.HasPrefix(key, name) {
+			best, value = name, v
+		}
+	}
+	return value, best != ""
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// StateProgress is the state key holding the latest Progress of a task.
+const StateProgress = "progress"
+
+// Progress reports how far a long task has got, e.g. "step 3/7: generating
+// report". Tools and agents set it with SetProgress or ReportStep.
+type Progress struct {
+	Percent   float64   `json:"percent"`         // 0-100
+	Phase     string    `json:"phase,omitempty"` // Short machine-friendly phase name
+	Message   string    `json:"message,omitempty"`
+	Step      int       `json:"step,omitempty"`
+	Steps     int       `json:"steps,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (p Progress) String() string {
+	s := fmt.Sprintf("%.0f%%", p.Percent)
+	if p.Steps > 0 {
+		s = fmt.Sprintf("step %d/%d", p.Step, p.Steps)
+	}
+	if p.Phase != "" {
+		s += " " + p.Phase
+	}
+	if p.Message != "" {
+		s += ": " + p.Message
+	}
+	return s
+}
+
+// progressReporter receives progress for the task executing under a context.
+type progressReporter struct {
+	task   *Task
+	latest atomic.Pointer[Progress]
+	onSet  func(*Progress)
+}
+
+type progressKey struct{}
+
+// withProgress installs a reporter for task unless ctx already has one, so
+// nested agents report on the outermost task.
+func withProgress(ctx context.Context, task *Task, onSet func(*Progress)) context.Context {
+	if _, ok := ctx.Value(progressKey{}).(*progressReporter); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, progressKey{}, &progressReporter{task: task, onSet: onSet})
+}
+
+// SetProgress reports progress for the task running under ctx. It is safe
+// to call from tools, including concurrently. The update is emitted as an
+// EventProgress, visible through Executor.Progress, and written to
+// state[StateProgress] by the LLMAgent after the current tool calls finish.
+// A Percent of 0 with Steps set is derived from Step/Steps.
+func SetProgress(ctx context.Context, p Progress) {
+	r, ok := ctx.Value(progressKey{}).(*progressReporter)
+	if !ok {
+		return
+	}
+	if p.Percent == 0 && p.Steps > 0 {
+		p.Percent = 100 * float64(p.Step) / float64(p.Steps)
+	}
+	if p.UpdatedAt.IsZero() {
+		p.UpdatedAt = Now(ctx)
+	}
+	r.latest.Store(&p)
+	if r.onSet != nil {
+		r.onSet(&p)
+	}
+	path := AgentPathFromContext(ctx)
+	ev := newEvent(ctx, r.task.ID, path[strings.LastIndex(path, "/")+1:], EventProgress)
+	ev.AgentPath = path
+	ev.Progress = &p
+	emit(r.task, ev)
+}
+
+// ReportStep is SetProgress for step-counted work.
+func ReportStep(ctx context.Context, step, steps int, message string) {
+	SetProgress(ctx, Progress{Step: step, Steps: steps, Message: message})
+}
+
+// progressSince returns the latest progress reported under ctx if it is
+// newer than seen.
+func progressSince(ctx context.Context, seen *Progress) *Progress {
+	r, ok := ctx.Value(progressKey{}).(*progressReporter)
+	if !ok {
+		return nil
+	}
+	if p := r.latest.Load(); p != seen {
+		return p
+	}
+	return nil
+}
+
+// Progress returns the latest progress reported by a job, or nil if it has
+// not reported any.
+func (e *Executor) Progress(taskID string) (*Progress, error) {
+	job, ok := e.jobs.get(taskID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, taskID)
+	}
+	return job.progress.Load(), nil
+}
@@ -0,0 +1,302 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ToolFallback controls when an LLMAgent describes its tools in the prompt
+// instead of sending them to the provider for native tool calling.
+type ToolFallback int
+
+const (
+	ToolFallbackOff    ToolFallback = iota // Always use native tool calling
+	ToolFallbackAuto                       // Prompted tools when the provider reports no native support (see ToolSupport)
+	ToolFallbackAlways                     // Prompted tools on every request
+)
+
+// ToolSupport is implemented by providers that know whether a model can call
+// tools natively. model is CompletionRequest.Model; empty means the
+// provider's default model.
+type ToolSupport interface {
+	SupportsTools(model string) bool
+}
+
+// PromptedToolModel gives tool calling to models without native support.
+// It removes the tools from each request, describes them in the system
+// prompt with instructions to answer with a fenced JSON block, and parses
+// such blocks from the response into ToolCalls. Earlier tool calls in the
+// history are rewritten in the same format, so the model sees its own
+// protocol.
+type PromptedToolModel struct {
+	model ModelProvider
+}
+
+// NewPromptedToolModel wraps model with the prompted JSON tool protocol.
+func NewPromptedToolModel(model ModelProvider) *PromptedToolModel {
+	return &PromptedToolModel{model: model}
+}
+
+// Unwrap returns the wrapped model.
+func (m *PromptedToolModel) Unwrap() ModelProvider {
+	return m.model
+}
+
+func (m *PromptedToolModel) Complete(ctx context.Context, req *CompletionRequest) (*ModelResponse, error) {
+	return m.complete(ctx, req, nil)
+}
+
+// CompleteStream streams through to the wrapped model when it supports
+// streaming. Deltas stop at the start of a fenced block or a bare JSON
+// answer, so tool call JSON is not streamed as content; if the response
+// turns out to hold no tool calls, the held-back content follows.
+func (m *PromptedToolModel) CompleteStream(ctx context.Context, req *CompletionRequest, onDelta func(delta string)) (*ModelResponse, error) {
+	if len(req.Tools) == 0 {
+		return completeStream(ctx, m.model, req, onDelta)
+	}
+	stream := &promptedStream{onDelta: onDelta}
+	resp, err := m.complete(ctx, req, stream.write)
+	if err == nil && len(resp.ToolCalls) == 0 {
+		stream.flush()
+	}
+	return resp, err
+}
+
+func (m *PromptedToolModel) complete(ctx context.Context, req *CompletionRequest, onDelta func(string)) (*ModelResponse, error) {
+	if len(req.Tools) == 0 {
+		return m.model.Complete(ctx, req)
+	}
+	prompted := *req
+	prompted.Tools = nil
+	prompted.History = promptedHistory(req.History, describeTools(req.Tools))
+
+	resp, err := completeStream(ctx, m.model, &prompted, onDelta)
+	if err != nil || len(resp.ToolCalls) > 0 {
+		return resp, err
+	}
+	if calls, rest, ok := parsePromptedToolCalls(resp.Content); ok {
+		parsed := *resp
+		parsed.ToolCalls = calls
+		parsed.Content = rest
+		return &parsed, nil
+	}
+	return resp, nil
+}
+
+// promptedStream forwards the deltas of a prompted response up to where a
+// tool call block may start.
+type promptedStream struct {
+	onDelta func(string)
+	text    strings.Builder
+	sent    int  // Bytes of text forwarded
+	held    bool // A possible tool call started; the rest is held back
+}
+
+func (s *promptedStream) write(delta string) {
+	s.text.WriteString(delta)
+	if s.held {
+		return
+	}
+	text := s.text.String()
+	if i := strings.Index(text, "```"); i >= 0 {
+		s.held = true
+		s.forward(text, i)
+		return
+	}
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return
+	}
+	if s.sent == 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		s.held = true
+		return
+	}
+	// Trailing backticks may be the start of a fence
+	s.forward(text, len(strings.TrimRight(text, "`")))
+}
+
+// forward sends text[sent:end], skipping whitespace-only pieces before a
+// tool call.
+func (s *promptedStream) forward(text string, end int) {
+	if end <= s.sent {
+		return
+	}
+	if piece := text[s.sent:end]; !s.held || strings.TrimSpace(piece) != "" {
+		s.onDelta(piece)
+	}
+	s.sent = end
+}
+
+// flush sends the content held back for a response without tool calls.
+func (s *promptedStream) flush() {
+	if text := s.text.String(); len(text) > s.sent {
+		s.onDelta(text[s.sent:])
+		s.sent = len(text)
+	}
+}
+
+// usePromptedTools reports whether req should go through the prompted tool
+// protocol instead of model's native tool calling.
+func (f ToolFallback) usePromptedTools(model ModelProvider, req *CompletionRequest) bool {
+	switch {
+	case len(req.Tools) == 0:
+		return false
+	case f == ToolFallbackAlways:
+		return true
+	case f == ToolFallbackAuto:
+		ts, ok := model.(ToolSupport)
+		return ok && !ts.SupportsTools(req.Model)
+	}
+	return false
+}
+
+const promptedToolInstructions = `## Tools
+
+You can call the tools below. To call tools, reply with only a fenced JSON block and nothing else:
+
+` + "```json" + `
+{"tool_calls": [{"name": "<tool name>", "arguments": {<arguments matching the tool's parameters>}}]}
+` + "```" + `
+
+You may list several calls. Their results are sent to you in the next message. When you have the final answer, reply normally, without a tool_calls block.
+
+Available tools:
+`
+
+// describeTools renders the tool instructions and catalog for the system
+// prompt.
+func describeTools(tools []Tool) string {
+	var b strings.Builder
+	b.WriteString(promptedToolInstructions)
+	for _, t := range tools {
+		fmt.Fprintf(&b, "\n- %s: %s", t.Name(), t.Description())
+		if schema := t.Schema(); schema != nil {
+			if data, err := json.Marshal(schema); err == nil {
+				fmt.Fprintf(&b, "\n  Parameters: %s", data)
+			}
+		}
+	}
+	return b.String()
+}
+
+// promptedHistory adds the tool instructions to the system message and
+// rewrites tool traffic for a model without tool support: assistant tool
+// calls become fenced JSON blocks and tool results user messages (see
+// ToolResultsAsUser).
+func promptedHistory(history []Message, instructions string) []Message {
+	out := make([]Message, 0, len(history)+1)
+	if len(history) == 0 || history[0].Role != RoleSystem {
+		out = append(out, Message{Role: RoleSystem, Content: instructions})
+	}
+	for i, m := range ToolResultsAsUser(history) {
+		switch {
+		case i == 0 && m.Role == RoleSystem:
+			if m.Content != "" {
+				m.Content += "\n\n"
+			}
+			m.Content += instructions
+		case m.Role == RoleAssistant && len(m.ToolCalls) > 0:
+			m.Content = fencedToolCalls(m.ToolCalls)
+			m.ToolCalls = nil
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+type promptedCall struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+func fencedToolCalls(calls []ToolCall) string {
+	list := make([]promptedCall, len(calls))
+	for i, tc := range calls {
+		list[i] = promptedCall{Name: tc.Name, Arguments: tc.Arguments}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"tool_calls": list})
+	return "```json\n" + string(data) + "\n```"
+}
+
+var fencedBlock = regexp.MustCompile("(?s)```[a-zA-Z_]*\\s*\\n?(.*?)```")
+
+// parsePromptedToolCalls extracts the tool calls of a response that follows
+// the prompted protocol: fenced JSON blocks, or a bare JSON answer, holding
+// {"tool_calls": [...]}, a list of calls, or a single {"name", "arguments"}
+// call. rest is the content outside the blocks.
+func parsePromptedToolCalls(content string) (calls []ToolCall, rest string, ok bool) {
+	blocks := fencedBlock.FindAllStringSubmatchIndex(content, -1)
+	if len(blocks) == 0 {
+		if calls := decodePromptedCalls(content); len(calls) > 0 {
+			return calls, "", true
+		}
+		return nil, content, false
+	}
+
+	var kept strings.Builder
+	prev := 0
+	for _, loc := range blocks {
+		found := decodePromptedCalls(content[loc[2]:loc[3]])
+		if len(found) == 0 {
+			continue
+		}
+		calls = append(calls, found...)
+		kept.WriteString(content[prev:loc[0]])
+		prev = loc[1]
+	}
+	if len(calls) == 0 {
+		return nil, content, false
+	}
+	kept.WriteString(content[prev:])
+	return calls, strings.TrimSpace(kept.String()), true
+}
+
+func decodePromptedCalls(text string) []ToolCall {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "{") && !strings.HasPrefix(text, "[") {
+		return nil
+	}
+	var raw interface{}
+	if err := json.Unmarshal([]byte(text), &raw); err != nil {
+		repaired, rerr := DefaultJSONRepairer.Repair(text)
+		if rerr != nil || json.Unmarshal([]byte(repaired), &raw) != nil {
+			return nil
+		}
+	}
+
+	var items []interface{}
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		if list, ok := v["tool_calls"].([]interface{}); ok {
+			items = list
+		} else {
+			items = []interface{}{v}
+		}
+	case []interface{}:
+		items = v
+	}
+
+	var calls []ToolCall
+	for _, item := range items {
+		obj, _ := item.(map[string]interface{})
+		name, _ := obj["name"].(string)
+		if name == "" {
+			name, _ = obj["tool"].(string)
+		}
+		if name == "" {
+			return nil
+		}
+		args, _ := obj["arguments"].(map[string]interface{})
+		if encoded, ok := obj["arguments"].(string); ok {
+			json.Unmarshal([]byte(encoded), &args)
+		}
+		if args == nil {
+			args = map[string]interface{}{}
+		}
+		calls = append(calls, ToolCall{Name: name, Arguments: args})
+	}
+	return calls
+}
@@ -0,0 +1,236 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// RedactField names a class of potentially sensitive content.
+type RedactField string
+
+const (
+	RedactPrompt      RedactField = "prompt"       // Messages sent to the model
+	RedactInput       RedactField = "input"        // Task and step input
+	RedactToolArgs    RedactField = "tool_args"    // Tool call arguments
+	RedactToolResults RedactField = "tool_results" // Tool call results
+	RedactOutput      RedactField = "output"       // Step, event, and final output, and named outputs
+	RedactReasoning   RedactField = "reasoning"    // Model reasoning
+	RedactState       RedactField = "state"        // State deltas and snapshots
+)
+
+// RedactMethod selects how a redacted field is rewritten.
+type RedactMethod int
+
+const (
+	RedactKeep     RedactMethod = iota // Leave the value unchanged
+	RedactDrop                         // Remove the value
+	RedactHash                         // Replace with "sha256:<hex>" so equal values stay correlatable
+	RedactTruncate                     // Keep the first TruncateTo characters
+)
+
+// RedactionPolicy rewrites sensitive fields before execution data leaves
+// the process through logs, traces, or webhook payloads. Policies never
+// modify their input; they return redacted copies. A nil policy keeps
+// everything.
+type RedactionPolicy struct {
+	Fields     map[RedactField]RedactMethod
+	TruncateTo int // Characters kept by RedactTruncate (default 64)
+}
+
+// Value applies the policy for field to a single value.
+func (p *RedactionPolicy) Value(field RedactField, v interface{}) interface{} {
+	if p == nil || v == nil {
+		return v
+	}
+	switch p.Fields[field] {
+	case RedactDrop:
+		return nil
+	case RedactHash:
+		return hashValue(v)
+	case RedactTruncate:
+		limit := p.TruncateTo
+		if limit <= 0 {
+			limit = 64
+		}
+		text, ok := v.(string)
+		if !ok {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil
+			}
+			text = string(data)
+		}
+		if r := []rune(text); len(r) > limit {
+			return string(r[:limit]) + "..."
+		}
+		return text
+	default:
+		return v
+	}
+}
+
+// String applies the policy for field to a string value.
+func (p *RedactionPolicy) String(field RedactField, s string) string {
+	if s == "" {
+		return s
+	}
+	out, _ := p.Value(field, s).(string)
+	return out
+}
+
+func (p *RedactionPolicy) active(field RedactField) bool {
+	return p != nil && p.Fields[field] != RedactKeep
+}
+
+func hashValue(v interface{}) string {
+	data, ok := v.(string)
+	if !ok {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "sha256:"
+		}
+		data = string(b)
+	}
+	sum := sha256.Sum256([]byte(data))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Messages returns redacted copies of prompt messages.
+func (p *RedactionPolicy) Messages(msgs []Message) []Message {
+	if !p.active(RedactPrompt) {
+		return msgs
+	}
+	out := make([]Message, len(msgs))
+	for i, m := range msgs {
+		out[i] = Message{Role: m.Role, Content: p.String(RedactPrompt, m.Content), ToolCallID: m.ToolCallID}
+		for _, part := range m.Parts {
+			out[i].Parts = append(out[i].Parts, Part{
+				Type: part.Type,
+				Text: p.String(RedactPrompt, part.Text),
+				Data: p.Value(RedactPrompt, part.Data),
+			})
+		}
+	}
+	return out
+}
+
+// ToolCalls returns redacted copies of tool calls.
+func (p *RedactionPolicy) ToolCalls(calls []ToolCall) []ToolCall {
+	if !p.active(RedactToolArgs) && !p.active(RedactToolResults) {
+		return calls
+	}
+	out := make([]ToolCall, len(calls))
+	for i, tc := range calls {
+		out[i] = tc
+		if args, ok := p.Value(RedactToolArgs, tc.Arguments).(map[string]interface{}); ok {
+			out[i].Arguments = args
+		} else {
+			out[i].Arguments = redactedArgs(p.Value(RedactToolArgs, tc.Arguments))
+		}
+		out[i].Result = p.Value(RedactToolResults, tc.Result)
+	}
+	return out
+}
+
+// redactedArgs wraps a hashed or truncated argument map so it still fits
+// the map-typed Arguments field.
+func redactedArgs(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	return map[string]interface{}{"redacted": v}
+}
+
+// valueMap redacts each value of m as field, dropping removed values.
+func (p *RedactionPolicy) valueMap(field RedactField, m map[string]interface{}) map[string]interface{} {
+	if !p.active(field) || m == nil {
+		return m
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if rv := p.Value(field, v); rv != nil {
+			out[k] = rv
+		}
+	}
+	return out
+}
+
+// Step returns a redacted copy of an execution step.
+func (p *RedactionPolicy) Step(step ExecutionStep) ExecutionStep {
+	if p == nil {
+		return step
+	}
+	step.Input = p.Value(RedactInput, step.Input)
+	step.Output = p.Value(RedactOutput, step.Output)
+	step.Reasoning = p.String(RedactReasoning, step.Reasoning)
+	step.ToolCalls = p.ToolCalls(step.ToolCalls)
+	step.StateDelta = p.valueMap(RedactState, step.StateDelta)
+	return step
+}
+
+// Event returns a redacted copy of an event.
+func (p *RedactionPolicy) Event(ev *Event) *Event {
+	if p == nil || ev == nil {
+		return ev
+	}
+	out := *ev
+	out.Input = p.Value(RedactInput, ev.Input)
+	out.Output = p.Value(RedactOutput, ev.Output)
+	out.Content = p.String(RedactOutput, ev.Content)
+	out.Reasoning = p.String(RedactReasoning, ev.Reasoning)
+	out.ToolCalls = p.ToolCalls(ev.ToolCalls)
+	if ev.Actions != nil && len(ev.Actions.StateDelta) > 0 {
+		actions := *ev.Actions
+		actions.StateDelta = p.valueMap(RedactState, ev.Actions.StateDelta)
+		out.Actions = &actions
+	}
+	return &out
+}
+
+// Result returns a redacted copy of a result (steps, output, and state).
+func (p *RedactionPolicy) Result(r *Result) *Result {
+	if p == nil || r == nil {
+		return r
+	}
+	out := *r
+	out.Output = p.Value(RedactOutput, r.Output)
+	out.Outputs = p.valueMap(RedactOutput, r.Outputs)
+	out.State = p.valueMap(RedactState, r.State)
+	out.Steps = make([]ExecutionStep, len(r.Steps))
+	for i, step := range r.Steps {
+		out.Steps[i] = p.Step(step)
+	}
+	return &out
+}
+
+// Job returns a copy of a finished job whose task input, params, and result
+// are redacted, for handing to sinks.
+func (p *RedactionPolicy) Job(job *Job) *Job {
+	if p == nil || job == nil {
+		return job
+	}
+	task := *job.Task
+	task.Input = p.String(RedactInput, task.Input)
+	task.Params = p.valueMap(RedactState, task.Params)
+	task.State = p.valueMap(RedactState, task.State)
+	r := &Job{Task: &task, Result: p.Result(job.Result), Error: job.Error}
+	r.status.Store(job.Status())
+	return r
+}
+
+// Handler wraps next so it only sees redacted events.
+func (p *RedactionPolicy) Handler(next EventHandler) EventHandler {
+	if p == nil {
+		return next
+	}
+	return func(ev *Event) { next(p.Event(ev)) }
+}
+
+// RedactedSink wraps a ResultSink so it receives redacted jobs.
+func RedactedSink(p *RedactionPolicy, sink ResultSink) ResultSink {
+	return ResultSinkFunc(func(ctx context.Context, job *Job) error {
+		return sink.Deliver(ctx, p.Job(job))
+	})
+}
@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+)
+
+// Compensator is implemented by agents that can undo the external effects of
+// a completed execution. SequentialAgent and PipelineAgent call Compensate on
+// completed stages, in reverse order, when a later stage fails.
+type Compensator interface {
+	Compensate(ctx context.Context, task *Task, result *Result) error
+}
+
+// CompensationFunc undoes the effects of a stage given its result.
+type CompensationFunc func(ctx context.Context, task *Task, result *Result) error
+
+// compensatedAgent decorates an agent with a compensation action.
+type compensatedAgent struct {
+	Agent
+	compensate CompensationFunc
+}
+
+// WithCompensation wraps ag so fn runs if a later workflow stage fails.
+func WithCompensation(ag Agent, fn CompensationFunc) Agent {
+	return &compensatedAgent{Agent: ag, compensate: fn}
+}
+
+// WithCompensatingAgent wraps ag so comp runs if a later workflow stage
+// fails. The compensating agent receives the stage's output as its input.
+func WithCompensatingAgent(ag, comp Agent) Agent {
+	return WithCompensation(ag, func(ctx context.Context, task *Task, result *Result) error {
+		compTask := *task
+		compTask.Input = outputString(result.Output)
+		_, err := comp.Execute(ctx, &compTask)
+		return err
+	})
+}
+
+func (a *compensatedAgent) Unwrap() Agent {
+	return a.Agent
+}
+
+func (a *compensatedAgent) Compensate(ctx context.Context, task *Task, result *Result) error {
+	return a.compensate(ctx, task, result)
+}
+
+// completedStage records a stage that finished successfully, for compensation.
+type completedStage struct {
+	agent  Agent
+	result *Result
+}
+
+// compensate runs compensations for completed stages in reverse order. It
+// runs even if ctx was cancelled, since cleanup matters most after timeouts.
+// Compensation errors are collected in result metadata.
+func compensate(ctx context.Context, task *Task, result *Result, completed []completedStage) {
+	ctx = context.WithoutCancel(ctx)
+	var errs []string
+
+	for i := len(completed) - 1; i >= 0; i-- {
+		c, ok := findCompensator(completed[i].agent)
+		if !ok {
+			continue
+		}
+
+		start := Now(ctx)
+		err := c.Compensate(ctx, task, completed[i].result)
+		step := ExecutionStep{
+			AgentName: completed[i].agent.Name(),
+			Action:    "compensate",
+			Duration:  Since(ctx, start),
+			Timestamp: start,
+		}
+		if err != nil {
+			step.Error = err.Error()
+			errs = append(errs, completed[i].agent.Name()+": "+err.Error())
+		}
+		recordStep(ctx, task, result, step)
+	}
+
+	if len(errs) > 0 {
+		result.Metadata["compensation_errors"] = errs
+	}
+}
+
+func findCompensator(ag Agent) (Compensator, bool) {
+	found := unwrapTo(ag, func(a Agent) bool { _, ok := a.(Compensator); return ok })
+	if found == nil {
+		return nil, false
+	}
+	return found.(Compensator), true
+}
@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+)
+
+// SamplingPolicy decides which executions an event or result exporter
+// keeps, so trace storage does not grow linearly with traffic. Failed
+// executions are always kept; a fraction of successful ones is kept in full
+// and the rest are reduced to their first and last turn, or dropped.
+//
+// The decision is a hash of the task ID, so every exporter using the same
+// rate keeps the same executions.
+type SamplingPolicy struct {
+	SuccessRate   float64 // Fraction of successful executions kept in full (0 keeps none, 1 keeps all)
+	KeepFirstLast bool    // Keep the first and last step of successful executions that are not sampled
+
+	// MaxBuffered caps the events held per execution while waiting for its
+	// outcome (default 1000). When exceeded, the oldest events after the
+	// first step are discarded.
+	MaxBuffered int
+
+	mu      sync.Mutex
+	pending map[string]*sampledTask
+}
+
+// sampledTask holds the events of an execution that is not sampled until
+// its outcome is known.
+type sampledTask struct {
+	events    []*Event
+	firstStep int // Index in events, -1 until seen
+}
+
+// Sampled reports whether a successful execution of taskID is kept in full.
+func (p *SamplingPolicy) Sampled(taskID string) bool {
+	switch {
+	case p.SuccessRate >= 1:
+		return true
+	case p.SuccessRate <= 0:
+		return false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(taskID))
+	return float64(h.Sum64()%1_000_000)/1_000_000 < p.SuccessRate
+}
+
+// Handler wraps next so it only receives the events the policy keeps.
+// Events of sampled executions pass through at once; the others are held
+// until the execution's outcome is known from its final EventJob (Executor
+// jobs; see WithEvents) or from Flush, then passed on all together if it
+// failed. Streaming deltas of executions that are not sampled are dropped.
+func (p *SamplingPolicy) Handler(next EventHandler) EventHandler {
+	if p == nil {
+		return next
+	}
+	return func(ev *Event) {
+		if p.Sampled(ev.TaskID) {
+			next(ev)
+			return
+		}
+		if ev.Partial {
+			return
+		}
+		if ev.Type == EventJob && ev.Finished {
+			for _, kept := range p.take(ev.TaskID, ev.Error != "") {
+				next(kept)
+			}
+			next(ev)
+			return
+		}
+		p.hold(ev)
+	}
+}
+
+// Flush passes on the events held for taskID according to its outcome,
+// for executions that do not end with an EventJob (ExecuteSync or direct
+// Execute calls).
+func (p *SamplingPolicy) Flush(taskID string, failed bool, next EventHandler) {
+	for _, ev := range p.take(taskID, failed) {
+		next(ev)
+	}
+}
+
+func (p *SamplingPolicy) hold(ev *Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending == nil {
+		p.pending = make(map[string]*sampledTask)
+	}
+	t := p.pending[ev.TaskID]
+	if t == nil {
+		t = &sampledTask{firstStep: -1}
+		p.pending[ev.TaskID] = t
+	}
+	if ev.Type == EventStep && t.firstStep < 0 {
+		t.firstStep = len(t.events)
+	}
+	t.events = append(t.events, ev)
+
+	maxBuffered := p.MaxBuffered
+	if maxBuffered <= 0 {
+		maxBuffered = 1000
+	}
+	if len(t.events) > maxBuffered {
+		// Drop the oldest event that is not the first step
+		drop := 0
+		if t.firstStep == 0 {
+			drop = 1
+		}
+		t.events = append(t.events[:drop], t.events[drop+1:]...)
+		if t.firstStep > drop {
+			t.firstStep--
+		}
+	}
+}
+
+// take removes the events held for taskID and returns the ones to keep.
+func (p *SamplingPolicy) take(taskID string, failed bool) []*Event {
+	p.mu.Lock()
+	t := p.pending[taskID]
+	delete(p.pending, taskID)
+	p.mu.Unlock()
+	if t == nil {
+		return nil
+	}
+	if failed {
+		return t.events
+	}
+	if !p.KeepFirstLast || t.firstStep < 0 {
+		return nil
+	}
+	kept := []*Event{t.events[t.firstStep]}
+	for i := len(t.events) - 1; i > t.firstStep; i-- {
+		if t.events[i].Type == EventStep {
+			kept = append(kept, t.events[i])
+			break
+		}
+	}
+	return kept
+}
+
+// SampledSink wraps a ResultSink so it receives every failed job and the
+// successful jobs the policy samples.
+func SampledSink(p *SamplingPolicy, sink ResultSink) ResultSink {
+	return ResultSinkFunc(func(ctx context.Context, job *Job) error {
+		if job.Error != nil || p.Sampled(job.Task.ID) {
+			return sink.Deliver(ctx, job)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,248 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sultanfariz/gonostic/pkg/schema"
+)
+
+// SchemaError describes a value that does not conform to a JSON schema.
+type SchemaError struct {
+	Path    string // JSON path of the offending value, e.g. "$.items[2].name"
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidateSchema checks value against a JSON schema. The supported keywords
+// are type, properties, required, additionalProperties (boolean form), items,
+// enum, minimum, maximum, minLength, and maxLength. Go structs and typed maps
+// are normalized through encoding/json before validation, and JSON-encoded
+// strings are decoded when the schema expects an object or array.
+func ValidateSchema(schema map[string]interface{}, value interface{}) error {
+	if schema == nil {
+		return nil
+	}
+	return validateNode(schema, normalizeValue(schema, value), "$")
+}
+
+// normalizeValue converts value into the generic form produced by
+// encoding/json so validation only has to handle one representation.
+func normalizeValue(schema map[string]interface{}, value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		if t := schemaTypes(schema); containsAny(t, "object", "array") && !containsAny(t, "string") {
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(s), &decoded); err == nil {
+				return decoded
+			}
+		}
+		return s
+	}
+	switch value.(type) {
+	case nil, bool, float64, map[string]interface{}, []interface{}:
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
+func validateNode(schema map[string]interface{}, value interface{}, path string) error {
+	if types := schemaTypes(schema); len(types) > 0 {
+		actual := jsonType(value)
+		ok := false
+		for _, t := range types {
+			if t == actual || (t == "number" && actual == "integer") {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), actual)}
+		}
+	}
+
+	if enum := interfaceList(schema["enum"]); enum != nil {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(normalizeValue(nil, e), value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("value %v is not one of %v", value, enum)}
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range stringList(schema["required"]) {
+			if _, ok := v[key]; !ok {
+				return &SchemaError{Path: path, Message: fmt.Sprintf("missing required property %q", key)}
+			}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			propSchema, ok := props[k].(map[string]interface{})
+			if !ok {
+				if allowed, isBool := schema["additionalProperties"].(bool); isBool && !allowed {
+					return &SchemaError{Path: path, Message: fmt.Sprintf("unexpected property %q", k)}
+				}
+				continue
+			}
+			if err := validateNode(propSchema, v[k], path+"."+k); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				if err := validateNode(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case float64:
+		if min, ok := toFloat(schema["minimum"]); ok && v < min {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("%v is less than minimum %v", v, min)}
+		}
+		if max, ok := toFloat(schema["maximum"]); ok && v > max {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("%v is greater than maximum %v", v, max)}
+		}
+	case string:
+		if min, ok := toFloat(schema["minLength"]); ok && float64(len([]rune(v))) < min {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("length is less than %v", min)}
+		}
+		if max, ok := toFloat(schema["maxLength"]); ok && float64(len([]rune(v))) > max {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("length exceeds %v", max)}
+		}
+	}
+
+	return nil
+}
+
+func schemaTypes(schema map[string]interface{}) []string {
+	if schema == nil {
+		return nil
+	}
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	default:
+		return stringList(t)
+	}
+}
+
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func stringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// interfaceList converts any slice value into []interface{}.
+func interfaceList(v interface{}) []interface{} {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() != reflect.Slice {
+		return nil
+	}
+	out := make([]interface{}, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
+
+func containsAny(list []string, values ...string) bool {
+	for _, item := range list {
+		for _, v := range values {
+			if item == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// SchemaOf derives a JSON schema from a Go value's type. Struct fields are
+// named by their json tag; fields without omitempty are required.
+func SchemaOf(v interface{}) map[string]interface{} {
+	return schema.FromType(reflect.TypeOf(v))
+}
+
+// schemaMap returns a tool schema as a JSON schema map, accepting both plain
+// maps and builders from the schema package.
+func schemaMap(v interface{}) (map[string]interface{}, bool) {
+	switch s := v.(type) {
+	case map[string]interface{}:
+		return s, true
+	case interface{ Build() map[string]interface{} }:
+		return s.Build(), true
+	}
+	return nil, false
+}
@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// SelfEvaluation configures an optional post-execution step where the model
+// rates its own final answer. The score lands in Result.Metadata["confidence"]
+// so downstream systems can route low-confidence results to humans.
+type SelfEvaluation struct {
+	Rubric string        // Criteria the answer is judged against (optional)
+	Model  ModelProvider // Judge model; defaults to the agent's own model
+}
+
+const selfEvalPrompt = `Rate how well the answer below fulfils the task%s.
+Respond with JSON only: {"confidence": <number from 0 to 1>, "rationale": "<one sentence>"}
+
+Task:
+%s
+
+Answer:
+%v`
+
+var confidencePattern = regexp.MustCompile(`\d*\.?\d+`)
+
+// evaluate runs the self-evaluation step and records its outcome on the
+// result. Failures are recorded in metadata and never fail the execution.
+func (e *SelfEvaluation) evaluate(ctx context.Context, defaultModel ModelProvider, agentName string, task *Task, result *Result) {
+	model := e.Model
+	if model == nil {
+		model = defaultModel
+	}
+	rubric := ""
+	if e.Rubric != "" {
+		rubric = " according to this rubric:\n" + e.Rubric + "\n"
+	}
+
+	start := Now(ctx)
+	step := ExecutionStep{AgentName: agentName, Action: "self_evaluation", Timestamp: start}
+
+	resp, err := model.Complete(ctx, &CompletionRequest{
+		History: []Message{{Role: RoleUser, Content: fmt.Sprintf(selfEvalPrompt, rubric, task.Input, result.Output)}},
+	})
+	step.LLMLatency = Since(ctx, start)
+	step.Duration = step.LLMLatency
+	if err != nil {
+		step.Error = err.Error()
+		recordStep(ctx, task, result, step)
+		result.Metadata["self_evaluation_error"] = err.Error()
+		return
+	}
+	step.TokenUsage = resp.Usage
+	step.Output = resp.Content
+	recordStep(ctx, task, result, step)
+
+	confidence, rationale, err := parseSelfEvaluation(resp.Content)
+	if err != nil {
+		result.Metadata["self_evaluation_error"] = err.Error()
+		return
+	}
+	result.Metadata["confidence"] = confidence
+	if rationale != "" {
+		result.Metadata["self_evaluation"] = rationale
+	}
+}
+
+func parseSelfEvaluation(content string) (float64, string, error) {
+	var parsed struct {
+		Confidence float64 `json:"confidence"`
+		Rationale  string  `json:"rationale"`
+	}
+	if err := json.Unmarshal([]byte(content), &parsed); err == nil {
+		return clamp01(parsed.Confidence), parsed.Rationale, nil
+	}
+
+	// Fall back to the first number in the reply
+	match := confidencePattern.FindString(content)
+	if match == "" {
+		return 0, "", fmt.Errorf("no confidence score in %q", content)
+	}
+	v, err := strconv.ParseFloat(match, 64)
+	if err != nil {
+		return 0, "", err
+	}
+	return clamp01(v), "", nil
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -27,15 +27,19 @@ type Response struct {
 	Artifacts []Artifact
 	Actions   *EventActions
 	Finished  bool
+
+	FinishReason FinishReason // Why the model stopped generating, if known
 }
 
 // EventActions captures state changes and control flow actions.
 type EventActions struct {
-	StateDelta    map[string]interface{}
-	Escalate      bool
-	TransferTo    string
-	ExitLoop      bool
-	SkipRemaining bool
+	StateDelta       map[string]interface{}
+	Escalate         bool
+	EscalationReason string // Why the agent escalated
+	EscalatedBy      string // Agent that raised the escalation
+	TransferTo       string
+	ExitLoop         bool
+	SkipRemaining    bool
 }
 
 // State represents session state with get/set/delete semantics.
@@ -55,6 +59,24 @@ type RunConfig struct {
 	EnablePlan     bool
 	EnableMemory   bool
 	TimeoutSeconds int
+	Model          string // Model name override for this invocation
+}
+
+// ExecutionConfig converts the run settings to the Task path's config, so
+// SessionAgent implementations backed by Task agents apply them the same
+// way: as the call layer in ResolveExecutionConfig. A nil config yields nil.
+func (c *RunConfig) ExecutionConfig() *ExecutionConfig {
+	if c == nil {
+		return nil
+	}
+	return &ExecutionConfig{
+		MaxIterations:  c.MaxIterations,
+		TimeoutSeconds: c.TimeoutSeconds,
+		Temperature:    c.Temperature,
+		EnablePlan:     c.EnablePlan,
+		StreamingMode:  c.StreamingMode,
+		Model:          c.Model,
+	}
 }
 
 // StreamingMode defines how output is streamed back to the caller.
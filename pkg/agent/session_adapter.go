@@ -0,0 +1,291 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// AsSessionAgent adapts a Task agent (LLMAgent, workflow agents, decorators)
+// to the SessionAgent interface. Each Run executes the agent on a task built
+// from the invocation: the input message becomes Task.Input and Files, the
+// invocation state is copied into Task.State, and RunConfig becomes the
+// call layer of the execution config. State changes are written back to
+// the invocation state and reported in Response.Actions.StateDelta.
+func AsSessionAgent(ag Agent) SessionAgent {
+	if a, ok := ag.(*sessionBackedAgent); ok {
+		return a.agent
+	}
+	return &taskBackedSessionAgent{agent: ag}
+}
+
+// AsAgent adapts a SessionAgent to the Agent interface, so it can run in an
+// Executor or as a stage of a workflow agent. Each Execute runs one
+// invocation whose session ID is the task ID, and records it as a "run"
+// step.
+func AsAgent(sa SessionAgent) Agent {
+	if a, ok := sa.(*taskBackedSessionAgent); ok {
+		return a.agent
+	}
+	return &sessionBackedAgent{agent: sa}
+}
+
+type taskBackedSessionAgent struct {
+	agent Agent
+}
+
+func (a *taskBackedSessionAgent) Name() string {
+	return a.agent.Name()
+}
+
+func (a *taskBackedSessionAgent) Agents() []SessionAgent {
+	subs := a.agent.SubAgents()
+	agents := make([]SessionAgent, len(subs))
+	for i, sub := range subs {
+		agents[i] = AsSessionAgent(sub)
+	}
+	return agents
+}
+
+// Unwrap returns the adapted agent.
+func (a *taskBackedSessionAgent) Unwrap() Agent {
+	return a.agent
+}
+
+func (a *taskBackedSessionAgent) Run(ctx context.Context, inv *Invocation) (*Response, error) {
+	before := make(map[string]interface{})
+	if inv.State != nil {
+		for _, k := range inv.State.Keys() {
+			if v, ok := inv.State.Get(k); ok {
+				before[k] = v
+			}
+		}
+	}
+
+	task := &Task{
+		ID:        NewID(ctx),
+		State:     copyMap(before),
+		Config:    inv.Config.ExecutionConfig(),
+		StartedAt: Now(ctx),
+	}
+	if inv.UserID != "" {
+		if task.Config == nil {
+			task.Config = &ExecutionConfig{}
+		}
+		task.Config.UserID = inv.UserID
+	}
+	if inv.SessionID != "" {
+		task.Params = map[string]interface{}{ParamSessionID: inv.SessionID}
+	}
+	if inv.Input != nil {
+		task.Input = inv.Input.Content
+		task.Files = filesFromParts(inv.Input.Parts)
+	}
+
+	result, err := a.agent.Execute(ctx, task)
+	task.CompletedAt = Now(ctx)
+
+	delta, removed := stateChanges(before, task.State)
+	if inv.State != nil {
+		inv.State.Merge(delta)
+		for _, k := range removed {
+			inv.State.Delete(k)
+		}
+	}
+	return responseFromResult(result, delta, err == nil), err
+}
+
+// responseFromResult converts a Task agent's result into a Response.
+func responseFromResult(result *Result, delta map[string]interface{}, ok bool) *Response {
+	resp := &Response{}
+	if result == nil {
+		return resp
+	}
+	if result.Output != nil {
+		resp.Content = outputString(result.Output)
+	}
+	for _, step := range result.Steps {
+		resp.ToolCalls = append(resp.ToolCalls, step.ToolCalls...)
+	}
+	if n := len(result.Steps); n > 0 {
+		resp.FinishReason = result.Steps[n-1].FinishReason
+	}
+	resp.Artifacts = result.Artifacts
+	if result.Actions != nil || len(delta) > 0 {
+		actions := EventActions{}
+		if result.Actions != nil {
+			actions = *result.Actions
+		}
+		actions.StateDelta = delta
+		resp.Actions = &actions
+	}
+	resp.Finished = ok && result.Success
+	return resp
+}
+
+type sessionBackedAgent struct {
+	agent SessionAgent
+}
+
+func (a *sessionBackedAgent) Name() string {
+	return a.agent.Name()
+}
+
+func (a *sessionBackedAgent) SubAgents() []Agent {
+	subs := a.agent.Agents()
+	agents := make([]Agent, len(subs))
+	for i, sub := range subs {
+		agents[i] = AsAgent(sub)
+	}
+	return agents
+}
+
+func (a *sessionBackedAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
+	ctx = EnterAgent(ctx, a.agent.Name())
+	result := &Result{
+		TaskID:   task.ID,
+		Success:  false,
+		Metadata: make(map[string]interface{}),
+		Steps:    []ExecutionStep{},
+	}
+
+	parts, err := partsFromFiles(task.Files)
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+	state := NewMapStateFrom(task.State)
+	inv := &Invocation{
+		SessionID: task.ID,
+		Input:     &Message{Role: RoleUser, Content: task.Input, Parts: parts},
+		State:     state,
+		Config:    runConfigFrom(task.Config),
+	}
+	if task.Config != nil {
+		inv.UserID = task.Config.UserID
+	}
+
+	stepStart := Now(ctx)
+	resp, err := a.agent.Run(ctx, inv)
+	step := ExecutionStep{
+		AgentName: a.agent.Name(),
+		Action:    "run",
+		Input:     task.Input,
+		Duration:  Since(ctx, stepStart),
+		Timestamp: stepStart,
+	}
+
+	after := make(map[string]interface{})
+	for _, k := range state.Keys() {
+		if v, ok := state.Get(k); ok {
+			after[k] = v
+		}
+	}
+	delta, removed := stateChanges(task.State, after)
+	for k, v := range delta {
+		setState(task, &step, k, v)
+	}
+	for _, k := range removed {
+		delete(task.State, k)
+	}
+
+	if resp != nil {
+		step.Output = resp.Content
+		step.ToolCalls = resp.ToolCalls
+		step.FinishReason = resp.FinishReason
+		result.Output = resp.Content
+		result.Artifacts = resp.Artifacts
+		if resp.Actions != nil && (resp.Actions.Escalate || resp.Actions.TransferTo != "" || resp.Actions.ExitLoop || resp.Actions.SkipRemaining) {
+			actions := *resp.Actions
+			actions.StateDelta = nil
+			result.Actions = &actions
+		}
+	}
+	if err != nil {
+		step.Error = err.Error()
+		recordStep(ctx, task, result, step)
+		result.Error = err.Error()
+		result.markPartial(task.State)
+		return result, err
+	}
+	recordStep(ctx, task, result, step)
+	result.Success = true
+	result.aggregateMetrics()
+	return result, nil
+}
+
+// runConfigFrom converts the execution settings a SessionAgent understands.
+func runConfigFrom(cfg *ExecutionConfig) *RunConfig {
+	if cfg == nil {
+		return nil
+	}
+	return &RunConfig{
+		MaxIterations:  cfg.MaxIterations,
+		StreamingMode:  cfg.StreamingMode,
+		Temperature:    cfg.Temperature,
+		EnablePlan:     cfg.EnablePlan,
+		TimeoutSeconds: cfg.TimeoutSeconds,
+		Model:          cfg.Model,
+	}
+}
+
+// stateChanges returns the keys of after that are new or changed since
+// before, and the keys of before that are gone.
+func stateChanges(before, after map[string]interface{}) (map[string]interface{}, []string) {
+	delta := make(map[string]interface{})
+	for k, v := range after {
+		if old, ok := before[k]; !ok || !reflect.DeepEqual(old, v) {
+			delta[k] = v
+		}
+	}
+	var removed []string
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	return delta, removed
+}
+
+// filesFromParts converts the non-text parts of a message into files.
+func filesFromParts(parts []Part) []FileInput {
+	var files []FileInput
+	for _, p := range parts {
+		if p.Type == "" || p.Type == "text" {
+			continue
+		}
+		file := FileInput{Type: p.Type}
+		switch data := p.Data.(type) {
+		case []byte:
+			file.Content = data
+		case string:
+			file.URI = data
+		case FileInput:
+			file = data
+		default:
+			continue
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// partsFromFiles converts task files into message parts, as LLMAgent does.
+func partsFromFiles(files []FileInput) ([]Part, error) {
+	var parts []Part
+	for _, f := range files {
+		f, err := f.buffered()
+		if err != nil {
+			return nil, fmt.Errorf("read file %s: %w", f.Name, err)
+		}
+		var data interface{} = f.Content
+		switch {
+		case f.ContentReader != nil:
+			data = f
+		case f.URI != "" && f.Content == nil:
+			data = f.URI
+		}
+		parts = append(parts, Part{Type: f.Type, Data: data})
+	}
+	return parts, nil
+}
@@ -0,0 +1,185 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session is a conversation's persisted state and event history.
+type Session struct {
+	ID        string
+	UserID    string
+	State     map[string]interface{} // Current state, with every event's StateDelta applied
+	Events    []*Event
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	initial map[string]interface{} // State at creation, the base for StateAt
+}
+
+// SessionService stores sessions for SessionAgents. Implementations back it
+// with a database; NewInMemorySessionService keeps sessions in memory.
+type SessionService interface {
+	// Create starts a session with initial state. An empty id gets a
+	// generated one.
+	Create(ctx context.Context, id, userID string, state map[string]interface{}) (*Session, error)
+	Get(ctx context.Context, id string) (*Session, error)
+	Delete(ctx context.Context, id string) error
+
+	// AppendEvent adds an event to the history and applies its StateDelta.
+	// Invocation-scoped keys (StateTempPrefix) are not persisted.
+	AppendEvent(ctx context.Context, id string, ev *Event) error
+
+	// StateAt reconstructs the state as of the event at eventIndex (0-based),
+	// i.e. initial state plus the deltas of events 0 through eventIndex. An
+	// eventIndex of -1 returns the initial state.
+	StateAt(ctx context.Context, id string, eventIndex int) (map[string]interface{}, error)
+}
+
+// ErrSessionNotFound is returned for unknown session IDs.
+var ErrSessionNotFound = &jobStateError{msg: "session not found", status: http.StatusNotFound}
+
+// ErrEventIndex is returned by StateAt for an index outside the history.
+var ErrEventIndex = errors.New("event index out of range")
+
+// StateAt replays the StateDeltas of events 0 through eventIndex over a copy
+// of initial, for reconstructing state from any persisted event log.
+func StateAt(initial map[string]interface{}, events []*Event, eventIndex int) (map[string]interface{}, error) {
+	if eventIndex < -1 || eventIndex >= len(events) {
+		return nil, fmt.Errorf("%w: %d (history has %d events)", ErrEventIndex, eventIndex, len(events))
+	}
+	state := make(map[string]interface{}, len(initial))
+	for k, v := range initial {
+		state[k] = v
+	}
+	for _, ev := range events[:eventIndex+1] {
+		applyDelta(state, ev)
+	}
+	return state, nil
+}
+
+func applyDelta(state map[string]interface{}, ev *Event) {
+	if ev.Actions == nil {
+		return
+	}
+	for k, v := range ev.Actions.StateDelta {
+		if !strings.HasPrefix(k, StateTempPrefix) {
+			state[k] = v
+		}
+	}
+}
+
+// InMemorySessionService is a SessionService for tests and single-process
+// deployments. It is safe for concurrent use.
+type InMemorySessionService struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+// NewInMemorySessionService creates an empty in-memory SessionService.
+func NewInMemorySessionService() *InMemorySessionService {
+	return &InMemorySessionService{sessions: make(map[string]*Session)}
+}
+
+func (s *InMemorySessionService) Create(ctx context.Context, id, userID string, state map[string]interface{}) (*Session, error) {
+	if id == "" {
+		id = NewID(ctx)
+	}
+	if state == nil {
+		state = map[string]interface{}{}
+	}
+	now := Now(ctx)
+	sess := &Session{ID: id, UserID: userID, State: copyMap(state), CreatedAt: now, UpdatedAt: now, initial: copyMap(state)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.sessions[id]; exists {
+		return nil, fmt.Errorf("session %s already exists", id)
+	}
+	s.sessions[id] = sess
+	return sess.clone(), nil
+}
+
+// Get returns a copy of the session.
+func (s *InMemorySessionService) Get(ctx context.Context, id string) (*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	return sess.clone(), nil
+}
+
+func (s *InMemorySessionService) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	delete(s.sessions, id)
+	return nil
+}
+
+func (s *InMemorySessionService) AppendEvent(ctx context.Context, id string, ev *Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	sess.Events = append(sess.Events, withoutTemp(ev))
+	applyDelta(sess.State, ev)
+	sess.UpdatedAt = Now(ctx)
+	return nil
+}
+
+func (s *InMemorySessionService) StateAt(ctx context.Context, id string, eventIndex int) (map[string]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	return StateAt(sess.initial, sess.Events, eventIndex)
+}
+
+// withoutTemp returns ev, or a copy whose StateDelta has no
+// invocation-scoped keys.
+func withoutTemp(ev *Event) *Event {
+	if ev.Actions == nil {
+		return ev
+	}
+	var delta map[string]interface{}
+	for k := range ev.Actions.StateDelta {
+		if strings.HasPrefix(k, StateTempPrefix) {
+			delta = make(map[string]interface{}, len(ev.Actions.StateDelta))
+			break
+		}
+	}
+	if delta == nil {
+		return ev
+	}
+	for k, v := range ev.Actions.StateDelta {
+		if !strings.HasPrefix(k, StateTempPrefix) {
+			delta[k] = v
+		}
+	}
+	c := *ev
+	actions := *ev.Actions
+	actions.StateDelta = delta
+	c.Actions = &actions
+	return &c
+}
+
+func (sess *Session) clone() *Session {
+	c := *sess
+	c.State = copyMap(sess.State)
+	c.Events = append([]*Event(nil), sess.Events...)
+	return &c
+}
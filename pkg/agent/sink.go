@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+	"time"
+)
+
+// ResultSink receives every job an Executor finishes, pushing results to
+// downstream systems (webhooks, files, queues, databases) instead of having
+// them poll GetResult. See package sink for implementations.
+type ResultSink interface {
+	Deliver(ctx context.Context, job *Job) error
+}
+
+// ResultSinkFunc adapts a function to the ResultSink interface.
+type ResultSinkFunc func(ctx context.Context, job *Job) error
+
+func (f ResultSinkFunc) Deliver(ctx context.Context, job *Job) error {
+	return f(ctx, job)
+}
+
+// sinkConfig holds the Executor's sinks and how deliveries are bounded.
+type sinkConfig struct {
+	sinks   []ResultSink
+	timeout time.Duration
+	onError func(job *Job, err error)
+}
+
+// deliverResults writes a finished job to every sink. Each delivery gets its
+// own timeout so one slow sink cannot starve the others.
+func (e *Executor) deliverResults(job *Job) {
+	for _, sink := range e.sinks.sinks {
+		ctx, cancel := context.WithTimeout(context.Background(), e.sinks.timeout)
+		err := sink.Deliver(ctx, job)
+		cancel()
+		if err != nil && e.sinks.onError != nil {
+			e.sinks.onError(job, err)
+		}
+	}
+}
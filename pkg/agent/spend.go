@@ -0,0 +1,254 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SpendAction is what a SpendTracker does once a budget is used up.
+type SpendAction string
+
+const (
+	SpendReject   SpendAction = "reject"   // Fail further tasks in the scope with a *SpendLimitError
+	SpendFallback SpendAction = "fallback" // Run further tasks on SpendConfig.Fallback
+	SpendPause    SpendAction = "pause"    // Pause Executor intake; resume with Executor.Resume
+)
+
+// SpendConfig holds configuration for creating a SpendTracker. Budgets are
+// in USD per calendar day.
+type SpendConfig struct {
+	Pricing *Pricing // Default DefaultPricing
+
+	Daily     float64            // Budget across all tenants (0 = none)
+	PerTenant float64            // Budget of each tenant without its own entry in Tenants (0 = none)
+	Tenants   map[string]float64 // Budgets by TenantID
+
+	// Thresholds are the fractions of a budget that trigger an alert, once
+	// per budget per day (default 0.5, 0.9, 1).
+	Thresholds []float64
+
+	// OnAlert receives every alert, e.g. tools.NotifySpendAlerts(n, nil).
+	// It is called synchronously after the job that crossed the threshold.
+	OnAlert func(SpendAlert)
+
+	// OnLimit is what happens once a budget is used up (default SpendReject).
+	OnLimit SpendAction
+
+	// Fallback is the cheaper model tasks run on with SpendFallback.
+	Fallback ModelProvider
+
+	// Location sets where days begin (default time.Local).
+	Location *time.Location
+}
+
+// SpendAlert reports that spending crossed a threshold of a budget.
+type SpendAlert struct {
+	Tenant    string    `json:"tenant,omitempty"` // Empty for the Daily budget
+	Threshold float64   `json:"threshold"`        // Fraction of the budget, e.g. 0.9
+	Spent     float64   `json:"spent"`            // USD today
+	Budget    float64   `json:"budget"`           // USD per day
+	Day       string    `json:"day"`              // YYYY-MM-DD
+	At        time.Time `json:"at"`
+}
+
+// Message renders the alert, e.g. "Spend for tenant acme reached 90% of its
+// daily budget: $45.12 of $50.00".
+func (a SpendAlert) Message() string {
+	scope := "total spend"
+	if a.Tenant != "" {
+		scope = "spend for tenant " + a.Tenant
+	}
+	return fmt.Sprintf("%s reached %.0f%% of its daily budget: $%.2f of $%.2f", scope, a.Threshold*100, a.Spent, a.Budget)
+}
+
+// SpendLimitError rejects a task whose budget for the day is used up.
+type SpendLimitError struct {
+	Tenant string // Empty for the Daily budget
+	Spent  float64
+	Budget float64
+}
+
+func (e *SpendLimitError) Error() string {
+	if e.Tenant == "" {
+		return fmt.Sprintf("daily spend budget of $%.2f reached ($%.2f spent)", e.Budget, e.Spent)
+	}
+	return fmt.Sprintf("daily spend budget of tenant %s of $%.2f reached ($%.2f spent)", e.Tenant, e.Budget, e.Spent)
+}
+
+// StatusCode returns the HTTP status servers should respond with.
+func (e *SpendLimitError) StatusCode() int {
+	return http.StatusPaymentRequired
+}
+
+// SpendTracker totals the cost of finished tasks per tenant and day,
+// alerts as budgets fill up, and enforces them once they are used up. Add
+// it to an Executor with WithSpendTracker. It is safe for concurrent use.
+type SpendTracker struct {
+	cfg SpendConfig
+
+	mu      sync.Mutex
+	day     string
+	total   float64
+	tenants map[string]float64
+	alerted map[string]int // Thresholds already alerted, by tenant ("" for Daily)
+	pause   func(reason string)
+}
+
+// NewSpendTracker creates a tracker from the given configuration.
+func NewSpendTracker(cfg SpendConfig) *SpendTracker {
+	if cfg.Pricing == nil {
+		cfg.Pricing = DefaultPricing
+	}
+	if cfg.Thresholds == nil {
+		cfg.Thresholds = []float64{0.5, 0.9, 1}
+	}
+	cfg.Thresholds = append([]float64(nil), cfg.Thresholds...)
+	sort.Float64s(cfg.Thresholds)
+	if cfg.OnLimit == "" {
+		cfg.OnLimit = SpendReject
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+	return &SpendTracker{cfg: cfg, tenants: make(map[string]float64), alerted: make(map[string]int)}
+}
+
+// WithSpendTracker records the cost of every job in t, in
+// Result.Metadata["cost_usd"], and applies its budgets to new jobs.
+func WithSpendTracker(t *SpendTracker) ExecutorOption {
+	return func(e *Executor) {
+		e.agent = &spendAgent{Agent: e.agent, tracker: t}
+		t.mu.Lock()
+		t.pause = func(reason string) { e.Pause(reason) }
+		t.mu.Unlock()
+	}
+}
+
+// Spent returns today's spend of tenant and in total, in USD.
+func (t *SpendTracker) Spent(tenant string) (tenantSpent, total float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover(time.Now())
+	return t.tenants[tenant], t.total
+}
+
+// Record adds cost USD to tenant's spend and sends the alerts it triggers.
+func (t *SpendTracker) Record(tenant string, cost float64) {
+	if cost <= 0 {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	t.rollover(now)
+	t.total += cost
+	t.tenants[tenant] += cost
+	alerts := t.crossed("", t.total, t.cfg.Daily, now)
+	if tenant != "" {
+		alerts = append(alerts, t.crossed(tenant, t.tenants[tenant], t.budget(tenant), now)...)
+	}
+	limited := t.limitLocked(tenant) != nil
+	pause := t.pause
+	t.mu.Unlock()
+
+	for _, a := range alerts {
+		if t.cfg.OnAlert != nil {
+			t.cfg.OnAlert(a)
+		}
+	}
+	if limited && t.cfg.OnLimit == SpendPause && pause != nil {
+		pause("spend budget reached")
+	}
+}
+
+// Limit returns a *SpendLimitError if tenant's or the total budget for the
+// day is used up.
+func (t *SpendTracker) Limit(tenant string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover(time.Now())
+	return t.limitLocked(tenant)
+}
+
+func (t *SpendTracker) limitLocked(tenant string) error {
+	if t.cfg.Daily > 0 && t.total >= t.cfg.Daily {
+		return &SpendLimitError{Spent: t.total, Budget: t.cfg.Daily}
+	}
+	if budget := t.budget(tenant); tenant != "" && budget > 0 && t.tenants[tenant] >= budget {
+		return &SpendLimitError{Tenant: tenant, Spent: t.tenants[tenant], Budget: budget}
+	}
+	return nil
+}
+
+func (t *SpendTracker) budget(tenant string) float64 {
+	if b, ok := t.cfg.Tenants[tenant]; ok {
+		return b
+	}
+	return t.cfg.PerTenant
+}
+
+// rollover resets the totals when a new day has begun.
+func (t *SpendTracker) rollover(now time.Time) {
+	day := now.In(t.cfg.Location).Format(time.DateOnly)
+	if day == t.day {
+		return
+	}
+	t.day, t.total = day, 0
+	clear(t.tenants)
+	clear(t.alerted)
+}
+
+// crossed returns alerts for the thresholds of budget that spent has
+// newly reached.
+func (t *SpendTracker) crossed(tenant string, spent, budget float64, now time.Time) []SpendAlert {
+	if budget <= 0 {
+		return nil
+	}
+	var alerts []SpendAlert
+	for i := t.alerted[tenant]; i < len(t.cfg.Thresholds) && spent >= budget*t.cfg.Thresholds[i]; i++ {
+		alerts = append(alerts, SpendAlert{Tenant: tenant, Threshold: t.cfg.Thresholds[i], Spent: spent, Budget: budget, Day: t.day, At: now})
+		t.alerted[tenant] = i + 1
+	}
+	return alerts
+}
+
+// spendAgent decorates an Executor's agent with a SpendTracker.
+type spendAgent struct {
+	Agent
+	tracker *SpendTracker
+}
+
+func (a *spendAgent) Unwrap() Agent {
+	return a.Agent
+}
+
+func (a *spendAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
+	t := a.tracker
+	tenant := TenantID(task)
+	ag := a.Agent
+	fallback := false
+	if err := t.Limit(tenant); err != nil {
+		if t.cfg.OnLimit != SpendFallback || t.cfg.Fallback == nil {
+			return &Result{TaskID: task.ID, Error: err.Error(), Metadata: map[string]interface{}{}}, err
+		}
+		ag, fallback = WithModel(ag, t.cfg.Fallback), true
+	}
+
+	result, err := ag.Execute(ctx, task)
+	if result == nil {
+		return result, err
+	}
+	cost := t.cfg.Pricing.Cost(result)
+	t.Record(tenant, cost)
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]interface{})
+	}
+	result.Metadata["cost_usd"] = cost
+	if fallback {
+		result.Metadata["spend_fallback"] = true
+	}
+	return result, err
+}
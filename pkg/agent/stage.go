@@ -0,0 +1,187 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Stage wraps a child of a SequentialAgent, ParallelAgent, or PipelineAgent
+// with per-stage execution settings:
+//
+//	agent.NewSequentialAgent("report", []agent.Agent{
+//		research,
+//		&agent.Stage{Agent: enrich, Timeout: 30 * time.Second, Retries: 1, Optional: true},
+//		write,
+//	})
+//
+// The child runs on a copy of the task state that is merged back only when
+// it succeeds, so an attempt that timed out or failed leaves no writes
+// behind.
+type Stage struct {
+	Agent
+
+	// Timeout bounds each attempt (0 = only the workflow's own deadline).
+	// An attempt that overruns is abandoned even if the child ignores its
+	// context.
+	Timeout time.Duration
+
+	// Retries is how many times a failed attempt is retried.
+	Retries int
+
+	// RetryDelay is the wait before the first retry, doubling after each
+	// one (default 1s).
+	RetryDelay time.Duration
+
+	// Optional stages that still fail are skipped instead of failing the
+	// workflow: the stage's input passes through as its output, and the
+	// error is recorded in a "skip" step and Metadata["stage_skipped"].
+	Optional bool
+}
+
+// StageTimeoutError reports a stage attempt that exceeded Stage.Timeout.
+type StageTimeoutError struct {
+	Stage   string
+	Timeout time.Duration
+}
+
+func (e *StageTimeoutError) Error() string {
+	return fmt.Sprintf("stage %s timed out after %s", e.Stage, e.Timeout)
+}
+
+func (e *StageTimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+func (s *Stage) Unwrap() Agent {
+	return s.Agent
+}
+
+func (s *Stage) Execute(ctx context.Context, task *Task) (*Result, error) {
+	delay := s.RetryDelay
+	if delay == 0 {
+		delay = time.Second
+	}
+
+	var result *Result
+	var err error
+	var retries []ExecutionStep
+	for attempt := 0; ; attempt++ {
+		result, err = s.attempt(ctx, task)
+		if err == nil || attempt == s.Retries || ctx.Err() != nil {
+			break
+		}
+		step := ExecutionStep{
+			AgentName: s.Agent.Name(),
+			AgentPath: pathFor(ctx, s.Agent.Name()),
+			Action:    "retry",
+			Error:     err.Error(),
+			Timestamp: Now(ctx),
+		}
+		retries = append(retries, step)
+		emit(task, eventFromStep(ctx, task.ID, step))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+		delay *= 2
+	}
+	if result == nil {
+		result = &Result{TaskID: task.ID, Metadata: make(map[string]interface{})}
+	}
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]interface{})
+	}
+	result.Steps = append(retries, result.Steps...)
+	if len(retries) > 0 {
+		result.Metadata["stage_attempts"] = len(retries) + 1
+	}
+	if err == nil || !s.Optional || ctx.Err() != nil {
+		return result, err
+	}
+
+	recordStep(ctx, task, result, ExecutionStep{
+		AgentName: s.Agent.Name(),
+		Action:    "skip",
+		Input:     task.Input,
+		Error:     err.Error(),
+		Timestamp: Now(ctx),
+	})
+	result.Metadata["stage_skipped"] = err.Error()
+	result.Output = task.Input
+	result.Error = ""
+	result.Success = true
+	return result, nil
+}
+
+// attempt runs the child once on a copy of the task, bounded by Timeout.
+func (s *Stage) attempt(ctx context.Context, task *Task) (*Result, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	attempt := *task
+	attempt.State = copyMap(task.State)
+	if attempt.State == nil {
+		attempt.State = make(map[string]interface{})
+	}
+
+	type outcome struct {
+		result *Result
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		var o outcome
+		defer func() { done <- o }()
+		defer recoverPanic(&o.err)
+		o.result, o.err = s.Agent.Execute(ctx, &attempt)
+	}()
+
+	select {
+	case o := <-done:
+		if o.err != nil && s.Timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			o.err = fmt.Errorf("%w: %v", &StageTimeoutError{Stage: s.Agent.Name(), Timeout: s.Timeout}, o.err)
+		}
+		if o.err == nil {
+			replaceState(task, attempt.State)
+			task.Input = attempt.Input
+		}
+		return o.result, o.err
+	case <-ctx.Done():
+		err := ctx.Err()
+		if s.Timeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+			err = &StageTimeoutError{Stage: s.Agent.Name(), Timeout: s.Timeout}
+		}
+		return nil, err
+	}
+}
+
+// replaceState makes task.State hold exactly state, in place, so callers
+// holding the map see the change.
+func replaceState(task *Task, state map[string]interface{}) {
+	if task.State == nil {
+		task.State = state
+		return
+	}
+	for k := range task.State {
+		if _, ok := state[k]; !ok {
+			delete(task.State, k)
+		}
+	}
+	for k, v := range state {
+		task.State[k] = v
+	}
+}
+
+// stageSkipped reports whether an optional Stage was skipped, so workflows
+// keep the output of the stages before it.
+func stageSkipped(result *Result) bool {
+	if result == nil {
+		return false
+	}
+	_, ok := result.Metadata["stage_skipped"]
+	return ok
+}
@@ -0,0 +1,166 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// CachedStage is a completed stage execution: its result and the task state
+// it left behind, so a cache hit can restore both.
+type CachedStage struct {
+	Result *Result
+	State  map[string]interface{}
+}
+
+// StageCache stores completed stage executions keyed by stage identity,
+// input hash, and state hash. Implementations must be safe for concurrent use.
+type StageCache interface {
+	Get(ctx context.Context, key string) (*CachedStage, bool, error)
+	Set(ctx context.Context, key string, entry *CachedStage) error
+}
+
+// MemoryStageCache is an in-process StageCache.
+type MemoryStageCache struct {
+	mu      sync.RWMutex
+	entries map[string]*CachedStage
+}
+
+// NewMemoryStageCache creates a new empty MemoryStageCache.
+func NewMemoryStageCache() *MemoryStageCache {
+	return &MemoryStageCache{entries: make(map[string]*CachedStage)}
+}
+
+func (c *MemoryStageCache) Get(ctx context.Context, key string) (*CachedStage, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	return entry, ok, nil
+}
+
+func (c *MemoryStageCache) Set(ctx context.Context, key string, entry *CachedStage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+	return nil
+}
+
+// stageCacheKey identifies a stage run by workflow, position, stage name,
+// input, and state.
+func stageCacheKey(workflow string, index int, stage Agent, task *Task) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%d\x00%s\x00%s\x00", workflow, index, stage.Name(), task.Input)
+
+	// encoding/json sorts map keys, giving a stable state encoding
+	if data, err := json.Marshal(task.State); err == nil {
+		h.Write(data)
+	} else {
+		fmt.Fprint(h, task.State)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// runStage executes a workflow stage, serving it from the stage cache when
+// one is configured and holds a matching entry.
+func (o *workflowOptions) runStage(ctx context.Context, workflow string, index int, stage Agent, task *Task) (*Result, error) {
+	if o.cache == nil {
+		return stage.Execute(ctx, task)
+	}
+
+	key := stageCacheKey(workflow, index, stage, task)
+	if entry, ok, err := o.cache.Get(ctx, key); err == nil && ok {
+		for k := range task.State {
+			delete(task.State, k)
+		}
+		for k, v := range entry.State {
+			task.State[k] = deepCopy(v)
+		}
+
+		// Workflows write into the result, so each hit gets its own copy
+		cached := copyResult(entry.Result)
+		cached.Steps = append([]ExecutionStep{{
+			AgentName: stage.Name(),
+			AgentPath: pathFor(ctx, stage.Name()),
+			Action:    "cache_hit",
+			Timestamp: Now(ctx),
+		}}, cached.Steps...)
+		return cached, nil
+	}
+
+	res, err := stage.Execute(ctx, task)
+	if err != nil || res == nil || !res.Success || res.Escalated() || stageSkipped(res) {
+		return res, err
+	}
+
+	// The workflow goes on to modify res and the state; cache copies
+	state := deepCopy(task.State).(map[string]interface{})
+	// A failing cache write must not fail the stage
+	_ = o.cache.Set(ctx, key, &CachedStage{Result: copyResult(res), State: state})
+	return res, nil
+}
+
+// copyResult returns a copy of r sharing no maps or slices with it.
+func copyResult(r *Result) *Result {
+	c := *r
+	c.Output = deepCopy(r.Output)
+	c.Outputs, _ = deepCopy(r.Outputs).(map[string]interface{})
+	c.Metadata, _ = deepCopy(r.Metadata).(map[string]interface{})
+	c.State, _ = deepCopy(r.State).(map[string]interface{})
+	c.Artifacts = slices.Clone(r.Artifacts)
+	if r.Actions != nil {
+		actions := *r.Actions
+		actions.StateDelta, _ = deepCopy(r.Actions.StateDelta).(map[string]interface{})
+		c.Actions = &actions
+	}
+	if r.Steps != nil {
+		c.Steps = make([]ExecutionStep, len(r.Steps))
+		for i, step := range r.Steps {
+			step.Input = deepCopy(step.Input)
+			step.Output = deepCopy(step.Output)
+			step.StateDelta, _ = deepCopy(step.StateDelta).(map[string]interface{})
+			if step.ToolCalls != nil {
+				calls := make([]ToolCall, len(step.ToolCalls))
+				for j, call := range step.ToolCalls {
+					call.Arguments, _ = deepCopy(call.Arguments).(map[string]interface{})
+					call.Result = deepCopy(call.Result)
+					calls[j] = call
+				}
+				step.ToolCalls = calls
+			}
+			c.Steps[i] = step
+		}
+	}
+	return &c
+}
+
+// deepCopy copies the JSON-like maps and slices in v; other values are
+// shared.
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		c := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			c[k] = deepCopy(e)
+		}
+		return c
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		c := make([]interface{}, len(v))
+		for i, e := range v {
+			c[i] = deepCopy(e)
+		}
+		return c
+	case []string:
+		return slices.Clone(v)
+	}
+	return v
+}
@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrJobStalled is the error of a job cancelled by stall detection.
+var ErrJobStalled = errors.New("job stalled: no heartbeat within interval")
+
+type stallConfig struct {
+	interval time.Duration
+	cancel   bool
+	onStall  func(job *Job)
+}
+
+// beat records progress on a job.
+func (j *Job) beat() {
+	j.lastHeartbeat.Store(time.Now().UnixNano())
+}
+
+// LastHeartbeat returns when the job last showed progress.
+func (j *Job) LastHeartbeat() time.Time {
+	return time.Unix(0, j.lastHeartbeat.Load())
+}
+
+// withHeartbeat returns a copy of the task config whose event handler also
+// records heartbeats on the job, preserving any caller handler.
+func withHeartbeat(cfg *ExecutionConfig, job *Job) *ExecutionConfig {
+	var c ExecutionConfig
+	if cfg != nil {
+		c = *cfg
+	}
+	next := c.OnEvent
+	c.OnEvent = func(ev *Event) {
+		job.beat()
+		if next != nil {
+			next(ev)
+		}
+	}
+	return &c
+}
+
+// monitorStalls periodically checks running jobs for missing heartbeats.
+func (e *Executor) monitorStalls() {
+	ticker := time.NewTicker(e.stall.interval / 2)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		var stalled []*Job
+
+		// Compare-and-swap so a job finishing concurrently keeps its final status
+		e.jobs.each(func(job *Job) {
+			switch job.Status() {
+			case JobRunning:
+				if now.Sub(job.LastHeartbeat()) > e.stall.interval &&
+					job.status.CompareAndSwap(JobRunning, JobStalled) {
+					stalled = append(stalled, job)
+				}
+			case JobStalled:
+				// Progress resumed after being flagged
+				if now.Sub(job.LastHeartbeat()) <= e.stall.interval {
+					job.status.CompareAndSwap(JobStalled, JobRunning)
+				}
+			}
+		})
+
+		for _, job := range stalled {
+			e.counters.stalled.Add(1)
+			if e.stall.onStall != nil {
+				e.stall.onStall(job)
+			}
+			if e.stall.cancel && job.cancel != nil {
+				job.stallCancelled.Store(true)
+				job.cancel()
+			}
+		}
+	}
+}
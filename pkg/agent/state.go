@@ -1,12 +1,21 @@
 package agent
 
-import "sync"
+import (
+	"strings"
+	"sync"
+	"time"
+)
 
 // MapState is a thread-safe implementation of the State interface
-// backed by a map.
+// backed by a map. Keys may expire: set a TTL per key with SetWithTTL or per
+// key prefix with SetPrefixTTL. Expired keys are invisible to reads and are
+// removed on access or by Sweep.
 type MapState struct {
-	mu   sync.RWMutex
-	data map[string]interface{}
+	mu      sync.RWMutex
+	data    map[string]interface{}
+	expires map[string]time.Time
+	ttls    map[string]time.Duration // By key prefix
+	clock   Clock                    // Nil for SystemClock
 }
 
 // NewMapState creates a new empty MapState.
@@ -25,31 +34,96 @@ func NewMapStateFrom(initial map[string]interface{}) *MapState {
 	return &MapState{data: data}
 }
 
+// SetClock makes expiry use clock instead of the system time, e.g. a
+// ManualClock to test TTLs deterministically.
+func (s *MapState) SetClock(clock Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+}
+
+// now returns the time on the state's clock; the caller holds the lock.
+func (s *MapState) now() time.Time {
+	if s.clock != nil {
+		return s.clock.Now()
+	}
+	return time.Now()
+}
+
 func (s *MapState) Get(key string) (interface{}, bool) {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
 	v, ok := s.data[key]
+	expired := ok && s.expired(key, s.now())
+	s.mu.RUnlock()
+	if expired {
+		s.sweepKey(key)
+		return nil, false
+	}
 	return v, ok
 }
 
 func (s *MapState) Set(key string, value interface{}) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.data[key] = value
+	s.set(key, value, s.prefixTTL(key))
+}
+
+// SetWithTTL stores value under key until ttl has passed. A ttl <= 0 stores
+// it without expiry, overriding any prefix TTL.
+func (s *MapState) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(key, value, ttl)
+}
+
+// SetPrefixTTL makes keys starting with prefix expire ttl after they are
+// set, e.g. SetPrefixTTL("cache:", time.Hour). It applies to later writes; a
+// ttl <= 0 removes the rule.
+func (s *MapState) SetPrefixTTL(prefix string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ttl <= 0 {
+		delete(s.ttls, prefix)
+		return
+	}
+	if s.ttls == nil {
+		s.ttls = make(map[string]time.Duration)
+	}
+	s.ttls[prefix] = ttl
+}
+
+// TTL returns how long key has left, and false if it does not exist or
+// does not expire.
+func (s *MapState) TTL(key string) (time.Duration, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	at, ok := s.expires[key]
+	if _, exists := s.data[key]; !exists || !ok {
+		return 0, false
+	}
+	left := at.Sub(s.now())
+	if left <= 0 {
+		return 0, false
+	}
+	return left, true
 }
 
 func (s *MapState) Delete(key string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	delete(s.data, key)
+	delete(s.expires, key)
 }
 
 func (s *MapState) Keys() []string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	now := s.now()
 	keys := make([]string, 0, len(s.data))
 	for k := range s.data {
-		keys = append(keys, k)
+		if !s.expired(k, now) {
+			keys = append(keys, k)
+		}
 	}
 	return keys
 }
@@ -58,6 +132,75 @@ func (s *MapState) Merge(delta map[string]interface{}) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for k, v := range delta {
-		s.data[k] = v
+		s.set(k, v, s.prefixTTL(k))
+	}
+}
+
+// Sweep removes expired keys and returns how many it removed. See
+// StartSweeper to run it periodically.
+func (s *MapState) Sweep() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	n := 0
+	for k := range s.expires {
+		if s.expired(k, now) {
+			delete(s.data, k)
+			delete(s.expires, k)
+			n++
+		}
+	}
+	return n
+}
+
+// ClearTemp removes the invocation-scoped keys (see StateTempPrefix).
+func (s *MapState) ClearTemp() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k := range s.data {
+		if strings.HasPrefix(k, StateTempPrefix) {
+			delete(s.data, k)
+			delete(s.expires, k)
+		}
+	}
+}
+
+// set stores a value; the caller holds the write lock.
+func (s *MapState) set(key string, value interface{}, ttl time.Duration) {
+	s.data[key] = value
+	if ttl <= 0 {
+		delete(s.expires, key)
+		return
+	}
+	if s.expires == nil {
+		s.expires = make(map[string]time.Time)
+	}
+	s.expires[key] = s.now().Add(ttl)
+}
+
+// prefixTTL returns the TTL of the longest prefix rule matching key.
+func (s *MapState) prefixTTL(key string) time.Duration {
+	var ttl time.Duration
+	longest := -1
+	for prefix, d := range s.ttls {
+		if len(prefix) > longest && strings.HasPrefix(key, prefix) {
+			ttl, longest = d, len(prefix)
+		}
+	}
+	return ttl
+}
+
+func (s *MapState) expired(key string, now time.Time) bool {
+	at, ok := s.expires[key]
+	return ok && !now.Before(at)
+}
+
+// sweepKey removes key if it is still expired.
+func (s *MapState) sweepKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.expired(key, s.now()) {
+		delete(s.data, key)
+		delete(s.expires, key)
 	}
 }
@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// StateFormat selects how state values are injected into the system prompt.
+type StateFormat int
+
+const (
+	// StateInline replaces {key} placeholders in the prompt with values.
+	StateInline StateFormat = iota
+	// StateJSONBlock replaces placeholders and also appends the injected
+	// keys to the prompt as a JSON block.
+	StateJSONBlock
+)
+
+// StatePolicy controls which task state reaches the system prompt and how.
+// Key patterns use path.Match syntax, e.g. "user_*".
+type StatePolicy struct {
+	Keys           []string // Keys allowed into the prompt (empty = all)
+	Format         StateFormat
+	MaxValueLength int      // Per-key cap in characters; longer values are truncated (0 = unlimited)
+	SecretKeys     []string // Keys whose values are replaced with RedactWith
+	RedactWith     string   // Replacement for secret values (default "[REDACTED]")
+}
+
+// DefaultStatePolicy injects every key inline without limits.
+var DefaultStatePolicy = &StatePolicy{}
+
+// apply renders prompt with state injected according to the policy.
+func (p *StatePolicy) apply(prompt string, state map[string]interface{}) string {
+	if len(state) == 0 {
+		return prompt
+	}
+	var injected map[string]interface{}
+	if p.Format == StateJSONBlock {
+		injected = make(map[string]interface{}, len(state))
+	}
+	hasPlaceholders := strings.IndexByte(prompt, '{') >= 0
+	for key, val := range state {
+		if !p.allowed(key) {
+			continue
+		}
+		placeholder := ""
+		if hasPlaceholders {
+			placeholder = "{" + key + "}"
+			if !strings.Contains(prompt, placeholder) {
+				placeholder = ""
+			}
+		}
+		// Values are only formatted when they will appear in the prompt
+		if placeholder == "" && p.Format != StateJSONBlock {
+			continue
+		}
+		text := p.value(key, val)
+		if placeholder != "" {
+			prompt = strings.ReplaceAll(prompt, placeholder, text)
+		}
+		if p.Format == StateJSONBlock {
+			if _, isString := val.(string); isString || p.secret(key) || p.truncated(val) {
+				injected[key] = text
+			} else {
+				injected[key] = val
+			}
+		}
+	}
+
+	if p.Format != StateJSONBlock || len(injected) == 0 {
+		return prompt
+	}
+	block, err := json.MarshalIndent(injected, "", "  ")
+	if err != nil {
+		return prompt
+	}
+	return prompt + "\n\nCurrent state:\n```json\n" + string(block) + "\n```"
+}
+
+func (p *StatePolicy) allowed(key string) bool {
+	return len(p.Keys) == 0 || matchKey(p.Keys, key)
+}
+
+func (p *StatePolicy) secret(key string) bool {
+	return matchKey(p.SecretKeys, key)
+}
+
+// truncated reports whether val is cut by MaxValueLength.
+func (p *StatePolicy) truncated(val interface{}) bool {
+	return p.MaxValueLength > 0 && len([]rune(formatStateValue(val))) > p.MaxValueLength
+}
+
+// value returns the prompt text for a state value after redaction and
+// truncation.
+func (p *StatePolicy) value(key string, val interface{}) string {
+	if p.secret(key) {
+		if p.RedactWith != "" {
+			return p.RedactWith
+		}
+		return "[REDACTED]"
+	}
+	text := formatStateValue(val)
+	if p.MaxValueLength > 0 {
+		if r := []rune(text); len(r) > p.MaxValueLength {
+			text = string(r[:p.MaxValueLength]) + "...(truncated)"
+		}
+	}
+	return text
+}
+
+// formatStateValue renders strings as-is and other values as JSON, falling
+// back to fmt.Sprint for values that cannot be encoded.
+func formatStateValue(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+	return string(data)
+}
+
+func matchKey(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
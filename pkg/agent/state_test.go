@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"strconv"
+	"testing"
+)
+
+func benchState(keys int) (*MapState, []string) {
+	state := NewMapState()
+	names := make([]string, keys)
+	for i := range names {
+		names[i] = "key-" + strconv.Itoa(i)
+		state.Set(names[i], i)
+	}
+	return state, names
+}
+
+func BenchmarkMapStateGet(b *testing.B) {
+	state, names := benchState(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		state.Get(names[i%len(names)])
+	}
+}
+
+func BenchmarkMapStateSet(b *testing.B) {
+	state, names := benchState(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		state.Set(names[i%len(names)], i)
+	}
+}
+
+// BenchmarkMapStateParallel mixes 90% reads and 10% writes from all
+// goroutines at once.
+func BenchmarkMapStateParallel(b *testing.B) {
+	state, names := benchState(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			key := names[(i*7919)%len(names)]
+			if i%10 == 0 {
+				state.Set(key, i)
+			} else {
+				state.Get(key)
+			}
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// StateTempPrefix marks invocation-scoped state keys, e.g. "temp:draft".
+// They are removed from a task's state when Executor jobs and ExecuteSync
+// calls finish, so they are not returned in Result.State; session stores
+// should not persist them past the invocation (see MapState.ClearTemp).
+const StateTempPrefix = "temp:"
+
+// ExpiringState is implemented by states and persistent stores that
+// support per-key TTLs.
+type ExpiringState interface {
+	State
+	SetWithTTL(key string, value interface{}, ttl time.Duration)
+	TTL(key string) (time.Duration, bool)
+}
+
+// Sweeper is implemented by states and stores that can remove their
+// expired entries in bulk.
+type Sweeper interface {
+	Sweep() int
+}
+
+// StartSweeper calls s.Sweep every interval until ctx is done, so expired
+// keys do not accumulate in stores that are rarely read. onSweep, if
+// non-nil, receives the number of keys removed by each run.
+func StartSweeper(ctx context.Context, s Sweeper, interval time.Duration, onSweep func(removed int)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n := s.Sweep()
+				if onSweep != nil {
+					onSweep(n)
+				}
+			}
+		}
+	}()
+}
+
+// clearTempKeys removes invocation-scoped keys from a task state map.
+func clearTempKeys(state map[string]interface{}) {
+	for k := range state {
+		if strings.HasPrefix(k, StateTempPrefix) {
+			delete(state, k)
+		}
+	}
+}
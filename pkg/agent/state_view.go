@@ -0,0 +1,147 @@
+package agent
+
+import (
+	"context"
+	"sync"
+)
+
+// StateScope limits which task state a tool can see and change, so
+// third-party tools cannot read or overwrite unrelated data. Key patterns
+// use path.Match syntax, e.g. "public:*".
+type StateScope struct {
+	Keys     []string // Keys the tool may read (empty = all)
+	Write    []string // Keys the tool may write, through its view or a map result (empty = same as Keys)
+	ReadOnly bool     // No writes at all
+}
+
+// ReadOnlyState returns a scope that can read keys (all if none are given)
+// but not write any.
+func ReadOnlyState(keys ...string) *StateScope {
+	return &StateScope{Keys: keys, ReadOnly: true}
+}
+
+// ScopedState returns a scope that can read and write only keys.
+func ScopedState(keys ...string) *StateScope {
+	return &StateScope{Keys: keys}
+}
+
+// StateScoped is implemented by tools that declare their own scope.
+// LLMAgentConfig.ToolStateScopes takes precedence.
+type StateScoped interface {
+	StateScope() *StateScope
+}
+
+func (s *StateScope) readable(key string) bool {
+	return s == nil || len(s.Keys) == 0 || matchKey(s.Keys, key)
+}
+
+func (s *StateScope) writable(key string) bool {
+	switch {
+	case s == nil:
+		return true
+	case s.ReadOnly:
+		return false
+	case len(s.Write) > 0:
+		return matchKey(s.Write, key)
+	}
+	return s.readable(key)
+}
+
+// stateView is the State a tool sees through StateFromContext. Reads and
+// writes outside its scope behave as if the key did not exist; writes are
+// recorded on the step like any other state change.
+type stateView struct {
+	mu     sync.Mutex
+	data   map[string]interface{}
+	scope  *StateScope
+	set    func(key string, value interface{})
+	denied int
+}
+
+type stateViewKey struct{}
+
+// StateFromContext returns the task state visible to the tool being
+// executed, limited to the tool's StateScope, or nil outside a tool call.
+// It is only valid until the tool returns.
+func StateFromContext(ctx context.Context) State {
+	if v, ok := ctx.Value(stateViewKey{}).(*stateView); ok {
+		return v
+	}
+	return nil
+}
+
+func (v *stateView) Get(key string) (interface{}, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.scope.readable(key) {
+		return nil, false
+	}
+	val, ok := v.data[key]
+	return val, ok
+}
+
+func (v *stateView) Set(key string, value interface{}) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.scope.writable(key) {
+		v.denied++
+		return
+	}
+	v.set(key, value)
+}
+
+func (v *stateView) Delete(key string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.scope.writable(key) {
+		v.denied++
+		return
+	}
+	delete(v.data, key)
+}
+
+func (v *stateView) Keys() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	keys := make([]string, 0, len(v.data))
+	for k := range v.data {
+		if v.scope.readable(k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func (v *stateView) Merge(delta map[string]interface{}) {
+	for k, val := range delta {
+		v.Set(k, val)
+	}
+}
+
+// toolStateScope returns the scope configured for tool, if any.
+func (a *LLMAgent) toolStateScope(tool Tool) *StateScope {
+	if scope, ok := a.stateScopes[tool.Name()]; ok {
+		return scope
+	}
+	if s, ok := tool.(StateScoped); ok {
+		return s.StateScope()
+	}
+	return a.defaultScope
+}
+
+// recordDeniedWrites counts state writes a tool's scope rejected in
+// Metadata["state_writes_denied"].
+func recordDeniedWrites(result *Result, n int) {
+	if n > 0 {
+		count, _ := result.Metadata["state_writes_denied"].(int)
+		result.Metadata["state_writes_denied"] = count + n
+	}
+}
+
+// withStateView gives a tool call its view of the task state. Writes land in
+// task state and the step's StateDelta.
+func withStateView(ctx context.Context, task *Task, step *ExecutionStep, scope *StateScope) (context.Context, *stateView) {
+	v := &stateView{data: task.State, scope: scope}
+	v.set = func(key string, value interface{}) { setState(task, step, key, value) }
+	return context.WithValue(ctx, stateViewKey{}, v), v
+}
@@ -0,0 +1,29 @@
+package agent
+
+// TemperatureSchedule returns the sampling temperature for a zero-based turn.
+type TemperatureSchedule func(turn int) float32
+
+// LinearTemperature interpolates from start on the first turn to end on turn
+// turns-1, holding end afterwards.
+func LinearTemperature(start, end float32, turns int) TemperatureSchedule {
+	return func(turn int) float32 {
+		if turns <= 1 || turn >= turns-1 {
+			return end
+		}
+		return start + (end-start)*float32(turn)/float32(turns-1)
+	}
+}
+
+// StepTemperature uses temps[turn] for each turn, holding the last value once
+// the list runs out.
+func StepTemperature(temps ...float32) TemperatureSchedule {
+	return func(turn int) float32 {
+		if len(temps) == 0 {
+			return 0
+		}
+		if turn >= len(temps) {
+			return temps[len(temps)-1]
+		}
+		return temps[turn]
+	}
+}
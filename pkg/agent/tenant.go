@@ -0,0 +1,192 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// TenantConfig is the execution setup of one tenant: which provider, model,
+// and API key to use, and which tools it may call. Zero fields keep the
+// agents' own configuration.
+type TenantConfig struct {
+	ID        string
+	Model     ModelProvider // Provider for every LLMAgent in the tree (nil = agents' own)
+	ModelName string        // Sent as CompletionRequest.Model (empty = provider default)
+	APIKey    string        // Bring-your-own key; providers read it with APIKeyFromContext
+	Tools     []string      // Tool allowlist by name (nil = all tools)
+	Members   []string      // User IDs that may select this tenant with Params["org_id"] (TenantMap)
+}
+
+// TenantResolver looks up a tenant's configuration. It returns an error
+// wrapping ErrUnknownTenant for tenants it does not know. When a task names
+// a tenant in Params["org_id"], userID is the identified caller ("" if
+// none) and differs from tenantID; the resolver must return an error
+// wrapping ErrTenantDenied unless that user belongs to the tenant.
+type TenantResolver interface {
+	ResolveTenant(ctx context.Context, tenantID, userID string) (*TenantConfig, error)
+}
+
+// TenantResolverFunc adapts a function to the TenantResolver interface.
+type TenantResolverFunc func(ctx context.Context, tenantID, userID string) (*TenantConfig, error)
+
+func (f TenantResolverFunc) ResolveTenant(ctx context.Context, tenantID, userID string) (*TenantConfig, error) {
+	return f(ctx, tenantID, userID)
+}
+
+// TenantMap is a static TenantResolver. The "" entry, if present, serves
+// tasks without a tenant ID and tenants not in the map. An identified user
+// may select another tenant with Params["org_id"] only if the tenant lists
+// them in Members.
+type TenantMap map[string]*TenantConfig
+
+func (m TenantMap) ResolveTenant(ctx context.Context, tenantID, userID string) (*TenantConfig, error) {
+	if userID != "" && tenantID != userID {
+		if cfg, ok := m[tenantID]; ok && slices.Contains(cfg.Members, userID) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("%w: user %q for %q", ErrTenantDenied, userID, tenantID)
+	}
+	if cfg, ok := m[tenantID]; ok {
+		return cfg, nil
+	}
+	if cfg, ok := m[""]; ok {
+		return cfg, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownTenant, tenantID)
+}
+
+// ErrUnknownTenant is matched (via errors.Is) when a task's tenant cannot be
+// resolved.
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// ErrTenantDenied is matched (via errors.Is) when the caller may not run as
+// the tenant a task names.
+var ErrTenantDenied = errors.New("tenant not permitted")
+
+// TenantError reports a task whose tenant configuration could not be
+// resolved.
+type TenantError struct {
+	Tenant string
+	Err    error
+}
+
+func (e *TenantError) Error() string {
+	return fmt.Sprintf("resolve tenant %q: %v", e.Tenant, e.Err)
+}
+
+func (e *TenantError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the HTTP status servers should respond with.
+func (e *TenantError) StatusCode() int {
+	if errors.Is(e.Err, ErrUnknownTenant) || errors.Is(e.Err, ErrTenantDenied) {
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// TenantID returns the tenant a task is attributed to, e.g. for spend
+// budgets: the caller's ExecutionConfig.UserID, or Params["org_id"] for
+// tasks without an identified caller. Params come from the client, so an
+// identified caller's org_id is only honoured by WithTenants once its
+// resolver authorizes the caller for that tenant.
+func TenantID(task *Task) string {
+	if user := taskUserID(task); user != "" {
+		return user
+	}
+	org, _ := task.Params["org_id"].(string)
+	return org
+}
+
+// claimedTenant returns the tenant a task asks to run as, Params["org_id"]
+// if set, else the caller's user ID, along with that user ID.
+func claimedTenant(task *Task) (tenantID, userID string) {
+	userID = taskUserID(task)
+	if org, ok := task.Params["org_id"].(string); ok && org != "" {
+		return org, userID
+	}
+	return userID, userID
+}
+
+func taskUserID(task *Task) string {
+	if task.Config != nil {
+		return task.Config.UserID
+	}
+	return ""
+}
+
+type tenantKey struct{}
+
+// TenantFromContext returns the tenant configuration resolved for the
+// running task, or nil.
+func TenantFromContext(ctx context.Context) *TenantConfig {
+	cfg, _ := ctx.Value(tenantKey{}).(*TenantConfig)
+	return cfg
+}
+
+// APIKeyFromContext returns the tenant's API key, or "" to use the
+// provider's own. Providers supporting bring-your-own-key call it per request.
+func APIKeyFromContext(ctx context.Context) string {
+	if cfg := TenantFromContext(ctx); cfg != nil {
+		return cfg.APIKey
+	}
+	return ""
+}
+
+// tenantAgent decorates an agent with per-tenant configuration.
+type tenantAgent struct {
+	Agent
+	resolver TenantResolver
+}
+
+// WithTenants wraps ag so each task runs with its tenant's configuration:
+// Params["org_id"] if set and the resolver authorizes the caller for it,
+// else the caller's ExecutionConfig.UserID. Wrap the root agent; a
+// WithModel decorator further down still overrides the tenant's provider
+// for its subtree.
+func WithTenants(ag Agent, resolver TenantResolver) Agent {
+	return &tenantAgent{Agent: ag, resolver: resolver}
+}
+
+func (a *tenantAgent) Unwrap() Agent {
+	return a.Agent
+}
+
+func (a *tenantAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
+	id, user := claimedTenant(task)
+	cfg, err := a.resolver.ResolveTenant(ctx, id, user)
+	if err != nil {
+		terr := &TenantError{Tenant: id, Err: err}
+		return &Result{TaskID: task.ID, Error: terr.Error(), Metadata: map[string]interface{}{}}, terr
+	}
+	if cfg != nil {
+		ctx = context.WithValue(ctx, tenantKey{}, cfg)
+		if cfg.Model != nil {
+			ctx = context.WithValue(ctx, modelOverrideKey{}, cfg.Model)
+		}
+	}
+	return a.Agent.Execute(ctx, task)
+}
+
+// allowTools filters tools to the tenant's allowlist, if it has one.
+func allowTools(ctx context.Context, tools []Tool) []Tool {
+	cfg := TenantFromContext(ctx)
+	if cfg == nil || cfg.Tools == nil {
+		return tools
+	}
+	allowed := make(map[string]bool, len(cfg.Tools))
+	for _, name := range cfg.Tools {
+		allowed[name] = true
+	}
+	filtered := make([]Tool, 0, len(tools))
+	for _, t := range tools {
+		if allowed[t.Name()] {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
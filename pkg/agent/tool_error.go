@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+)
+
+// Tool error classes reported to the model.
+const (
+	ToolErrorInvalidArguments = "invalid_arguments"
+	ToolErrorUnknownTool      = "unknown_tool"
+	ToolErrorTimeout          = "timeout"
+	ToolErrorCancelled        = "cancelled"
+	ToolErrorUnavailable      = "unavailable" // Transient backend failure; retrying may succeed
+	ToolErrorPermission       = "permission_denied"
+	ToolErrorNotFound         = "not_found"
+	ToolErrorInternal         = "internal"
+)
+
+// ToolError is a structured tool failure. Tools return it (or wrap it) to
+// tell the model what went wrong and how to recover; other errors are
+// classified by ClassifyToolError. The model receives it as a JSON payload
+// instead of a flat message.
+type ToolError struct {
+	Class      string `json:"class"`
+	Message    string `json:"message"`
+	Retryable  bool   `json:"retryable"`
+	Suggestion string `json:"suggestion,omitempty"` // How to fix the call, if known
+	Err        error  `json:"-"`                    // Underlying error, if any
+}
+
+func (e *ToolError) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyToolError returns err as a *ToolError, inferring the class and
+// whether a retry may succeed from well-known errors when the tool did not
+// return one itself.
+func ClassifyToolError(err error) *ToolError {
+	if err == nil {
+		return nil
+	}
+	var te *ToolError
+	if errors.As(err, &te) {
+		c := *te
+		if c.Message == "" {
+			c.Message = te.Error()
+		}
+		if c.Class == "" {
+			c.Class = ToolErrorInternal
+		}
+		return &c
+	}
+
+	te = &ToolError{Class: ToolErrorInternal, Message: err.Error(), Err: err}
+	var netErr net.Error
+	var panicErr *PanicError
+	switch {
+	case errors.Is(err, ErrMalformedToolCall):
+		te.Class = ToolErrorInvalidArguments
+		te.Retryable = true
+		te.Suggestion = "Call the tool again with arguments that match its schema."
+		if errors.Is(err, errUnknownTool) {
+			te.Class = ToolErrorUnknownTool
+			te.Suggestion = "Use one of the available tools."
+		}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		te.Class = ToolErrorTimeout
+		te.Retryable = true
+		te.Suggestion = "Retry, or narrow the request so it completes faster."
+	case errors.Is(err, context.Canceled):
+		te.Class = ToolErrorCancelled
+	case errors.As(err, &netErr):
+		te.Class = ToolErrorUnavailable
+		te.Retryable = true
+	case errors.As(err, &panicErr):
+		te.Message = panicErr.Error() // Keep stack traces out of the prompt
+	}
+	return te
+}
+
+// toolErrorPayload renders a tool error as the JSON object sent to the model.
+func toolErrorPayload(err error) string {
+	data, jerr := json.Marshal(map[string]*ToolError{"error": ClassifyToolError(err)})
+	if jerr != nil {
+		return err.Error()
+	}
+	return string(data)
+}
@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ToolStats summarizes calls to one tool.
+type ToolStats struct {
+	Calls     int
+	Errors    int
+	ErrorRate float64
+	Avg       time.Duration
+	P50       time.Duration
+	P95       time.Duration
+	Max       time.Duration
+}
+
+// ToolMetrics accumulates per-tool call counts, errors, and latency samples
+// across runs. It is safe for concurrent use.
+type ToolMetrics struct {
+	mu      sync.Mutex
+	maxSize int
+	tools   map[string]*toolRecord
+}
+
+type toolRecord struct {
+	calls   int
+	errors  int
+	samples []time.Duration // Most recent latencies, capped at maxSize
+}
+
+// DefaultToolMetrics is the process-wide collector used by LLMAgent and
+// reported by Executor.Stats unless another collector is configured.
+var DefaultToolMetrics = NewToolMetrics()
+
+// NewToolMetrics creates a collector keeping up to 1000 latency samples per tool.
+func NewToolMetrics() *ToolMetrics {
+	return &ToolMetrics{maxSize: 1000, tools: make(map[string]*toolRecord)}
+}
+
+// Record adds one tool call.
+func (m *ToolMetrics) Record(tool string, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec, ok := m.tools[tool]
+	if !ok {
+		rec = &toolRecord{}
+		m.tools[tool] = rec
+	}
+	rec.calls++
+	if err != nil {
+		rec.errors++
+	}
+	rec.samples = append(rec.samples, latency)
+	if len(rec.samples) > m.maxSize {
+		rec.samples = rec.samples[len(rec.samples)-m.maxSize:]
+	}
+}
+
+// Snapshot returns stats for every tool seen so far.
+func (m *ToolMetrics) Snapshot() map[string]ToolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]ToolStats, len(m.tools))
+	for name, rec := range m.tools {
+		out[name] = summarizeTool(rec.calls, rec.errors, rec.samples)
+	}
+	return out
+}
+
+// Slowest returns up to n tool names ordered by descending P95 latency.
+func (m *ToolMetrics) Slowest(n int) []string {
+	stats := m.Snapshot()
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return stats[names[i]].P95 > stats[names[j]].P95 })
+	if n > 0 && len(names) > n {
+		names = names[:n]
+	}
+	return names
+}
+
+func summarizeTool(calls, errors int, samples []time.Duration) ToolStats {
+	s := ToolStats{Calls: calls, Errors: errors}
+	if calls > 0 {
+		s.ErrorRate = float64(errors) / float64(calls)
+	}
+	if len(samples) == 0 {
+		return s
+	}
+
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	s.Avg = total / time.Duration(len(sorted))
+	s.P50 = percentile(sorted, 0.50)
+	s.P95 = percentile(sorted, 0.95)
+	s.Max = sorted[len(sorted)-1]
+	return s
+}
+
+// toolStatsFromSteps summarizes the tool calls of a single execution.
+func toolStatsFromSteps(steps []ExecutionStep) map[string]ToolStats {
+	type acc struct {
+		calls, errors int
+		samples       []time.Duration
+	}
+	byTool := map[string]*acc{}
+	for _, step := range steps {
+		for _, tc := range step.ToolCalls {
+			a, ok := byTool[tc.Name]
+			if !ok {
+				a = &acc{}
+				byTool[tc.Name] = a
+			}
+			a.calls++
+			if tc.Error != nil {
+				a.errors++
+			}
+			a.samples = append(a.samples, tc.Duration)
+		}
+	}
+
+	out := make(map[string]ToolStats, len(byTool))
+	for name, a := range byTool {
+		out[name] = summarizeTool(a.calls, a.errors, a.samples)
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ParamToolsets is the Task.Params key listing tool groups to enable for a
+// task, e.g. Params["toolsets"] = []string{"github", "search"}.
+const ParamToolsets = "toolsets"
+
+// ToolRegistry holds tools by name and organizes them into named groups that
+// tasks can enable at execution time.
+type ToolRegistry struct {
+	mu     sync.RWMutex
+	tools  map[string]Tool
+	groups map[string][]string
+}
+
+// NewToolRegistry creates a new empty ToolRegistry.
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{
+		tools:  make(map[string]Tool),
+		groups: make(map[string][]string),
+	}
+}
+
+// Register adds a tool, replacing any tool with the same name, and adds it to
+// the given groups.
+func (r *ToolRegistry) Register(tool Tool, groups ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools[tool.Name()] = tool
+	for _, g := range groups {
+		r.groups[g] = append(r.groups[g], tool.Name())
+	}
+}
+
+// Get returns the tool registered under name.
+func (r *ToolRegistry) Get(name string) (Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tools[name]
+	return t, ok
+}
+
+// Groups returns the names of all groups in sorted order.
+func (r *ToolRegistry) Groups() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.groups))
+	for g := range r.groups {
+		names = append(names, g)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the tools in the named groups, without duplicates. It fails
+// if any group is unknown.
+func (r *ToolRegistry) Resolve(groups []string) ([]Tool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tools []Tool
+	seen := map[string]bool{}
+	for _, g := range groups {
+		names, ok := r.groups[g]
+		if !ok {
+			return nil, fmt.Errorf("unknown toolset %q", g)
+		}
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			tools = append(tools, r.tools[name])
+		}
+	}
+	return tools, nil
+}
+
+// requestedToolsets reads the toolset names from task params.
+func requestedToolsets(params map[string]interface{}) []string {
+	return stringList(params[ParamToolsets])
+}
+
+// mergeTools appends extra tools to base, skipping names already present.
+func mergeTools(base []Tool, extra []Tool) []Tool {
+	if len(extra) == 0 {
+		return base
+	}
+	seen := make(map[string]bool, len(base))
+	merged := make([]Tool, 0, len(base)+len(extra))
+	for _, t := range base {
+		seen[t.Name()] = true
+		merged = append(merged, t)
+	}
+	for _, t := range extra {
+		if !seen[t.Name()] {
+			seen[t.Name()] = true
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
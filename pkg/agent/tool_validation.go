@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMalformedToolCall is wrapped by errors for tool calls that name an
+// unknown tool or whose arguments fail the tool's schema.
+var ErrMalformedToolCall = errors.New("malformed tool call")
+
+// errUnknownTool marks malformed calls that name no available tool.
+var errUnknownTool = errors.New("tool not found")
+
+// validateToolCall resolves the tool a call refers to and checks its
+// arguments against the tool's schema when the schema is a JSON schema map.
+func validateToolCall(tools []Tool, tc ToolCall) (Tool, error) {
+	tool := findTool(tools, tc.Name)
+	if tool == nil {
+		return nil, fmt.Errorf("%w: %w: %s", ErrMalformedToolCall, errUnknownTool, tc.Name)
+	}
+	if schema, ok := schemaMap(tool.Schema()); ok {
+		var args interface{} = tc.Arguments
+		if tc.Arguments == nil {
+			args = map[string]interface{}{}
+		}
+		if err := ValidateSchema(schema, args); err != nil {
+			return nil, fmt.Errorf("%w: invalid arguments for %s: %v", ErrMalformedToolCall, tc.Name, err)
+		}
+	}
+	return tool, nil
+}
+
+// toolCallCorrection builds the corrective message sent after malformed tool
+// calls, listing the available tools and their argument schemas.
+func toolCallCorrection(tools []Tool, calls []ToolCall) string {
+	var b strings.Builder
+	b.WriteString("Some of your tool calls were invalid and were not executed:\n")
+	for _, tc := range calls {
+		if errors.Is(tc.Error, ErrMalformedToolCall) {
+			fmt.Fprintf(&b, "- %v\n", tc.Error)
+		}
+	}
+	b.WriteString("\nAvailable tools:\n")
+	for _, t := range tools {
+		schema, err := json.Marshal(t.Schema())
+		if err != nil {
+			schema = []byte("{}")
+		}
+		fmt.Fprintf(&b, "- %s: %s\n  arguments: %s\n", t.Name(), t.Description(), schema)
+	}
+	b.WriteString("\nRetry using only these tools with arguments that match their schemas.")
+	return b.String()
+}
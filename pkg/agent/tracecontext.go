@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// W3C Trace Context header names.
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// TraceContext is a W3C Trace Context (https://www.w3.org/TR/trace-context/):
+// the trace a request belongs to and the span that made it. Propagating it
+// to remote agents and tools keeps one user request one connected trace
+// across services.
+type TraceContext struct {
+	TraceID string // 32 lowercase hex digits
+	SpanID  string // 16 lowercase hex digits; the parent of spans started from it
+	Flags   byte   // Trace flags; bit 0 is "sampled"
+	State   string // Vendor-specific tracestate, passed through unchanged
+}
+
+// ErrInvalidTraceParent is matched by errors parsing a traceparent header.
+var ErrInvalidTraceParent = errors.New("invalid traceparent")
+
+// NewTraceContext starts a new sampled trace.
+func NewTraceContext() TraceContext {
+	return TraceContext{TraceID: randomHex(16), SpanID: randomHex(8), Flags: 1}
+}
+
+// ParseTraceParent parses a traceparent header value, e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func ParseTraceParent(s string) (TraceContext, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || parts[0] == "00" && len(parts) != 4 {
+		return TraceContext{}, fmt.Errorf("%w: %q", ErrInvalidTraceParent, s)
+	}
+	tc := TraceContext{TraceID: parts[1], SpanID: parts[2]}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 || !isHexID(tc.TraceID, 32) || !isHexID(tc.SpanID, 16) {
+		return TraceContext{}, fmt.Errorf("%w: %q", ErrInvalidTraceParent, s)
+	}
+	tc.Flags = flags[0]
+	return tc, nil
+}
+
+// isHexID reports whether s is n lowercase hex digits, not all zero.
+func isHexID(s string, n int) bool {
+	if len(s) != n || strings.Trim(s, "0") == "" {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Valid reports whether the trace and span IDs are well formed.
+func (tc TraceContext) Valid() bool {
+	return isHexID(tc.TraceID, 32) && isHexID(tc.SpanID, 16)
+}
+
+// Sampled reports whether the caller records the trace.
+func (tc TraceContext) Sampled() bool {
+	return tc.Flags&1 == 1
+}
+
+// TraceParent renders the traceparent header value.
+func (tc TraceContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", tc.TraceID, tc.SpanID, tc.Flags)
+}
+
+// Child returns the context of a new span in the same trace, for an
+// outgoing call.
+func (tc TraceContext) Child() TraceContext {
+	tc.SpanID = randomHex(8)
+	return tc
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type traceKey struct{}
+
+// ContextWithTrace returns ctx carrying tc.
+func ContextWithTrace(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, tc)
+}
+
+// TraceFromContext returns the trace context of the running request.
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceKey{}).(TraceContext)
+	return tc, ok
+}
+
+// TraceCarrier is a set of headers or metadata trace context is written to
+// and read from. http.Header implements it; MapCarrier adapts gRPC
+// metadata, message headers, and tool arguments.
+type TraceCarrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// MapCarrier is a TraceCarrier backed by a map.
+type MapCarrier map[string]string
+
+func (c MapCarrier) Get(key string) string { return c[key] }
+func (c MapCarrier) Set(key, value string) { c[key] = value }
+
+// InjectTrace writes a child span of ctx's trace to carrier, for a call to
+// a remote agent or external tool, along with the task's labels as a
+// baggage header. It does nothing if ctx has no trace.
+func InjectTrace(ctx context.Context, carrier TraceCarrier) {
+	tc, ok := TraceFromContext(ctx)
+	if !ok {
+		return
+	}
+	child := tc.Child()
+	carrier.Set(TraceParentHeader, child.TraceParent())
+	if child.State != "" {
+		carrier.Set(TraceStateHeader, child.State)
+	}
+	if labels := LabelsFromContext(ctx); len(labels) > 0 {
+		carrier.Set(BaggageHeader, baggage(labels))
+	}
+}
+
+// ExtractTrace returns ctx carrying the trace context read from carrier,
+// or ctx unchanged if carrier has none or it is malformed.
+func ExtractTrace(ctx context.Context, carrier TraceCarrier) context.Context {
+	tc, err := ParseTraceParent(carrier.Get(TraceParentHeader))
+	if err != nil {
+		return ctx
+	}
+	tc.State = carrier.Get(TraceStateHeader)
+	return ContextWithTrace(ctx, tc)
+}
+
+// TraceTransport is an http.RoundTripper that injects the trace context of
+// each request's context, so HTTP tools and providers continue the trace.
+type TraceTransport struct {
+	Base http.RoundTripper // Default http.DefaultTransport
+}
+
+func (t *TraceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if _, ok := TraceFromContext(req.Context()); !ok || req.Header.Get(TraceParentHeader) != "" {
+		return base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	InjectTrace(req.Context(), req.Header)
+	return base.RoundTrip(req)
+}
+
+// WithTraceParent continues the trace of a traceparent header (and its
+// tracestate) for the call, e.g. from an incoming HTTP request. Async jobs
+// run with it in their context.
+func WithTraceParent(traceparent, tracestate string) CallOption {
+	return func(c *ExecutionConfig) {
+		c.TraceParent = traceparent
+		c.TraceState = tracestate
+	}
+}
+
+// withTrace adds the trace context of cfg to ctx. Without one, ctx keeps
+// its own trace or, if it has none, a new trace is started. The trace ID is
+// recorded in the result metadata by recordTrace.
+func withTrace(ctx context.Context, cfg *ExecutionConfig) context.Context {
+	if cfg != nil && cfg.TraceParent != "" {
+		if tc, err := ParseTraceParent(cfg.TraceParent); err == nil {
+			tc.State = cfg.TraceState
+			return ContextWithTrace(ctx, tc)
+		}
+	}
+	if _, ok := TraceFromContext(ctx); ok {
+		return ctx
+	}
+	return ContextWithTrace(ctx, NewTraceContext())
+}
+
+// recordTrace stores ctx's trace ID in Metadata["trace_id"].
+func recordTrace(ctx context.Context, result *Result) {
+	if tc, ok := TraceFromContext(ctx); ok && result != nil && result.Metadata != nil {
+		result.Metadata["trace_id"] = tc.TraceID
+	}
+}
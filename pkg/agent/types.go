@@ -3,7 +3,9 @@
 package agent
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"time"
 )
 
@@ -21,6 +23,73 @@ type FileInput struct {
 	Content  []byte      // Raw file content
 	URI      string      // Optional URI if file is referenced by path
 	Metadata interface{} // Additional metadata about the file
+
+	// ContentReader streams large content instead of buffering it in
+	// Content. Use an io.ReadSeeker: it is rewound for every request, while
+	// other readers are buffered before the first model call unless the
+	// model uploads them (see FileUploader). Size is its length in bytes
+	// (0 if unknown).
+	ContentReader io.Reader
+	Size          int64
+}
+
+// Reader returns the file content as a stream, from ContentReader (rewound
+// if it can seek) if set, else from Content.
+func (f FileInput) Reader() io.Reader {
+	if f.ContentReader != nil {
+		if s, ok := f.ContentReader.(io.Seeker); ok {
+			s.Seek(0, io.SeekStart)
+		}
+		return f.ContentReader
+	}
+	return bytes.NewReader(f.Content)
+}
+
+// Bytes returns the file content, reading ContentReader fully if set. Use it
+// only in providers without a streaming upload path.
+func (f FileInput) Bytes() ([]byte, error) {
+	if f.ContentReader == nil {
+		return f.Content, nil
+	}
+	return io.ReadAll(f.Reader())
+}
+
+// Len returns the content length in bytes, or -1 if a streamed file has no
+// known size.
+func (f FileInput) Len() int64 {
+	if f.ContentReader != nil {
+		if f.Size == 0 {
+			return -1
+		}
+		return f.Size
+	}
+	return int64(len(f.Content))
+}
+
+// buffered reads a ContentReader that cannot seek into Content. Such a
+// reader is drained by the first request, and later turns would send an
+// empty file.
+func (f FileInput) buffered() (FileInput, error) {
+	if f.ContentReader == nil {
+		return f, nil
+	}
+	if _, ok := f.ContentReader.(io.Seeker); ok {
+		return f, nil
+	}
+	content, err := io.ReadAll(f.ContentReader)
+	if err != nil {
+		return f, err
+	}
+	f.Content, f.ContentReader = content, nil
+	return f, nil
+}
+
+// FileUploader is implemented by providers that can upload file content
+// ahead of a completion (multipart or resumable uploads). LLMAgent uploads
+// streamed files through it and passes the returned URI instead of the
+// content.
+type FileUploader interface {
+	UploadFile(ctx context.Context, file FileInput) (uri string, err error)
 }
 
 // Task represents a unit of work (API-triggered).
@@ -33,17 +102,26 @@ type Task struct {
 	Config      *ExecutionConfig
 	StartedAt   time.Time
 	CompletedAt time.Time
+
+	lineage *lineage // Which tool call wrote each state key
 }
 
 // Result is the final output of an agent execution.
 type Result struct {
-	TaskID        string
-	Success       bool
-	Output        interface{}            // Final result (can be struct, string, map)
-	Artifacts     []Artifact             // Generated files, images, etc.
-	Metadata      map[string]interface{} // Processing metadata
-	Error         string
-	Steps         []ExecutionStep        // Audit trail
+	TaskID    string
+	Success   bool
+	Output    interface{}            // Final result (can be struct, string, map)
+	Outputs   map[string]interface{} // Named outputs: the output schema's top-level properties (see OutputString etc.)
+	Artifacts []Artifact             // Generated files, images, etc.
+	Metadata  map[string]interface{} // Processing metadata
+	Error     string
+	Steps     []ExecutionStep // Audit trail
+	Actions   *EventActions   // Control flow actions raised during execution (e.g., escalation)
+
+	// Set when execution failed part-way: Steps and Artifacts hold what was
+	// completed and State is a snapshot of the last good task state.
+	Partial bool
+	State   map[string]interface{}
 
 	// Aggregated metrics
 	TotalLLMLatency   time.Duration // Total time spent on LLM calls across all steps
@@ -53,18 +131,23 @@ type Result struct {
 
 // ExecutionStep tracks what happened during a single turn.
 type ExecutionStep struct {
-	AgentName       string
-	Action          string
-	Input           interface{}
-	Output          interface{}
-	Error           string
-	Duration        time.Duration // Total step duration (LLM + tools)
-	LLMLatency      time.Duration // Time spent on LLM call
-	ToolsLatency    time.Duration // Time spent on tool execution (sum of all tools)
-	Timestamp       time.Time
-	TokenUsage      *TokenUsage // Token usage for LLM call in this step
-	ToolCalls       []ToolCall
-	StateDelta      map[string]interface{}
+	AgentName    string
+	AgentPath    string // Hierarchical path of the agent, e.g. "root/researcher"
+	Action       string
+	Input        interface{}
+	Output       interface{}
+	Error        string
+	Duration     time.Duration // Total step duration (LLM + tools)
+	LLMLatency   time.Duration // Time spent on LLM call
+	ToolsLatency time.Duration // Time spent on tool execution (sum of all tools)
+	Timestamp    time.Time
+	TokenUsage   *TokenUsage // Token usage for LLM call in this step
+	Reasoning    string      // Model reasoning; only recorded when the agent opts in
+	ToolCalls    []ToolCall
+	StateDelta   map[string]interface{}
+	Model        string       // Model that served the step, when the provider reports it
+	Routing      string       // Model routing decision for the step, if any
+	FinishReason FinishReason // Why the model stopped generating, if known
 }
 
 // ExecutionConfig controls how a task is executed.
@@ -74,11 +157,42 @@ type ExecutionConfig struct {
 	Temperature    float32
 	EnablePlan     bool
 	CallbackURL    string // For async notifications
+
+	// StreamingMode controls whether partial content is forwarded while the
+	// model is generating. Requires a StreamingModelProvider.
+	StreamingMode StreamingMode
+	OnEvent       EventHandler // Receives events as they happen; must be safe for concurrent use
+
+	// UserID identifies the caller, e.g. for tenant resolution. It is never
+	// read from JSON so clients cannot claim another identity.
+	UserID string `json:"-"`
+
+	// Tools are added to every LLMAgent's tools for this call only, e.g.
+	// closures over the current user or session. They take precedence over
+	// agent tools of the same name.
+	Tools []Tool `json:"-"`
+
+	// Model overrides the model name sent to the provider (see
+	// CompletionRequest.Model), taking precedence over a tenant's
+	// ModelName. It is never read from JSON so clients cannot pick a
+	// costlier model.
+	Model string `json:"-"`
+
+	// TraceParent and TraceState continue a W3C trace, e.g. from the
+	// headers of the request that submitted the task (see WithTraceParent).
+	TraceParent string `json:"-"`
+	TraceState  string `json:"-"`
+
+	// Labels tag the task with arbitrary dimensions (team, feature,
+	// environment), propagated to Result.Metadata["labels"], job events,
+	// sink records, and outgoing baggage headers; Executor.ListJobs and
+	// UsageByLabel filter and group by them.
+	Labels map[string]string
 }
 
 // Artifact represents generated content (files, images, etc.).
 type Artifact struct {
-	Type     string      // "text", "image", "video", "code", etc.
+	Type     string // "text", "image", "video", "code", etc.
 	MimeType string
 	Content  interface{} // Content or reference
 	Metadata map[string]interface{}
@@ -112,6 +226,19 @@ type CompletionRequest struct {
 	OutputSchema map[string]interface{} // Optional JSON schema for structured output (nil = unstructured)
 	Temperature  *float32               // Optional sampling temperature (nil = use provider default)
 	MaxTokens    *int                   // Optional max completion tokens (nil = use provider default)
+	Constraints  *DecodingConstraints   // Optional constrained decoding (nil = unconstrained)
+	Model        string                 // Optional model name (empty = provider default), e.g. set per tenant
+}
+
+// DecodingConstraints restrict what a backend may generate, guaranteeing the
+// shape of structured output instead of validating it afterwards. Providers
+// pass through the options their backend supports (e.g. GBNF grammars for
+// llama.cpp, regex and JSON guides for vLLM, strict mode for OpenAI) and
+// ignore the rest.
+type DecodingConstraints struct {
+	Grammar      string // GBNF grammar the output must match
+	Regex        string // Regular expression the output must match
+	StrictSchema bool   // Enforce OutputSchema and tool schemas exactly (strict JSON schema mode)
 }
 
 // ModelProvider interfaces with LLM backends.
@@ -119,32 +246,84 @@ type ModelProvider interface {
 	Complete(ctx context.Context, req *CompletionRequest) (*ModelResponse, error)
 }
 
+// HealthChecker is implemented by providers that can verify their backend is
+// reachable without running a full completion.
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
+
+// StreamingModelProvider is implemented by providers that can stream partial
+// content while a completion is being generated.
+type StreamingModelProvider interface {
+	ModelProvider
+	// CompleteStream behaves like Complete but invokes onDelta for each partial
+	// content chunk. The returned response must hold the complete content and
+	// any tool calls.
+	CompleteStream(ctx context.Context, req *CompletionRequest, onDelta func(delta string)) (*ModelResponse, error)
+}
+
 // TokenUsage tracks token consumption for LLM calls (model-agnostic).
 type TokenUsage struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+	ReasoningTokens  int `json:"reasoning_tokens"` // Thinking tokens, when the provider reports them separately
 }
 
 // ModelResponse is the response from an LLM.
 type ModelResponse struct {
 	Content   string
 	ToolCalls []ToolCall
-	Reasoning string
+	Reasoning string // Reasoning/thinking content from reasoning models (provider-dependent)
 	Finished  bool
 	Usage     *TokenUsage // Token usage metadata (provider-dependent)
+	Model     string      // Model that produced the response, for routing providers (optional)
+	Routing   string      // Why that model was chosen, e.g. a cascade escalation (optional)
+
+	// FinishReason says why generation stopped, mapped from the provider's
+	// value with ParseFinishReason (optional; see Reason).
+	FinishReason FinishReason
+}
+
+// Role identifies the author of a message.
+type Role string
+
+const (
+	RoleSystem    Role = "system"    // Instructions; at most one, first in the history
+	RoleUser      Role = "user"      // User input, and tool results for providers without a tool role
+	RoleAssistant Role = "assistant" // Model output, including tool call requests
+	RoleTool      Role = "tool"      // Results of the preceding assistant message's tool calls
+)
+
+// Valid reports whether r is one of the defined roles.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleSystem, RoleUser, RoleAssistant, RoleTool:
+		return true
+	}
+	return false
 }
 
 // Message represents a conversation message.
 type Message struct {
-	Role    string
+	Role    Role
 	Content string
 	Parts   []Part
+
+	// ToolCalls are the calls an assistant message requested, with the IDs
+	// their results refer to. Providers with native function calling send
+	// them back as structured calls.
+	ToolCalls []ToolCall
+
+	// ToolCallID is the ID of the call a RoleTool message holds the result
+	// of, for the provider's tool result message (e.g. OpenAI's
+	// tool_call_id, Anthropic's tool_use_id).
+	ToolCallID string
 }
 
 // Part represents a segment of a multimodal message.
 type Part struct {
 	Type string
 	Text string
-	Data interface{}
+	Data interface{} // []byte content, the FileInput of a streamed file, or a string URI for uploaded files
 }
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// UsageReport is the running token usage and cost of a task. It is emitted
+// as an EventUsage after every model call, so clients can show a live cost
+// meter during long executions, and read with Executor.Usage.
+type UsageReport struct {
+	Step      TokenUsage `json:"step"` // Usage of the model call that triggered the report
+	StepCost  float64    `json:"step_cost"`
+	Total     TokenUsage `json:"total"` // Usage of the task so far, across all of its agents
+	Cost      float64    `json:"cost"`  // USD so far, for models the Pricing knows
+	Calls     int        `json:"calls"` // Model calls so far
+	Model     string     `json:"model,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// usageMeter totals the usage of the task executing under a context.
+type usageMeter struct {
+	task    *Task
+	pricing *Pricing
+	onSet   func(*UsageReport)
+
+	mu     sync.Mutex
+	report UsageReport
+}
+
+type usageKey struct{}
+
+// withUsage installs a meter for task unless ctx already has one, so nested
+// agents report on the outermost task. A nil pricing uses DefaultPricing.
+func withUsage(ctx context.Context, task *Task, pricing *Pricing, onSet func(*UsageReport)) context.Context {
+	if _, ok := ctx.Value(usageKey{}).(*usageMeter); ok {
+		return ctx
+	}
+	if pricing == nil {
+		pricing = DefaultPricing
+	}
+	return context.WithValue(ctx, usageKey{}, &usageMeter{task: task, pricing: pricing, onSet: onSet})
+}
+
+// meterStep adds the token usage of a recorded step to the running totals
+// and emits the updated report.
+func meterStep(ctx context.Context, step ExecutionStep) {
+	m, ok := ctx.Value(usageKey{}).(*usageMeter)
+	if !ok || step.TokenUsage == nil {
+		return
+	}
+	var cost float64
+	if price, ok := m.pricing.Price(step.Model); ok {
+		cost = price.Cost(*step.TokenUsage)
+	}
+
+	m.mu.Lock()
+	m.report.Step = *step.TokenUsage
+	m.report.StepCost = cost
+	m.report.Total = addUsage(m.report.Total, *step.TokenUsage)
+	m.report.Cost += cost
+	m.report.Calls++
+	m.report.Model = step.Model
+	m.report.UpdatedAt = Now(ctx)
+	report := m.report
+	m.mu.Unlock()
+
+	if m.onSet != nil {
+		m.onSet(&report)
+	}
+	ev := newEvent(ctx, m.task.ID, step.AgentName, EventUsage)
+	ev.AgentPath = step.AgentPath
+	ev.Usage = step.TokenUsage
+	ev.Model = step.Model
+	ev.UsageReport = &report
+	emit(m.task, ev)
+}
+
+// WithPricing sets the prices of the running cost in usage reports
+// (default DefaultPricing).
+func WithPricing(p *Pricing) ExecutorOption {
+	return func(e *Executor) {
+		e.pricing = p
+	}
+}
+
+// Usage returns the running token usage and cost of a job, or nil before
+// its first model call.
+func (e *Executor) Usage(taskID string) (*UsageReport, error) {
+	job, ok := e.jobs.get(taskID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, taskID)
+	}
+	return job.usage.Load(), nil
+}
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Warmer is implemented by agents, tools, and model providers with expensive
+// initialization (opening DB pools, prefetching embeddings, priming caches)
+// that should run at startup rather than on the first request. Warmup may be
+// called more than once and must be safe for concurrent use.
+type Warmer interface {
+	Warmup(ctx context.Context) error
+}
+
+// ErrWarmingUp is reported by Executor.WarmupErr while warmup is running.
+var ErrWarmingUp = errors.New("executor is warming up")
+
+// warmupState tracks the Executor's warmup and gates its workers.
+type warmupState struct {
+	mu      sync.Mutex
+	running bool
+	err     error
+	gate    chan struct{} // Closed when the WithWarmup run finishes; nil without it
+}
+
+// WithWarmup runs Executor.Warmup in the background when the Executor is
+// created. Workers hold queued jobs until it finishes (or timeout elapses,
+// 0 = no limit), and Ready reports false until it succeeds.
+func WithWarmup(timeout time.Duration) ExecutorOption {
+	return func(e *Executor) {
+		e.warmup.gate = make(chan struct{})
+		e.warmup.running = true
+		e.warmupTimeout = timeout
+	}
+}
+
+// startWarmup runs the WithWarmup warmup and then releases the workers.
+func (e *Executor) startWarmup() {
+	ctx := context.Background()
+	if e.warmupTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.warmupTimeout)
+		defer cancel()
+	}
+	e.Warmup(ctx)
+	close(e.warmup.gate)
+}
+
+// Warmup calls Warmup concurrently on every Warmer reachable from the
+// Executor's agent: agents in the tree (through decorators), and the models
+// and tools of LLMAgents. Shared components are warmed once. Errors are
+// joined, each prefixed with the component's type.
+func (e *Executor) Warmup(ctx context.Context) error {
+	e.warmup.mu.Lock()
+	e.warmup.running = true
+	e.warmup.mu.Unlock()
+
+	warmers := collectWarmers(e.agent)
+	errs := make([]error, len(warmers))
+	var wg sync.WaitGroup
+	for i, w := range warmers {
+		wg.Add(1)
+		go func(i int, w Warmer) {
+			defer wg.Done()
+			defer recoverPanic(&errs[i])
+			if err := w.Warmup(ctx); err != nil {
+				errs[i] = fmt.Errorf("%T: %w", w, err)
+			}
+		}(i, w)
+	}
+	wg.Wait()
+	err := errors.Join(errs...)
+
+	e.warmup.mu.Lock()
+	e.warmup.running = false
+	e.warmup.err = err
+	e.warmup.mu.Unlock()
+	return err
+}
+
+// WarmupErr returns ErrWarmingUp while warmup is running, the error of the
+// last warmup if it failed, or nil.
+func (e *Executor) WarmupErr() error {
+	e.warmup.mu.Lock()
+	defer e.warmup.mu.Unlock()
+	if e.warmup.running {
+		return ErrWarmingUp
+	}
+	return e.warmup.err
+}
+
+// Ready reports whether the Executor is warm: no warmup is running and the
+// last one succeeded. Use it for readiness probes.
+func (e *Executor) Ready() bool {
+	return e.WarmupErr() == nil
+}
+
+// collectWarmers walks an agent tree and returns each distinct Warmer.
+func collectWarmers(root Agent) []Warmer {
+	var out []Warmer
+	seen := map[interface{}]bool{}
+	add := func(v interface{}) {
+		w, ok := v.(Warmer)
+		if !ok {
+			return
+		}
+		if reflect.TypeOf(v).Comparable() {
+			if seen[v] {
+				return
+			}
+			seen[v] = true
+		}
+		out = append(out, w)
+	}
+
+	visited := map[interface{}]bool{}
+	var walk func(ag Agent)
+	walk = func(ag Agent) {
+		if ag == nil {
+			return
+		}
+		if reflect.TypeOf(ag).Comparable() {
+			if visited[ag] {
+				return
+			}
+			visited[ag] = true
+		}
+		add(ag)
+		switch a := ag.(type) {
+		case *LLMAgent:
+			add(a.model)
+			for _, t := range a.tools {
+				add(t)
+			}
+			if a.registry != nil {
+				tools, _ := a.registry.Resolve(a.registry.Groups())
+				for _, t := range tools {
+					add(t)
+				}
+			}
+		case *modelOverride:
+			add(a.model)
+		}
+		if w, ok := ag.(Wrapper); ok {
+			walk(w.Unwrap())
+			return
+		}
+		for _, sub := range ag.SubAgents() {
+			walk(sub)
+		}
+	}
+	walk(root)
+	return out
+}
+
+// Warmup warms every tier's model.
+func (m *CascadeModel) Warmup(ctx context.Context) error {
+	var errs []error
+	for _, tier := range m.cfg.Tiers {
+		if w, ok := tier.Model.(Warmer); ok {
+			if err := w.Warmup(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("tier %s: %w", tier.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
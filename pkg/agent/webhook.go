@@ -0,0 +1,342 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook is a generated URL an external system calls to notify an agent,
+// e.g. when a build finishes or a payment settles.
+type Webhook struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at,omitempty"` // Zero for webhooks that do not expire
+}
+
+// WebhookDelivery is one call of a webhook URL.
+type WebhookDelivery struct {
+	WebhookID  string            `json:"webhook_id"`
+	Payload    interface{}       `json:"payload"` // Decoded JSON body, else the body as a string
+	Headers    map[string]string `json:"headers,omitempty"`
+	ReceivedAt time.Time         `json:"received_at"`
+}
+
+// ErrWebhookNotFound is returned for unknown or expired webhooks.
+var ErrWebhookNotFound = &jobStateError{msg: "webhook not found", status: http.StatusNotFound}
+
+// ErrWebhookDelivered is returned when a one-shot webhook is called again
+// before its delivery was consumed.
+var ErrWebhookDelivered = &jobStateError{msg: "webhook already delivered", status: http.StatusConflict}
+
+// DefaultWebhookHeaders are the request headers deliveries carry by
+// default: content metadata and the event headers common providers send.
+var DefaultWebhookHeaders = []string{
+	"Content-Type",
+	"User-Agent",
+	"X-Request-Id",
+	"X-GitHub-Event",
+	"X-GitHub-Delivery",
+	"X-Gitlab-Event",
+}
+
+// WebhookConfig configures a Webhooks receiver.
+type WebhookConfig struct {
+	// BaseURL is the public URL the receiver is served under; webhook URLs
+	// are BaseURL + "/" + ID, e.g. "https://agents.example.com/webhooks".
+	BaseURL string
+
+	// TTL is how long webhooks created for waiting agents stay valid
+	// (default 24h).
+	TTL time.Duration
+
+	// MaxBody caps accepted request bodies (default 1 MiB).
+	MaxBody int64
+
+	// Verify authenticates a call, e.g. by checking an HMAC signature
+	// header against body. Rejected calls get 401 (optional).
+	Verify func(r *http.Request, body []byte) error
+
+	// Headers lists the request headers copied into deliveries (default
+	// DefaultWebhookHeaders). Deliveries reach the model, task params,
+	// sinks, and audit logs, so never list credentials such as
+	// Authorization, Cookie, or signature headers.
+	Headers []string
+
+	// IDs and Clock generate webhook IDs and tell the time for creation,
+	// expiry, and delivery timestamps (default UUIDs and SystemClock).
+	IDs   IDGenerator
+	Clock Clock
+}
+
+// Webhooks is a managed webhook receiver and an http.Handler for it. Agents
+// create webhooks and wait for them to be called (see NewWebhookTools), and
+// Trigger registers webhooks that start work when called, e.g. submitting a
+// task with SubmitOnWebhook. Serve it on BaseURL:
+//
+//	mux.Handle("POST /webhooks/{id}", hooks)
+type Webhooks struct {
+	cfg   WebhookConfig
+	mu    sync.Mutex
+	hooks map[string]*webhookEntry
+}
+
+type webhookEntry struct {
+	hook       Webhook
+	deliveries chan WebhookDelivery // One-shot webhooks waited on by agents
+	trigger    func(ctx context.Context, d WebhookDelivery) error
+}
+
+// NewWebhooks creates a receiver from the given configuration.
+func NewWebhooks(cfg WebhookConfig) *Webhooks {
+	if cfg.TTL == 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.MaxBody == 0 {
+		cfg.MaxBody = 1 << 20
+	}
+	if cfg.IDs == nil {
+		cfg.IDs = UUIDs
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = SystemClock
+	}
+	if cfg.Headers == nil {
+		cfg.Headers = DefaultWebhookHeaders
+	}
+	return &Webhooks{cfg: cfg, hooks: make(map[string]*webhookEntry)}
+}
+
+func (w *Webhooks) newHook(description string, ttl time.Duration) Webhook {
+	id := w.cfg.IDs.NewID()
+	hook := Webhook{
+		ID:          id,
+		URL:         strings.TrimRight(w.cfg.BaseURL, "/") + "/" + id,
+		Description: description,
+		CreatedAt:   w.cfg.Clock.Now(),
+	}
+	if ttl > 0 {
+		hook.ExpiresAt = hook.CreatedAt.Add(ttl)
+	}
+	return hook
+}
+
+// Create registers a one-shot webhook whose delivery is received with Wait.
+// It expires after WebhookConfig.TTL.
+func (w *Webhooks) Create(description string) Webhook {
+	entry := &webhookEntry{hook: w.newHook(description, w.cfg.TTL), deliveries: make(chan WebhookDelivery, 1)}
+	w.mu.Lock()
+	w.hooks[entry.hook.ID] = entry
+	w.mu.Unlock()
+	return entry.hook
+}
+
+// Trigger registers a webhook that calls fn on every delivery, until it is
+// deleted. fn's error fails the call: with the status of its StatusCode
+// method if it has one, else 500.
+func (w *Webhooks) Trigger(description string, fn func(ctx context.Context, d WebhookDelivery) error) Webhook {
+	entry := &webhookEntry{hook: w.newHook(description, 0), trigger: fn}
+	w.mu.Lock()
+	w.hooks[entry.hook.ID] = entry
+	w.mu.Unlock()
+	return entry.hook
+}
+
+// SubmitOnWebhook registers a webhook that submits a task to e on every
+// delivery, with the delivery in Params["webhook"].
+func (w *Webhooks) SubmitOnWebhook(e *Executor, description, input string, config *ExecutionConfig) Webhook {
+	return w.Trigger(description, func(ctx context.Context, d WebhookDelivery) error {
+		_, err := e.Submit(input, map[string]interface{}{"webhook": d}, config)
+		return err
+	})
+}
+
+// Get returns a registered webhook.
+func (w *Webhooks) Get(id string) (Webhook, error) {
+	entry, err := w.entry(id)
+	if err != nil {
+		return Webhook{}, err
+	}
+	return entry.hook, nil
+}
+
+// Delete unregisters a webhook; later calls of its URL get 404.
+func (w *Webhooks) Delete(id string) {
+	w.mu.Lock()
+	delete(w.hooks, id)
+	w.mu.Unlock()
+}
+
+// entry returns a live webhook, removing it if it has expired.
+func (w *Webhooks) entry(id string) (*webhookEntry, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	entry, ok := w.hooks[id]
+	if ok && !entry.hook.ExpiresAt.IsZero() && w.cfg.Clock.Now().After(entry.hook.ExpiresAt) {
+		delete(w.hooks, id)
+		ok = false
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrWebhookNotFound, id)
+	}
+	return entry, nil
+}
+
+// Wait blocks until a webhook created with Create is called and returns the
+// delivery; the webhook is then removed. It fails if ctx is done first or
+// the webhook expires.
+func (w *Webhooks) Wait(ctx context.Context, id string) (*WebhookDelivery, error) {
+	entry, err := w.entry(id)
+	if err != nil {
+		return nil, err
+	}
+	if entry.deliveries == nil {
+		return nil, fmt.Errorf("webhook %s is a trigger and cannot be waited on", id)
+	}
+	expired := time.NewTimer(entry.hook.ExpiresAt.Sub(w.cfg.Clock.Now()))
+	defer expired.Stop()
+
+	select {
+	case d := <-entry.deliveries:
+		w.Delete(id)
+		return &d, nil
+	case <-expired.C:
+		w.Delete(id)
+		return nil, fmt.Errorf("%w: %s expired", ErrWebhookNotFound, id)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for webhook: %w", ctx.Err())
+	}
+}
+
+// Deliver hands a delivery to the webhook, as a call of its URL does.
+func (w *Webhooks) Deliver(ctx context.Context, d WebhookDelivery) error {
+	entry, err := w.entry(d.WebhookID)
+	if err != nil {
+		return err
+	}
+	if d.ReceivedAt.IsZero() {
+		d.ReceivedAt = w.cfg.Clock.Now()
+	}
+	if entry.trigger != nil {
+		return entry.trigger(ctx, d)
+	}
+	select {
+	case entry.deliveries <- d:
+		return nil
+	default:
+		return ErrWebhookDelivered
+	}
+}
+
+// ServeHTTP receives webhook calls. The webhook ID is the {id} path value,
+// or else the last path segment.
+func (w *Webhooks) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		id = path.Base(r.URL.Path)
+	}
+	if _, err := w.entry(id); err != nil {
+		writeWebhookError(rw, http.StatusNotFound, err)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, w.cfg.MaxBody))
+	if err != nil {
+		writeWebhookError(rw, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	if w.cfg.Verify != nil {
+		if err := w.cfg.Verify(r, body); err != nil {
+			writeWebhookError(rw, http.StatusUnauthorized, err)
+			return
+		}
+	}
+
+	d := WebhookDelivery{WebhookID: id, Headers: make(map[string]string), ReceivedAt: w.cfg.Clock.Now()}
+	for _, k := range w.cfg.Headers {
+		if v := r.Header.Get(k); v != "" {
+			d.Headers[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+	var payload interface{}
+	if len(body) > 0 && json.Unmarshal(body, &payload) == nil {
+		d.Payload = payload
+	} else {
+		d.Payload = string(body)
+	}
+
+	if err := w.Deliver(r.Context(), d); err != nil {
+		status := http.StatusInternalServerError
+		var sc interface{ StatusCode() int }
+		if errors.As(err, &sc) {
+			status = sc.StatusCode()
+		}
+		writeWebhookError(rw, status, err)
+		return
+	}
+	rw.WriteHeader(http.StatusAccepted)
+}
+
+func writeWebhookError(rw http.ResponseWriter, status int, err error) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	json.NewEncoder(rw).Encode(map[string]string{"error": err.Error()})
+}
+
+// createWebhookSchema and waitForWebhookSchema are the parameter schemas of
+// the webhook tools.
+var (
+	createWebhookSchema = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"description": map[string]interface{}{"type": "string", "description": "What the webhook is for, e.g. \"CI build 1234 finished\""},
+		},
+	}
+	waitForWebhookSchema = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id": map[string]interface{}{"type": "string", "description": "ID returned by create_webhook"},
+		},
+		"required": []string{"id"},
+	}
+)
+
+// NewWebhookTools returns a "create_webhook" tool, which registers a
+// one-shot webhook and returns its URL for the model to hand to an external
+// system, and a "wait_for_webhook" tool, which blocks until that URL is
+// called and returns the delivery. Waiting is bounded by the task timeout
+// and the webhook TTL.
+func NewWebhookTools(w *Webhooks) []Tool {
+	create := NewFuncTool("create_webhook",
+		"Create a callback URL that an external system can call when a long-running process finishes. Returns the webhook id and url.",
+		createWebhookSchema,
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			description, _ := args["description"].(string)
+			hook := w.Create(description)
+			return map[string]interface{}{"id": hook.ID, "url": hook.URL, "expires_at": hook.ExpiresAt}, nil
+		})
+	wait := NewFuncTool("wait_for_webhook",
+		"Wait until a webhook created with create_webhook is called. Returns the payload it was called with.",
+		waitForWebhookSchema,
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			id, _ := args["id"].(string)
+			if id == "" {
+				return nil, errors.New("id is required")
+			}
+			d, err := w.Wait(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return d, nil
+		})
+	return []Tool{create, wait}
+}
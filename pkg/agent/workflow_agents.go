@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"sync"
-	"time"
 )
 
 // SequentialAgent executes a list of agents in order, passing accumulated
@@ -12,11 +11,12 @@ import (
 type SequentialAgent struct {
 	name   string
 	agents []Agent
+	opts   workflowOptions
 }
 
 // NewSequentialAgent creates a new SequentialAgent that runs agents in order.
-func NewSequentialAgent(name string, agents []Agent) *SequentialAgent {
-	return &SequentialAgent{name: name, agents: agents}
+func NewSequentialAgent(name string, agents []Agent, opts ...WorkflowOption) *SequentialAgent {
+	return &SequentialAgent{name: name, agents: agents, opts: newWorkflowOptions(opts)}
 }
 
 func (a *SequentialAgent) Name() string {
@@ -28,41 +28,70 @@ func (a *SequentialAgent) SubAgents() []Agent {
 }
 
 func (a *SequentialAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
+	ctx = EnterAgent(ctx, a.name)
 	result := &Result{
-		TaskID:  task.ID,
-		Success: false,
-		Steps:   []ExecutionStep{},
+		TaskID:   task.ID,
+		Success:  false,
+		Metadata: make(map[string]interface{}),
+		Steps:    []ExecutionStep{},
 	}
 
-	for _, ag := range a.agents {
-		stepStart := time.Now()
+	var completed []completedStage
 
-		subResult, err := ag.Execute(ctx, task)
+	for i := resumeAt(ctx, task, result); i < len(a.agents); i++ {
+		ag := a.agents[i]
+		stepStart := Now(ctx)
+
+		subResult, err := a.opts.runStage(enterStage(ctx, i, task.Input, result), a.name, i, ag, task)
 
 		// Record step
 		step := ExecutionStep{
 			AgentName: ag.Name(),
 			Action:    "execute",
-			Duration:  time.Since(stepStart),
+			Duration:  Since(ctx, stepStart),
 			Timestamp: stepStart,
 		}
 
 		if err != nil {
 			step.Error = err.Error()
-			result.Steps = append(result.Steps, step)
+			result.mergePartial(ctx, subResult)
+			recordStep(ctx, task, result, step)
 			result.Error = fmt.Sprintf("agent %s failed: %v", ag.Name(), err)
+			compensate(ctx, task, result, completed)
+			result.markPartial(task.State)
 			return result, err
 		}
 
 		// Merge steps and state
-		result.Steps = append(result.Steps, subResult.Steps...)
+		result.appendSteps(ctx, subResult.Steps)
+
+		// Stop the sequence and bubble up if the agent escalated
+		if propagateEscalation(result, subResult) {
+			result.aggregateMetrics()
+			return result, nil
+		}
+
+		if stageSkipped(subResult) {
+			a.opts.saveCheckpoint(ctx, i, ag, task, result, task.Input)
+			continue
+		}
+		if err := checkStageOutput(ag, nil, subResult.Output); err != nil {
+			result.Error = err.Error()
+			compensate(ctx, task, result, append(completed, completedStage{ag, subResult}))
+			result.markPartial(task.State)
+			return result, err
+		}
 
 		// Last agent's output is final
 		result.Output = subResult.Output
+		result.Outputs = subResult.Outputs
 		result.Artifacts = append(result.Artifacts, subResult.Artifacts...)
+		completed = append(completed, completedStage{ag, subResult})
+		a.opts.saveCheckpoint(ctx, i, ag, task, result, task.Input)
 	}
 
 	result.Success = true
+	result.aggregateMetrics()
 	return result, nil
 }
 
@@ -71,11 +100,15 @@ func (a *SequentialAgent) Execute(ctx context.Context, task *Task) (*Result, err
 type ParallelAgent struct {
 	name   string
 	agents []Agent
+	opts   workflowOptions
 }
 
 // NewParallelAgent creates a new ParallelAgent that runs agents concurrently.
-func NewParallelAgent(name string, agents []Agent) *ParallelAgent {
-	return &ParallelAgent{name: name, agents: agents}
+// WithStageCache applies to each branch. WithCheckpoints has no effect:
+// branches finish in any order, so there is no stage position to resume
+// from; checkpoint the fan-out as one stage of an enclosing workflow.
+func NewParallelAgent(name string, agents []Agent, opts ...WorkflowOption) *ParallelAgent {
+	return &ParallelAgent{name: name, agents: agents, opts: newWorkflowOptions(opts)}
 }
 
 func (a *ParallelAgent) Name() string {
@@ -87,10 +120,12 @@ func (a *ParallelAgent) SubAgents() []Agent {
 }
 
 func (a *ParallelAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
+	ctx = EnterAgent(ctx, a.name)
 	result := &Result{
-		TaskID:  task.ID,
-		Success: false,
-		Steps:   []ExecutionStep{},
+		TaskID:   task.ID,
+		Success:  false,
+		Metadata: make(map[string]interface{}),
+		Steps:    []ExecutionStep{},
 	}
 
 	type agentResult struct {
@@ -101,6 +136,9 @@ func (a *ParallelAgent) Execute(ctx context.Context, task *Task) (*Result, error
 	results := make([]agentResult, len(a.agents))
 	var wg sync.WaitGroup
 
+	// Create the lineage map before copying so all children share it
+	task.lineageMap()
+
 	for i, ag := range a.agents {
 		wg.Add(1)
 		go func(idx int, ag Agent) {
@@ -113,8 +151,11 @@ func (a *ParallelAgent) Execute(ctx context.Context, task *Task) (*Result, error
 				taskCopy.State[k] = v
 			}
 
-			res, err := ag.Execute(ctx, &taskCopy)
-			results[idx] = agentResult{result: res, err: err}
+			var res *Result
+			var err error
+			defer func() { results[idx] = agentResult{result: res, err: err} }()
+			defer recoverPanic(&err)
+			res, err = a.opts.runStage(ctx, a.name, idx, ag, &taskCopy)
 		}(i, ag)
 	}
 
@@ -123,17 +164,31 @@ func (a *ParallelAgent) Execute(ctx context.Context, task *Task) (*Result, error
 	// Merge results
 	outputs := make(map[string]interface{})
 
+	var firstErr error
 	for i, res := range results {
 		if res.err != nil {
-			result.Error = fmt.Sprintf("agent %s failed: %v", a.agents[i].Name(), res.err)
-			return result, res.err
+			if firstErr == nil {
+				firstErr = res.err
+				result.Error = fmt.Sprintf("agent %s failed: %v", a.agents[i].Name(), res.err)
+			}
+			result.mergePartial(ctx, res.result)
+			continue
 		}
 
-		result.Steps = append(result.Steps, res.result.Steps...)
+		result.appendSteps(ctx, res.result.Steps)
 		result.Artifacts = append(result.Artifacts, res.result.Artifacts...)
 
-		// Collect outputs by agent name
-		outputs[a.agents[i].Name()] = res.result.Output
+		// Collect outputs by agent name; skipped optional stages have none
+		if !stageSkipped(res.result) {
+			outputs[a.agents[i].Name()] = res.result.Output
+		}
+		// Named outputs are merged in agent order
+		for k, v := range res.result.Outputs {
+			if result.Outputs == nil {
+				result.Outputs = make(map[string]interface{})
+			}
+			result.Outputs[k] = v
+		}
 
 		// Merge state deltas back
 		if len(res.result.Steps) > 0 {
@@ -145,21 +200,41 @@ func (a *ParallelAgent) Execute(ctx context.Context, task *Task) (*Result, error
 	}
 
 	result.Output = outputs
+
+	// Outputs of the agents that succeeded are kept on the partial result
+	if firstErr != nil {
+		result.markPartial(task.State)
+		return result, firstErr
+	}
+
+	// Any escalating child escalates the whole fan-out
+	for _, res := range results {
+		if propagateEscalation(result, res.result) {
+			result.aggregateMetrics()
+			return result, nil
+		}
+	}
+
 	result.Success = true
+	result.aggregateMetrics()
 	return result, nil
 }
 
 // PipelineAgent chains agents where each stage's output becomes the next
 // stage's input, forming a data processing pipeline.
 type PipelineAgent struct {
-	name   string
-	stages []Agent
+	name        string
+	stages      []Agent
+	opts        workflowOptions
+	contractErr error // From CheckContracts at construction
 }
 
 // NewPipelineAgent creates a new PipelineAgent that chains agents sequentially
-// with data flow between stages.
-func NewPipelineAgent(name string, stages []Agent) *PipelineAgent {
-	return &PipelineAgent{name: name, stages: stages}
+// with data flow between stages. The stage contracts are checked here; a
+// mismatch is returned by Validate and Warmup, and fails Execute before any
+// stage runs.
+func NewPipelineAgent(name string, stages []Agent, opts ...WorkflowOption) *PipelineAgent {
+	return &PipelineAgent{name: name, stages: stages, opts: newWorkflowOptions(opts), contractErr: CheckContracts(stages)}
 }
 
 func (a *PipelineAgent) Name() string {
@@ -170,38 +245,95 @@ func (a *PipelineAgent) SubAgents() []Agent {
 	return a.stages
 }
 
+// Validate checks the declared output/input schemas of adjacent stages for
+// compatibility without running the pipeline.
+func (a *PipelineAgent) Validate() error {
+	return a.contractErr
+}
+
+// Warmup reports a stage contract mismatch, so an Executor warming up its
+// agents surfaces it before the first task.
+func (a *PipelineAgent) Warmup(ctx context.Context) error {
+	return a.contractErr
+}
+
 func (a *PipelineAgent) Execute(ctx context.Context, task *Task) (*Result, error) {
+	ctx = EnterAgent(ctx, a.name)
 	result := &Result{
-		TaskID:  task.ID,
-		Success: false,
-		Steps:   []ExecutionStep{},
+		TaskID:   task.ID,
+		Success:  false,
+		Metadata: make(map[string]interface{}),
+		Steps:    []ExecutionStep{},
+	}
+
+	// Mismatched stages would only fail after paid model calls
+	if a.contractErr != nil {
+		result.Error = a.contractErr.Error()
+		return result, a.contractErr
 	}
 
 	// Each stage receives previous stage's output as input
+	start := resumeAt(ctx, task, result)
 	currentInput := task.Input
+	var completed []completedStage
 
-	for _, stage := range a.stages {
+	for i := start; i < len(a.stages); i++ {
+		stage := a.stages[i]
 		// Update task input from previous output
 		task.Input = currentInput
 
-		subResult, err := stage.Execute(ctx, task)
+		subResult, err := a.opts.runStage(enterStage(ctx, i, currentInput, result), a.name, i, stage, task)
 		if err != nil {
 			result.Error = fmt.Sprintf("stage %s failed: %v", stage.Name(), err)
+			result.mergePartial(ctx, subResult)
+			result.Output = currentInput // Last good stage output
+			compensate(ctx, task, result, completed)
+			result.markPartial(task.State)
 			return result, err
 		}
 
-		result.Steps = append(result.Steps, subResult.Steps...)
+		result.appendSteps(ctx, subResult.Steps)
 		result.Artifacts = append(result.Artifacts, subResult.Artifacts...)
 
-		// Output becomes input for next stage
-		if str, ok := subResult.Output.(string); ok {
-			currentInput = str
-		} else {
-			currentInput = fmt.Sprint(subResult.Output)
+		if propagateEscalation(result, subResult) {
+			result.aggregateMetrics()
+			return result, nil
+		}
+
+		// Catch wiring mistakes before the next stage spends a run on bad input
+		var next Agent
+		if i+1 < len(a.stages) {
+			next = a.stages[i+1]
+		}
+		if stageSkipped(subResult) {
+			a.opts.saveCheckpoint(ctx, i, stage, task, result, currentInput)
+			continue
 		}
+		completed = append(completed, completedStage{stage, subResult})
+		if err := checkStageOutput(stage, next, subResult.Output); err != nil {
+			result.Error = err.Error()
+			compensate(ctx, task, result, completed)
+			result.markPartial(task.State)
+			return result, err
+		}
+
+		// Output becomes input for next stage
+		currentInput = outputString(subResult.Output)
+		result.Output = currentInput
+		result.Outputs = subResult.Outputs
+		a.opts.saveCheckpoint(ctx, i, stage, task, result, currentInput)
 	}
 
 	result.Output = currentInput
 	result.Success = true
+	result.aggregateMetrics()
 	return result, nil
 }
+
+// outputString renders an agent output as text for use as the next input.
+func outputString(output interface{}) string {
+	if str, ok := output.(string); ok {
+		return str
+	}
+	return fmt.Sprint(output)
+}
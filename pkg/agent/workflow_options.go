@@ -0,0 +1,25 @@
+package agent
+
+// WorkflowOption configures a SequentialAgent or PipelineAgent.
+type WorkflowOption func(*workflowOptions)
+
+type workflowOptions struct {
+	cache       StageCache
+	checkpoints CheckpointStore
+}
+
+func newWorkflowOptions(opts []WorkflowOption) workflowOptions {
+	var o workflowOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithStageCache caches completed stage results so re-running a workflow
+// after a late-stage failure skips the stages that already succeeded.
+func WithStageCache(cache StageCache) WorkflowOption {
+	return func(o *workflowOptions) {
+		o.cache = cache
+	}
+}
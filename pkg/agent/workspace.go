@@ -0,0 +1,230 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Workspace is a per-task scratch directory shared by the task's tools.
+// Every access goes through an os.Root, so names cannot escape the
+// directory through ".." or symlinks.
+type Workspace struct {
+	Dir  string
+	root *os.Root
+}
+
+// OpenWorkspace opens dir as a workspace, creating it if needed.
+func OpenWorkspace(dir string) (*Workspace, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Workspace{Dir: dir, root: root}, nil
+}
+
+// FS returns the workspace as a read-only file system.
+func (w *Workspace) FS() fs.FS {
+	return w.root.FS()
+}
+
+// ReadFile returns the content of the named file.
+func (w *Workspace) ReadFile(name string) ([]byte, error) {
+	f, err := w.root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// WriteFile writes data to the named file, creating parent directories.
+func (w *Workspace) WriteFile(name string, data []byte) error {
+	if dir := path.Dir(filepath.ToSlash(name)); dir != "." {
+		if err := w.mkdirAll(dir); err != nil {
+			return err
+		}
+	}
+	f, err := w.root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (w *Workspace) mkdirAll(dir string) error {
+	var p string
+	for _, part := range strings.Split(dir, "/") {
+		p = path.Join(p, part)
+		if err := w.root.Mkdir(p, 0o700); err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove deletes the named file or empty directory.
+func (w *Workspace) Remove(name string) error {
+	return w.root.Remove(name)
+}
+
+// Files lists the regular files in the workspace, slash-separated and
+// relative to its root.
+func (w *Workspace) Files() ([]string, error) {
+	var files []string
+	err := fs.WalkDir(w.FS(), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Close releases the workspace without deleting it.
+func (w *Workspace) Close() error {
+	return w.root.Close()
+}
+
+type workspaceKey struct{}
+
+// ContextWithWorkspace makes ws available to tools run under ctx.
+func ContextWithWorkspace(ctx context.Context, ws *Workspace) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, ws)
+}
+
+// WorkspaceFromContext returns the workspace of the task being executed, or
+// nil when the Executor was not configured with WithWorkspace.
+func WorkspaceFromContext(ctx context.Context) *Workspace {
+	ws, _ := ctx.Value(workspaceKey{}).(*Workspace)
+	return ws
+}
+
+// WorkspaceConfig controls per-task workspaces; see WithWorkspace.
+type WorkspaceConfig struct {
+	BaseDir string // Parent of the task directories (default os.TempDir())
+
+	// Capture selects the files attached to the result as artifacts when the
+	// task finishes (nil = all files).
+	Capture func(name string) bool
+
+	// MaxCaptureBytes skips larger files, listing them in
+	// Metadata["workspace_skipped"] (default 10 MiB).
+	MaxCaptureBytes int64
+
+	// Keep leaves the directory in place after the task, e.g. for debugging.
+	// Its path is in Metadata["workspace"].
+	Keep bool
+}
+
+// WithWorkspace gives every task its own workspace directory, available to
+// tools through WorkspaceFromContext. Files left in it are attached to the
+// result as artifacts (also for failed tasks) and the directory is removed.
+func WithWorkspace(cfg WorkspaceConfig) ExecutorOption {
+	return func(e *Executor) {
+		if cfg.BaseDir == "" {
+			cfg.BaseDir = os.TempDir()
+		}
+		if cfg.MaxCaptureBytes == 0 {
+			cfg.MaxCaptureBytes = 10 << 20
+		}
+		e.workspace = &cfg
+	}
+}
+
+// open provisions the workspace for task, if configured.
+func (c *WorkspaceConfig) open(task *Task) (*Workspace, error) {
+	if c == nil {
+		return nil, nil
+	}
+	ws, err := OpenWorkspace(filepath.Join(c.BaseDir, "gonostic-"+task.ID))
+	if err != nil {
+		return nil, fmt.Errorf("workspace: %w", err)
+	}
+	return ws, nil
+}
+
+// finish captures the workspace files into result and removes the
+// directory unless Keep is set.
+func (c *WorkspaceConfig) finish(ws *Workspace, result *Result) {
+	defer func() {
+		ws.Close()
+		if !c.Keep {
+			os.RemoveAll(ws.Dir)
+		}
+	}()
+	if result == nil {
+		return
+	}
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]interface{})
+	}
+	if c.Keep {
+		result.Metadata["workspace"] = ws.Dir
+	}
+
+	files, err := ws.Files()
+	if err != nil {
+		result.Metadata["workspace_error"] = err.Error()
+	}
+	var skipped []string
+	for _, name := range files {
+		if c.Capture != nil && !c.Capture(name) {
+			continue
+		}
+		info, err := fs.Stat(ws.FS(), name)
+		if err != nil || info.Size() > c.MaxCaptureBytes {
+			skipped = append(skipped, name)
+			continue
+		}
+		data, err := ws.ReadFile(name)
+		if err != nil {
+			skipped = append(skipped, name)
+			continue
+		}
+		result.Artifacts = append(result.Artifacts, workspaceArtifact(name, data))
+	}
+	if len(skipped) > 0 {
+		result.Metadata["workspace_skipped"] = skipped
+	}
+}
+
+func workspaceArtifact(name string, data []byte) Artifact {
+	mimeType := mime.TypeByExtension(path.Ext(name))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	typ := "file"
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		typ = "image"
+	case strings.HasPrefix(mimeType, "video/"):
+		typ = "video"
+	case strings.HasPrefix(mimeType, "text/"):
+		typ = "text"
+	}
+	return Artifact{
+		Type:     typ,
+		MimeType: mimeType,
+		Content:  data,
+		Metadata: map[string]interface{}{"path": name, "source": "workspace"},
+	}
+}
@@ -0,0 +1,226 @@
+// Package audit writes a tamper-evident, append-only record of agent
+// execution: every prompt, model response, tool call, and state change.
+//
+// Each entry carries the hash of the previous entry, so editing, removing,
+// or reordering any line breaks the chain and is reported by Verify.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Kind classifies an audit entry.
+type Kind string
+
+const (
+	KindPrompt     Kind = "prompt"     // Completion request sent to a model
+	KindResponse   Kind = "response"   // Model response
+	KindToolCall   Kind = "tool_call"  // Tool invocation with arguments and result
+	KindState      Kind = "state"      // State keys written by a step
+	KindStep       Kind = "step"       // Completed execution step
+	KindEscalation Kind = "escalation" // Escalation raised by an agent
+)
+
+// Entry is one line of the audit log.
+type Entry struct {
+	Seq      uint64          `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Kind     Kind            `json:"kind"`
+	TaskID   string          `json:"task_id,omitempty"`
+	Agent    string          `json:"agent,omitempty"` // Agent path
+	Data     json.RawMessage `json:"data"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash,omitempty"`
+}
+
+// computeHash hashes the entry with its Hash field cleared.
+func (e Entry) computeHash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Log appends hash-chained entries to a writer. It is safe for concurrent
+// use.
+type Log struct {
+	mu   sync.Mutex
+	w    io.Writer
+	seq  uint64
+	prev string
+
+	// OnError receives write failures from Record, which is called from
+	// event handlers that cannot return errors (optional).
+	OnError func(err error)
+
+	// Redaction is applied to everything recorded by Handler and Model
+	// (optional). The chain still covers the redacted form.
+	Redaction *agent.RedactionPolicy
+}
+
+// NewLog starts a new chain on w.
+func NewLog(w io.Writer) *Log {
+	return &Log{w: w}
+}
+
+// OpenFile opens an audit log file for appending, continuing the chain of
+// any entries already in it. The existing entries are verified first.
+func OpenFile(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	last, err := verify(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	l := &Log{w: f}
+	if last != nil {
+		l.seq = last.Seq
+		l.prev = last.Hash
+	}
+	return l, nil
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if c, ok := l.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Record appends an entry with data encoded as JSON.
+func (l *Log) Record(kind Kind, taskID, agent string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return l.fail(fmt.Errorf("audit: encode %s: %w", kind, err))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := Entry{
+		Seq:      l.seq + 1,
+		Time:     time.Now().UTC(),
+		Kind:     kind,
+		TaskID:   taskID,
+		Agent:    agent,
+		Data:     raw,
+		PrevHash: l.prev,
+	}
+	if e.Hash, err = e.computeHash(); err != nil {
+		return l.fail(err)
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return l.fail(err)
+	}
+	if _, err := l.w.Write(append(line, '\n')); err != nil {
+		return l.fail(fmt.Errorf("audit: write: %w", err))
+	}
+	l.seq = e.Seq
+	l.prev = e.Hash
+	return nil
+}
+
+func (l *Log) fail(err error) error {
+	if l.OnError != nil {
+		l.OnError(err)
+	}
+	return err
+}
+
+// ChainError reports where an audit log fails verification.
+type ChainError struct {
+	Seq    uint64 // Sequence number of the offending entry (0 if unparseable)
+	Line   int
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit log broken at line %d (seq %d): %s", e.Line, e.Seq, e.Reason)
+}
+
+// ErrEmptyLog is returned by Verify for a log without entries.
+var ErrEmptyLog = errors.New("audit log is empty")
+
+// Verify checks every entry's hash and its link to the previous entry,
+// returning the number of entries verified. A *ChainError identifies the
+// first entry that does not match.
+func Verify(r io.Reader) (int, error) {
+	last, count, err := verifyCount(r)
+	if err != nil {
+		return count, err
+	}
+	if last == nil {
+		return 0, ErrEmptyLog
+	}
+	return count, nil
+}
+
+// VerifyFile verifies the audit log at path. See Verify.
+func VerifyFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return Verify(f)
+}
+
+func verify(r io.Reader) (*Entry, error) {
+	last, _, err := verifyCount(r)
+	return last, err
+}
+
+func verifyCount(r io.Reader) (*Entry, int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	var last *Entry
+	prev := ""
+	count := 0
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return last, count, &ChainError{Line: line, Reason: "malformed entry: " + err.Error()}
+		}
+		if e.PrevHash != prev {
+			return last, count, &ChainError{Seq: e.Seq, Line: line, Reason: "previous hash does not match"}
+		}
+		if last != nil && e.Seq != last.Seq+1 {
+			return last, count, &ChainError{Seq: e.Seq, Line: line, Reason: fmt.Sprintf("expected seq %d", last.Seq+1)}
+		}
+		hash, err := e.computeHash()
+		if err != nil || hash != e.Hash {
+			return last, count, &ChainError{Seq: e.Seq, Line: line, Reason: "entry hash does not match contents"}
+		}
+		prev = e.Hash
+		last = &e
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return last, count, err
+	}
+	return last, count, nil
+}
@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+type toolCallRecord struct {
+	ID        string                 `json:"id,omitempty"`
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Result    interface{}            `json:"result,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+type stepRecord struct {
+	Action string            `json:"action"`
+	Input  interface{}       `json:"input,omitempty"`
+	Output interface{}       `json:"output,omitempty"`
+	Error  string            `json:"error,omitempty"`
+	Usage  *agent.TokenUsage `json:"usage,omitempty"`
+}
+
+// Handler returns an event handler recording steps, tool calls, state
+// changes, and escalations. Set it as ExecutionConfig.OnEvent (chain it with
+// an existing handler if needed). Partial streaming deltas are skipped; the
+// assembled response is recorded by Model.
+func (l *Log) Handler() agent.EventHandler {
+	return func(ev *agent.Event) {
+		ev = l.Redaction.Event(ev)
+		agentPath := ev.AgentPath
+		if agentPath == "" {
+			agentPath = ev.Author
+		}
+		switch ev.Type {
+		case agent.EventStep:
+			for _, tc := range ev.ToolCalls {
+				rec := toolCallRecord{ID: tc.ID, Name: tc.Name, Arguments: tc.Arguments, Result: tc.Result}
+				if tc.Error != nil {
+					rec.Error = tc.Error.Error()
+				}
+				l.Record(KindToolCall, ev.TaskID, agentPath, rec)
+			}
+			if ev.Actions != nil && len(ev.Actions.StateDelta) > 0 {
+				l.Record(KindState, ev.TaskID, agentPath, ev.Actions.StateDelta)
+			}
+			l.Record(KindStep, ev.TaskID, agentPath, stepRecord{
+				Action: ev.Action, Input: ev.Input, Output: ev.Output, Error: ev.Error, Usage: ev.Usage,
+			})
+		case agent.EventEscalation:
+			l.Record(KindEscalation, ev.TaskID, agentPath, ev.Actions)
+		}
+	}
+}
+
+type promptRecord struct {
+	History      []agent.Message        `json:"history"`
+	Tools        []string               `json:"tools,omitempty"`
+	OutputSchema map[string]interface{} `json:"output_schema,omitempty"`
+	Temperature  *float32               `json:"temperature,omitempty"`
+}
+
+type responseRecord struct {
+	Content   string            `json:"content"`
+	ToolCalls []toolCallRecord  `json:"tool_calls,omitempty"`
+	Usage     *agent.TokenUsage `json:"usage,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// Model wraps a provider so every completion request and response is
+// recorded, attributed to the calling agent's path.
+func (l *Log) Model(m agent.ModelProvider) agent.ModelProvider {
+	return &auditedModel{inner: m, log: l}
+}
+
+type auditedModel struct {
+	inner agent.ModelProvider
+	log   *Log
+}
+
+func (m *auditedModel) Complete(ctx context.Context, req *agent.CompletionRequest) (*agent.ModelResponse, error) {
+	return m.record(ctx, req, func() (*agent.ModelResponse, error) {
+		return m.inner.Complete(ctx, req)
+	})
+}
+
+// CompleteStream streams through to the wrapped provider when it supports
+// streaming; the assembled response is recorded once it completes.
+func (m *auditedModel) CompleteStream(ctx context.Context, req *agent.CompletionRequest, onDelta func(string)) (*agent.ModelResponse, error) {
+	return m.record(ctx, req, func() (*agent.ModelResponse, error) {
+		if s, ok := m.inner.(agent.StreamingModelProvider); ok {
+			return s.CompleteStream(ctx, req, onDelta)
+		}
+		return m.inner.Complete(ctx, req)
+	})
+}
+
+func (m *auditedModel) record(ctx context.Context, req *agent.CompletionRequest, call func() (*agent.ModelResponse, error)) (*agent.ModelResponse, error) {
+	agentPath := agent.AgentPathFromContext(ctx)
+	redact := m.log.Redaction
+	prompt := promptRecord{History: redact.Messages(req.History), OutputSchema: req.OutputSchema, Temperature: req.Temperature}
+	for _, t := range req.Tools {
+		prompt.Tools = append(prompt.Tools, t.Name())
+	}
+	m.log.Record(KindPrompt, "", agentPath, prompt)
+
+	resp, err := call()
+
+	rec := responseRecord{}
+	if resp != nil {
+		rec.Content = redact.String(agent.RedactOutput, resp.Content)
+		rec.Usage = resp.Usage
+		for _, tc := range redact.ToolCalls(resp.ToolCalls) {
+			rec.ToolCalls = append(rec.ToolCalls, toolCallRecord{ID: tc.ID, Name: tc.Name, Arguments: tc.Arguments})
+		}
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	m.log.Record(KindResponse, "", agentPath, rec)
+	return resp, err
+}
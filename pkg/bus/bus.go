@@ -0,0 +1,263 @@
+// Package bus publishes agent events to a message broker such as NATS or
+// Kafka, so analytics and alerting consumers can follow every job without
+// changes to agent code:
+//
+//	b := bus.New(bus.Config{Publisher: bus.NATS{Conn: nc}})
+//	defer b.Close(context.Background())
+//	exec := agent.NewExecutor(myAgent, 5, agent.WithEvents(b.Handler()))
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Publisher sends one message to a topic. key is the task ID, for brokers
+// that partition by key (Kafka).
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key, data []byte) error
+}
+
+// PublisherFunc adapts a function to Publisher, e.g. for a Kafka writer:
+//
+//	bus.PublisherFunc(func(ctx context.Context, topic string, key, data []byte) error {
+//		return w.WriteMessages(ctx, kafka.Message{Topic: topic, Key: key, Value: data})
+//	})
+type PublisherFunc func(ctx context.Context, topic string, key, data []byte) error
+
+func (f PublisherFunc) Publish(ctx context.Context, topic string, key, data []byte) error {
+	return f(ctx, topic, key, data)
+}
+
+// NATSConn is the subset of a NATS connection (*nats.Conn) NATS needs.
+type NATSConn interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATS publishes on a NATS connection, using the topic as the subject.
+type NATS struct {
+	Conn NATSConn
+}
+
+func (n NATS) Publish(ctx context.Context, topic string, key, data []byte) error {
+	return n.Conn.Publish(topic, data)
+}
+
+// Message is the wire form of an event.
+type Message struct {
+	ID           string              `json:"id"`
+	Type         agent.EventType     `json:"type"`
+	TaskID       string              `json:"task_id"`
+	Author       string              `json:"author,omitempty"`
+	AgentPath    string              `json:"agent_path,omitempty"`
+	Time         time.Time           `json:"time"`
+	Action       string              `json:"action,omitempty"`
+	Content      string              `json:"content,omitempty"`
+	Output       interface{}         `json:"output,omitempty"`
+	ToolCalls    []ToolCall          `json:"tool_calls,omitempty"`
+	Error        string              `json:"error,omitempty"`
+	Finished     bool                `json:"finished,omitempty"`
+	DurationMS   int64               `json:"duration_ms,omitempty"`
+	Usage        *agent.TokenUsage   `json:"usage,omitempty"`
+	Model        string              `json:"model,omitempty"`
+	FinishReason agent.FinishReason  `json:"finish_reason,omitempty"`
+	Progress     *agent.Progress     `json:"progress,omitempty"`
+	Question     *agent.InputRequest `json:"question,omitempty"`
+}
+
+// ToolCall is the wire form of a tool call.
+type ToolCall struct {
+	ID         string                 `json:"id,omitempty"`
+	Name       string                 `json:"name"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	Result     interface{}            `json:"result,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	DurationMS int64                  `json:"duration_ms,omitempty"`
+}
+
+// NewMessage converts an event to its wire form.
+func NewMessage(ev *agent.Event) *Message {
+	m := &Message{
+		ID:           ev.ID,
+		Type:         ev.Type,
+		TaskID:       ev.TaskID,
+		Author:       ev.Author,
+		AgentPath:    ev.AgentPath,
+		Time:         ev.Timestamp,
+		Action:       ev.Action,
+		Content:      ev.Content,
+		Output:       ev.Output,
+		Error:        ev.Error,
+		Finished:     ev.Finished,
+		DurationMS:   ev.Duration.Milliseconds(),
+		Usage:        ev.Usage,
+		Model:        ev.Model,
+		FinishReason: ev.FinishReason,
+		Progress:     ev.Progress,
+		Question:     ev.Question,
+	}
+	for _, tc := range ev.ToolCalls {
+		wire := ToolCall{ID: tc.ID, Name: tc.Name, Arguments: tc.Arguments, Result: tc.Result, DurationMS: tc.Duration.Milliseconds()}
+		if tc.Error != nil {
+			wire.Error = tc.Error.Error()
+		}
+		m.ToolCalls = append(m.ToolCalls, wire)
+	}
+	return m
+}
+
+// Config holds configuration for creating a Bus.
+type Config struct {
+	Publisher Publisher
+
+	// Prefix names the default topics, <Prefix>.<event type>, e.g.
+	// "gonostic.events.step" (default "gonostic.events").
+	Prefix string
+
+	// Topic picks the topic per message instead, e.g. a single Kafka topic
+	// (optional).
+	Topic func(m *Message) string
+
+	// Types limits which events are published (default: all except
+	// EventPartial streaming deltas).
+	Types []agent.EventType
+
+	// Sampling keeps every failed job but only some successful ones
+	// (optional; see agent.SamplingPolicy).
+	Sampling *agent.SamplingPolicy
+
+	// Encode serializes messages (default JSON).
+	Encode func(m *Message) ([]byte, error)
+
+	Buffer  int           // Messages queued for publishing; when full, new ones are dropped (default 1024)
+	Timeout time.Duration // Per-publish timeout (default 5s)
+	OnError func(err error)
+}
+
+// ErrClosed is returned by Publish after Close.
+var ErrClosed = errors.New("bus: closed")
+
+// Bus publishes events in the background so slow brokers never block
+// agents. Events are published in the order they were emitted.
+type Bus struct {
+	cfg     Config
+	types   map[agent.EventType]bool
+	queue   chan *Message
+	done    chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+	dropped atomic.Int64
+}
+
+// New creates a Bus and starts its publishing goroutine.
+func New(cfg Config) *Bus {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "gonostic.events"
+	}
+	if cfg.Encode == nil {
+		cfg.Encode = func(m *Message) ([]byte, error) { return json.Marshal(m) }
+	}
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 1024
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	b := &Bus{cfg: cfg, queue: make(chan *Message, cfg.Buffer), done: make(chan struct{})}
+	if cfg.Types != nil {
+		b.types = make(map[agent.EventType]bool, len(cfg.Types))
+		for _, t := range cfg.Types {
+			b.types[t] = true
+		}
+	}
+	go b.run()
+	return b
+}
+
+// Handler returns an event handler that queues events for publishing, for
+// agent.WithEvents or ExecutionConfig.OnEvent.
+func (b *Bus) Handler() agent.EventHandler {
+	return b.cfg.Sampling.Handler(func(ev *agent.Event) {
+		if b.types != nil && !b.types[ev.Type] || b.types == nil && ev.Type == agent.EventPartial {
+			return
+		}
+		if err := b.Publish(NewMessage(ev)); err != nil && !errors.Is(err, ErrClosed) {
+			b.report(err)
+		}
+	})
+}
+
+// Publish queues a message without blocking. It fails when the buffer is
+// full, counting the message in Dropped.
+func (b *Bus) Publish(m *Message) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrClosed
+	}
+	select {
+	case b.queue <- m:
+		return nil
+	default:
+		b.dropped.Add(1)
+		return fmt.Errorf("bus: buffer full, dropped %s event for task %s", m.Type, m.TaskID)
+	}
+}
+
+// Dropped returns how many messages were dropped because the buffer was full.
+func (b *Bus) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// Close stops accepting messages and waits until the queued ones are
+// published or ctx is done.
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Bus) run() {
+	defer close(b.done)
+	for m := range b.queue {
+		data, err := b.cfg.Encode(m)
+		if err != nil {
+			b.report(fmt.Errorf("bus: encode %s event: %w", m.Type, err))
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), b.cfg.Timeout)
+		if err := b.cfg.Publisher.Publish(ctx, b.topic(m), []byte(m.TaskID), data); err != nil {
+			b.report(fmt.Errorf("bus: publish %s event: %w", m.Type, err))
+		}
+		cancel()
+	}
+}
+
+func (b *Bus) topic(m *Message) string {
+	if b.cfg.Topic != nil {
+		return b.cfg.Topic(m)
+	}
+	return b.cfg.Prefix + "." + string(m.Type)
+}
+
+func (b *Bus) report(err error) {
+	if b.cfg.OnError != nil {
+		b.cfg.OnError(err)
+	}
+}
@@ -0,0 +1,190 @@
+// Package config loads deployment settings from environment variables, so
+// 12-factor deployments configure providers, stores, workers, and timeouts
+// without their own plumbing. Every invalid variable is reported at once in
+// an *Error.
+package config
+
+import (
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/providers"
+)
+
+// Config holds the settings read by FromEnv. Tags name each variable, its
+// default, and its constraints; see Load.
+type Config struct {
+	Addr            string        `env:"GONOSTIC_ADDR" default:":8080"`
+	Workers         int           `env:"GONOSTIC_WORKERS" default:"5" min:"1"`
+	TaskTimeout     time.Duration `env:"GONOSTIC_TASK_TIMEOUT"`                   // Per-task limit (0 = none)
+	ShutdownTimeout time.Duration `env:"GONOSTIC_SHUTDOWN_TIMEOUT" default:"30s"` // Drain time on SIGTERM
+	WarmupTimeout   time.Duration `env:"GONOSTIC_WARMUP_TIMEOUT"`                 // 0 = no warmup
+	StallInterval   time.Duration `env:"GONOSTIC_STALL_INTERVAL"`                 // 0 = no stall detection
+	ProviderTimeout time.Duration `env:"GONOSTIC_PROVIDER_TIMEOUT" default:"60s"` // Wait for response headers
+
+	// Provider is the default provider name; it must be one of Providers.
+	// With a single provider configured it defaults to that one.
+	Provider string `env:"GONOSTIC_PROVIDER"`
+
+	SessionStoreDSN    string `env:"GONOSTIC_SESSION_STORE_DSN" dsn:"true"`
+	CheckpointStoreDSN string `env:"GONOSTIC_CHECKPOINT_STORE_DSN" dsn:"true"`
+	ResultStoreDSN     string `env:"GONOSTIC_RESULT_STORE_DSN" dsn:"true"`
+
+	// Providers are read from GONOSTIC_PROVIDER_<NAME>_API_KEY (or
+	// _API_KEYS, comma-separated for a KeyPool), _BASE_URL, and _MODEL, keyed
+	// by the lowercased name. OPENAI_API_KEY, ANTHROPIC_API_KEY, and
+	// GEMINI_API_KEY are used when the matching provider has no key.
+	Providers map[string]Provider `env:"-"`
+}
+
+// Provider is the connection settings for one model provider.
+type Provider struct {
+	Name    string
+	APIKeys []string
+	BaseURL string // Empty uses the provider's default
+	Model   string
+}
+
+// APIKey returns the first configured key, or "" for keyless backends.
+func (p Provider) APIKey() string {
+	if len(p.APIKeys) == 0 {
+		return ""
+	}
+	return p.APIKeys[0]
+}
+
+// KeyPool returns a pool rotating over the provider's keys.
+func (p Provider) KeyPool(cfg providers.KeyPoolConfig) *providers.KeyPool {
+	return providers.NewKeyPool(p.APIKeys, cfg)
+}
+
+// Default returns the provider named by Provider.
+func (c *Config) Default() Provider {
+	return c.Providers[c.Provider]
+}
+
+// HTTP returns the provider HTTP settings derived from the config.
+func (c *Config) HTTP() providers.HTTPConfig {
+	return providers.HTTPConfig{ResponseHeaderTimeout: c.ProviderTimeout}
+}
+
+// FromEnv reads the configuration from the process environment.
+func FromEnv() (*Config, error) {
+	return FromEnviron(os.Environ())
+}
+
+// wellKnownKeys are the conventional key variables of common providers.
+var wellKnownKeys = map[string]string{
+	"openai":    "OPENAI_API_KEY",
+	"anthropic": "ANTHROPIC_API_KEY",
+	"gemini":    "GEMINI_API_KEY",
+}
+
+const providerPrefix = "GONOSTIC_PROVIDER_"
+
+var providerSuffixes = []string{"_API_KEYS", "_API_KEY", "_BASE_URL", "_MODEL"}
+
+// FromEnviron reads the configuration from "KEY=value" pairs in the form
+// returned by os.Environ.
+func FromEnviron(environ []string) (*Config, error) {
+	vars := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			vars[k] = v
+		}
+	}
+	lookup := func(k string) (string, bool) {
+		v, ok := vars[k]
+		return v, ok
+	}
+
+	cfg := &Config{Providers: make(map[string]Provider)}
+	errs := &Error{}
+	if err := Load(cfg, lookup); err != nil {
+		errs.Errors = append(errs.Errors, err.(*Error).Errors...)
+	}
+
+	names := make(map[string]bool)
+	for k := range vars {
+		if n := providerName(k); n != "" {
+			names[n] = true
+		}
+	}
+	for n, env := range wellKnownKeys {
+		if vars[env] != "" {
+			names[n] = true
+		}
+	}
+
+	for n := range names {
+		p := Provider{Name: n}
+		upper := providerPrefix + strings.ToUpper(n)
+		if v, ok := lookup(upper + "_API_KEYS"); ok {
+			p.APIKeys = splitList(v)
+		} else if v, ok := lookup(upper + "_API_KEY"); ok && v != "" {
+			p.APIKeys = []string{v}
+		} else if env, ok := wellKnownKeys[n]; ok {
+			if v, ok := lookup(env); ok && v != "" {
+				p.APIKeys = []string{v}
+			}
+		}
+		p.BaseURL, _ = lookup(upper + "_BASE_URL")
+		p.Model, _ = lookup(upper + "_MODEL")
+
+		if p.BaseURL != "" {
+			if u, err := url.Parse(p.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+				errs.add(upper+"_BASE_URL", p.BaseURL, ErrInvalidURL)
+			}
+		}
+		if p.APIKeys == nil && p.BaseURL == "" {
+			errs.add(upper+"_API_KEY", "", ErrRequired)
+		}
+		cfg.Providers[n] = p
+	}
+
+	switch {
+	case cfg.Provider == "" && len(cfg.Providers) == 1:
+		for n := range cfg.Providers {
+			cfg.Provider = n
+		}
+	case cfg.Provider != "":
+		cfg.Provider = strings.ToLower(cfg.Provider)
+		if _, ok := cfg.Providers[cfg.Provider]; !ok {
+			errs.add("GONOSTIC_PROVIDER", cfg.Provider, ErrUnknownProvider)
+		}
+	}
+
+	if len(errs.Errors) > 0 {
+		sort.SliceStable(errs.Errors, func(i, j int) bool { return errs.Errors[i].Var < errs.Errors[j].Var })
+		return cfg, errs
+	}
+	return cfg, nil
+}
+
+// providerName extracts the lowercased provider name from a
+// GONOSTIC_PROVIDER_<NAME>_<SETTING> variable, or "" if it is not one.
+func providerName(env string) string {
+	rest, ok := strings.CutPrefix(env, providerPrefix)
+	if !ok {
+		return ""
+	}
+	for _, suffix := range providerSuffixes {
+		if name, ok := strings.CutSuffix(rest, suffix); ok && name != "" {
+			return strings.ToLower(name)
+		}
+	}
+	return ""
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrRequired reports a required variable that is unset or empty.
+	ErrRequired = errors.New("required")
+	// ErrInvalidURL reports a value that is not an absolute URL.
+	ErrInvalidURL = errors.New("not an absolute URL")
+	// ErrUnknownProvider reports a default provider that is not configured.
+	ErrUnknownProvider = errors.New("no such provider configured")
+)
+
+// FieldError reports one invalid environment variable. Values of variables
+// that look like secrets are masked.
+type FieldError struct {
+	Var   string
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("%s: %v", e.Var, e.Err)
+	}
+	return fmt.Sprintf("%s=%q: %v", e.Var, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// Error collects every invalid variable found while loading.
+type Error struct {
+	Errors []*FieldError
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return "invalid configuration: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap lets errors.Is and errors.As match any of the field errors.
+func (e *Error) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		errs[i] = fe
+	}
+	return errs
+}
+
+func (e *Error) add(env, value string, err error) {
+	if secret(env) {
+		value = "***"
+	}
+	e.Errors = append(e.Errors, &FieldError{Var: env, Value: value, Err: err})
+}
+
+func secret(env string) bool {
+	for _, s := range []string{"KEY", "SECRET", "TOKEN", "PASSWORD", "DSN"} {
+		if strings.Contains(env, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Load fills the fields of the struct pointed to by dst from variables read
+// through lookup (os.LookupEnv for the process environment). Applications
+// can use it for their own settings alongside FromEnv. Field tags:
+//
+//	env:"NAME"      variable to read; fields without it (or with "-") are skipped
+//	default:"v"     value used when the variable is unset
+//	required:"true" fail when the variable is unset or empty
+//	min:"n"         minimum for numbers and durations
+//	dsn:"true"      value must parse as a URL with a scheme
+//
+// Supported field types are string, bool, int, int64, float64,
+// time.Duration, and []string (comma-separated). It returns an *Error
+// listing every invalid variable.
+func Load(dst interface{}, lookup func(string) (string, bool)) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: Load needs a pointer to a struct, got %T", dst)
+	}
+	v = v.Elem()
+	t := v.Type()
+	errs := &Error{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		env := f.Tag.Get("env")
+		if env == "" || env == "-" || !f.IsExported() {
+			continue
+		}
+		raw, ok := lookup(env)
+		if !ok || raw == "" {
+			if f.Tag.Get("required") == "true" {
+				errs.add(env, "", ErrRequired)
+				continue
+			}
+			raw, ok = f.Tag.Lookup("default")
+			if !ok {
+				continue
+			}
+		}
+		if err := setField(v.Field(i), f, raw); err != nil {
+			errs.add(env, raw, err)
+		}
+	}
+
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setField(field reflect.Value, f reflect.StructField, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case field.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return errors.New("invalid duration")
+		}
+		if err := checkMin(f, float64(d), func(s string) (float64, error) {
+			m, err := time.ParseDuration(s)
+			return float64(m), err
+		}); err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+
+	case field.Kind() == reflect.String:
+		if f.Tag.Get("dsn") == "true" {
+			if u, err := url.Parse(raw); err != nil || u.Scheme == "" {
+				return errors.New("invalid DSN: expected scheme://...")
+			}
+		}
+		field.SetString(raw)
+
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("invalid boolean")
+		}
+		field.SetBool(b)
+
+	case field.Kind() == reflect.Int || field.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return errors.New("invalid integer")
+		}
+		if err := checkMin(f, float64(n), parseFloat); err != nil {
+			return err
+		}
+		field.SetInt(n)
+
+	case field.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return errors.New("invalid number")
+		}
+		if err := checkMin(f, n, parseFloat); err != nil {
+			return err
+		}
+		field.SetFloat(n)
+
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		field.Set(reflect.ValueOf(splitList(raw)))
+
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+func parseFloat(s string) (float64, error) { return strconv.ParseFloat(s, 64) }
+
+func checkMin(f reflect.StructField, value float64, parse func(string) (float64, error)) error {
+	tag, ok := f.Tag.Lookup("min")
+	if !ok {
+		return nil
+	}
+	min, err := parse(tag)
+	if err != nil {
+		return fmt.Errorf("bad min tag %q", tag)
+	}
+	if value < min {
+		return fmt.Errorf("must be at least %s", tag)
+	}
+	return nil
+}
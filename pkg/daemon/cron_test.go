@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // Zones below must load without a system database
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{spec: "* * * * *"},
+		{spec: "*/15 9-17 * * 1-5"},
+		{spec: "0 0 1,15 * *"},
+		{spec: "0 0 * * 7"},
+		{spec: "@daily"},
+		{spec: "0 0 * *", wantErr: "expected 5 fields"},
+		{spec: "60 * * * *", wantErr: "out of range"},
+		{spec: "* 24 * * *", wantErr: "out of range"},
+		{spec: "* * 0 * *", wantErr: "out of range"},
+		{spec: "5-1 * * * *", wantErr: "out of range"},
+		{spec: "*/0 * * * *", wantErr: "invalid step"},
+		{spec: "a * * * *", wantErr: "invalid value"},
+		{spec: "1-x * * * *", wantErr: "invalid range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := ParseCron(tt.spec)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParseCron(%q) = %v", tt.spec, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ParseCron(%q) = %v, want error containing %q", tt.spec, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	load := func(name string) *time.Location {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return loc
+	}
+	utc := time.UTC
+	kolkata := load("Asia/Kolkata")         // UTC+5:30
+	kathmandu := load("Asia/Kathmandu")     // UTC+5:45
+	newYork := load("America/New_York")     // DST from 2nd Sunday of March to 1st Sunday of November
+	lordHowe := load("Australia/Lord_Howe") // Half-hour DST shift
+	santiago := load("America/Santiago")    // DST starts at midnight, skipping 00:00-01:00
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"next minute", "* * * * *", time.Date(2025, 1, 1, 10, 15, 30, 0, utc), time.Date(2025, 1, 1, 10, 16, 0, 0, utc)},
+		{"strictly after", "0 9 * * *", time.Date(2025, 1, 1, 9, 0, 0, 0, utc), time.Date(2025, 1, 2, 9, 0, 0, 0, utc)},
+		{"step", "*/15 * * * *", time.Date(2025, 1, 1, 10, 16, 0, 0, utc), time.Date(2025, 1, 1, 10, 30, 0, 0, utc)},
+		{"weekday", "0 8 * * 1-5", time.Date(2025, 1, 3, 9, 0, 0, 0, utc), time.Date(2025, 1, 6, 8, 0, 0, 0, utc)},
+		{"dom or dow", "0 0 13 * 5", time.Date(2025, 1, 1, 0, 0, 0, 0, utc), time.Date(2025, 1, 3, 0, 0, 0, 0, utc)},
+		{"sunday as 7", "0 0 * * 7", time.Date(2025, 1, 1, 0, 0, 0, 0, utc), time.Date(2025, 1, 5, 0, 0, 0, 0, utc)},
+		{"leap day", "0 0 29 2 *", time.Date(2025, 3, 1, 0, 0, 0, 0, utc), time.Date(2028, 2, 29, 0, 0, 0, 0, utc)},
+		{"never", "0 0 30 2 *", time.Date(2025, 1, 1, 0, 0, 0, 0, utc), time.Time{}},
+		{"half-hour offset", "0 9 * * *", time.Date(2025, 1, 1, 10, 15, 0, 0, kolkata), time.Date(2025, 1, 2, 9, 0, 0, 0, kolkata)},
+		{"half-hour offset hourly", "0 * * * *", time.Date(2025, 1, 1, 10, 15, 0, 0, kolkata), time.Date(2025, 1, 1, 11, 0, 0, 0, kolkata)},
+		{"quarter-hour offset", "30 6 * * *", time.Date(2025, 1, 1, 7, 0, 0, 0, kathmandu), time.Date(2025, 1, 2, 6, 30, 0, 0, kathmandu)},
+		{"spring forward skips hour", "30 2 * * *", time.Date(2025, 3, 9, 1, 0, 0, 0, newYork), time.Date(2025, 3, 10, 2, 30, 0, 0, newYork)},
+		{"spring forward hourly", "0 * * * *", time.Date(2025, 3, 9, 1, 30, 0, 0, newYork), time.Date(2025, 3, 9, 3, 0, 0, 0, newYork)},
+		{"fall back", "0 9 * * *", time.Date(2025, 11, 2, 0, 30, 0, 0, newYork), time.Date(2025, 11, 2, 9, 0, 0, 0, newYork)},
+		{"half-hour DST shift", "0 3 * * *", time.Date(2025, 4, 6, 1, 0, 0, 0, lordHowe), time.Date(2025, 4, 6, 3, 0, 0, 0, lordHowe)},
+		{"midnight gap, next hour", "0 12 * * *", time.Date(2024, 9, 7, 13, 0, 0, 0, santiago), time.Date(2024, 9, 8, 12, 0, 0, 0, santiago)},
+		{"midnight gap, next day", "0 12 8 9 *", time.Date(2024, 9, 7, 0, 0, 0, 0, santiago), time.Date(2024, 9, 8, 12, 0, 0, 0, santiago)},
+		{"midnight gap skipped", "30 0 * * *", time.Date(2024, 9, 7, 1, 0, 0, 0, santiago), time.Date(2024, 9, 9, 0, 30, 0, 0, santiago)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Cron(%q).Next(%v) = %v, want %v", tt.spec, tt.from, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// Package daemon runs agents autonomously on triggers (cron schedules, file
+// changes, incoming webhooks) and routes their results to sinks.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Fire is a single trigger activation that becomes a task.
+type Fire struct {
+	Trigger string                 // Trigger that fired, e.g. "cron:0 * * * *"
+	Input   string                 // Task input; Registration.Input is used when empty
+	Params  map[string]interface{} // Merged over Registration.Params
+	Time    time.Time
+}
+
+// Trigger produces Fires until its context is cancelled.
+type Trigger interface {
+	// Run blocks until ctx is done, calling fire for each activation.
+	Run(ctx context.Context, fire func(Fire)) error
+	String() string
+}
+
+// Registration binds an agent to the triggers that start it and the sinks
+// that receive its results.
+type Registration struct {
+	Agent    agent.Agent
+	Triggers []Trigger
+	Sinks    []agent.ResultSink     // e.g. from package sink
+	Input    string                 // Default task input for triggers without a payload
+	Params   map[string]interface{} // Default task params
+	Config   *agent.ExecutionConfig // Execution config for every task (optional)
+	Workers  int                    // Concurrent tasks for this agent (default 1)
+}
+
+// Config holds configuration for creating a Daemon.
+type Config struct {
+	// OnError receives trigger, submission, and sink errors (optional).
+	OnError func(err error)
+
+	// SinkTimeout bounds each sink delivery (default 30s).
+	SinkTimeout time.Duration
+}
+
+// Daemon runs registered agents whenever one of their triggers fires.
+type Daemon struct {
+	cfg Config
+
+	mu       sync.Mutex
+	regs     []*registration
+	running  bool
+	webhooks []*Webhook
+}
+
+type registration struct {
+	Registration
+	executor *agent.Executor
+}
+
+// New creates a new Daemon from the given configuration.
+func New(cfg Config) *Daemon {
+	if cfg.SinkTimeout == 0 {
+		cfg.SinkTimeout = 30 * time.Second
+	}
+	return &Daemon{cfg: cfg}
+}
+
+// Register adds an agent to the daemon. Registrations must be made before Run.
+func (d *Daemon) Register(r Registration) error {
+	if r.Agent == nil {
+		return errors.New("daemon: registration has no agent")
+	}
+	if len(r.Triggers) == 0 {
+		return fmt.Errorf("daemon: agent %s has no triggers", r.Agent.Name())
+	}
+	if r.Workers == 0 {
+		r.Workers = 1
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.running {
+		return errors.New("daemon: cannot register while running")
+	}
+
+	name := r.Agent.Name()
+	opts := []agent.ExecutorOption{
+		agent.WithSinkErrorHandler(d.cfg.SinkTimeout, func(job *agent.Job, err error) {
+			d.reportError(fmt.Errorf("daemon: sink for %s task %s: %w", name, job.Task.ID, err))
+		}),
+	}
+	for _, sink := range r.Sinks {
+		opts = append(opts, agent.WithResultSink(sink))
+	}
+	reg := &registration{Registration: r, executor: agent.NewExecutor(r.Agent, r.Workers, opts...)}
+	d.regs = append(d.regs, reg)
+	for _, t := range r.Triggers {
+		if wh, ok := t.(*Webhook); ok {
+			d.webhooks = append(d.webhooks, wh)
+		}
+	}
+	return nil
+}
+
+// Handler serves every registered Webhook trigger at its path. Mount it on
+// the HTTP server receiving external events.
+func (d *Daemon) Handler() http.Handler {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	mux := http.NewServeMux()
+	for _, wh := range d.webhooks {
+		mux.Handle("POST "+wh.Path, wh)
+	}
+	return mux
+}
+
+// Run starts all triggers and blocks until ctx is cancelled. Tasks already
+// submitted keep running on their executors.
+func (d *Daemon) Run(ctx context.Context) error {
+	d.mu.Lock()
+	if d.running {
+		d.mu.Unlock()
+		return errors.New("daemon: already running")
+	}
+	d.running = true
+	regs := d.regs
+	d.mu.Unlock()
+
+	defer func() {
+		d.mu.Lock()
+		d.running = false
+		d.mu.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	for _, reg := range regs {
+		for _, t := range reg.Triggers {
+			wg.Add(1)
+			go func(reg *registration, t Trigger) {
+				defer wg.Done()
+				err := t.Run(ctx, func(f Fire) { d.submit(reg, f) })
+				if err != nil && !errors.Is(err, context.Canceled) {
+					d.reportError(fmt.Errorf("daemon: trigger %s for %s: %w", t, reg.Agent.Name(), err))
+				}
+			}(reg, t)
+		}
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// submit turns a Fire into a task on the registration's executor.
+func (d *Daemon) submit(reg *registration, f Fire) {
+	input := f.Input
+	if input == "" {
+		input = reg.Input
+	}
+	params := make(map[string]interface{}, len(reg.Params)+len(f.Params)+1)
+	for k, v := range reg.Params {
+		params[k] = v
+	}
+	for k, v := range f.Params {
+		params[k] = v
+	}
+	params["trigger"] = f.Trigger
+
+	if _, err := reg.executor.Submit(input, params, reg.Config); err != nil {
+		d.reportError(fmt.Errorf("daemon: submit %s from %s: %w", reg.Agent.Name(), f.Trigger, err))
+	}
+}
+
+func (d *Daemon) reportError(err error) {
+	if d.cfg.OnError != nil {
+		d.cfg.OnError(err)
+	}
+}
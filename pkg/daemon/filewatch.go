@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileWatch fires when files matching a glob pattern are created or
+// modified. It polls, so it works on any filesystem without OS watch APIs.
+// The task input is the file path; Params carry "path" and "event"
+// ("created" or "modified").
+type FileWatch struct {
+	Pattern  string        // filepath.Glob pattern, e.g. "/srv/inbox/*.pdf"
+	Interval time.Duration // Poll period (default 5s)
+}
+
+// WatchFiles returns a FileWatch trigger for pattern.
+func WatchFiles(pattern string, interval time.Duration) *FileWatch {
+	return &FileWatch{Pattern: pattern, Interval: interval}
+}
+
+func (w *FileWatch) String() string {
+	return "file:" + w.Pattern
+}
+
+// Run polls until ctx is cancelled. Files present when Run starts do not
+// fire until they change.
+func (w *FileWatch) Run(ctx context.Context, fire func(Fire)) error {
+	interval := w.Interval
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+	seen, err := w.scan()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			current, err := w.scan()
+			if err != nil {
+				return err
+			}
+			for path, mod := range current {
+				prev, existed := seen[path]
+				switch {
+				case !existed:
+					fire(w.fire(path, "created", now))
+				case mod.After(prev):
+					fire(w.fire(path, "modified", now))
+				}
+			}
+			seen = current
+		}
+	}
+}
+
+func (w *FileWatch) fire(path, event string, now time.Time) Fire {
+	return Fire{
+		Trigger: w.String(),
+		Input:   path,
+		Params:  map[string]interface{}{"path": path, "event": event},
+		Time:    now,
+	}
+}
+
+// scan returns the modification time of every regular file matching the
+// pattern.
+func (w *FileWatch) scan() (map[string]time.Time, error) {
+	matches, err := filepath.Glob(w.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]time.Time, len(matches))
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files[path] = info.ModTime()
+	}
+	return files, nil
+}
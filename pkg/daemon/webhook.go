@@ -0,0 +1,88 @@
+package daemon
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Webhook fires when an external system POSTs to Path. The request body is
+// the task input and query parameters become task params. Serve it through
+// Daemon.Handler or mount it directly.
+type Webhook struct {
+	Path    string
+	Secret  string // Required X-Webhook-Secret header value (optional)
+	MaxBody int64  // Body size limit in bytes (default 1 MiB)
+
+	mu   sync.RWMutex
+	fire func(Fire)
+}
+
+// NewWebhook returns a Webhook trigger served at path.
+func NewWebhook(path, secret string) *Webhook {
+	return &Webhook{Path: path, Secret: secret}
+}
+
+func (w *Webhook) String() string {
+	return "webhook:" + w.Path
+}
+
+// Run accepts requests until ctx is cancelled.
+func (w *Webhook) Run(ctx context.Context, fire func(Fire)) error {
+	w.mu.Lock()
+	w.fire = fire
+	w.mu.Unlock()
+
+	<-ctx.Done()
+
+	w.mu.Lock()
+	w.fire = nil
+	w.mu.Unlock()
+	return ctx.Err()
+}
+
+func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if w.Secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Webhook-Secret")), []byte(w.Secret)) != 1 {
+		writeStatus(rw, http.StatusUnauthorized, "invalid webhook secret")
+		return
+	}
+
+	w.mu.RLock()
+	fire := w.fire
+	w.mu.RUnlock()
+	if fire == nil {
+		writeStatus(rw, http.StatusServiceUnavailable, "daemon not running")
+		return
+	}
+
+	limit := w.MaxBody
+	if limit == 0 {
+		limit = 1 << 20
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, limit))
+	if err != nil {
+		writeStatus(rw, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+
+	params := map[string]interface{}{}
+	for k, v := range r.URL.Query() {
+		if len(v) == 1 {
+			params[k] = v[0]
+		} else {
+			params[k] = v
+		}
+	}
+	fire(Fire{Trigger: w.String(), Input: string(body), Params: params, Time: time.Now()})
+	writeStatus(rw, http.StatusAccepted, "accepted")
+}
+
+func writeStatus(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"status": msg})
+}
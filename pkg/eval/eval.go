@@ -0,0 +1,259 @@
+// Package eval runs an agent over a set of test cases and scores the results,
+// producing reports for CI quality gates.
+package eval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Case is one evaluation input with the expectations its result must meet.
+// Every expectation that is set must hold for the case to pass.
+type Case struct {
+	ID     string                 `json:"id"`
+	Input  string                 `json:"input"`
+	Params map[string]interface{} `json:"params,omitempty"`
+	Tags   []string               `json:"tags,omitempty"`
+
+	Expected    interface{}            `json:"expected,omitempty"`     // Output must equal this (strings compared trimmed)
+	Contains    []string               `json:"contains,omitempty"`     // Output text must contain each, case-insensitively
+	NotContains []string               `json:"not_contains,omitempty"` // Output text must contain none of these
+	Match       string                 `json:"match,omitempty"`        // Output text must match this regular expression
+	Schema      map[string]interface{} `json:"schema,omitempty"`       // Output must satisfy this JSON schema
+	Tools       []string               `json:"tools,omitempty"`        // Tools that must be called
+}
+
+// Scorer is an additional check run on every successful case; a non-nil
+// error fails the case with its message.
+type Scorer func(c Case, r *agent.Result) error
+
+// Config controls an evaluation run.
+type Config struct {
+	Agent       agent.Agent
+	Concurrency int           // Cases run at once (default 1)
+	Timeout     time.Duration // Per-case timeout (0 = none)
+	Scorers     []Scorer
+	OnResult    func(CaseResult) // Called as each case finishes, e.g. for progress output (optional)
+}
+
+// CaseResult is the outcome of one case. A case either passes, fails its
+// expectations (Failures), or errors before it can be scored (Error).
+type CaseResult struct {
+	ID       string           `json:"id"`
+	Tags     []string         `json:"tags,omitempty"`
+	Passed   bool             `json:"passed"`
+	Failures []string         `json:"failures,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Output   interface{}      `json:"output,omitempty"`
+	Duration time.Duration    `json:"duration_ns"`
+	Usage    agent.TokenUsage `json:"usage"`
+
+	Cohorts map[string]string `json:"cohorts,omitempty"` // Experiment cohorts the case ran in
+}
+
+// Run evaluates every case and returns the report. Cases without an ID are
+// numbered by position. Cancelling ctx errors the cases not yet finished.
+func Run(ctx context.Context, cfg Config, cases []Case) *Report {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	start := time.Now()
+	results := make([]CaseResult, len(cases))
+	sem := make(chan struct{}, cfg.Concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, c := range cases {
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("case-%d", i+1)
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, c Case) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res := runCase(ctx, cfg, c)
+			results[i] = res
+			if cfg.OnResult != nil {
+				mu.Lock()
+				cfg.OnResult(res)
+				mu.Unlock()
+			}
+		}(i, c)
+	}
+	wg.Wait()
+	return newReport(results, time.Since(start))
+}
+
+func runCase(ctx context.Context, cfg Config, c Case) (res CaseResult) {
+	res = CaseResult{ID: c.ID, Tags: c.Tags}
+	start := time.Now()
+	defer func() {
+		res.Duration = time.Since(start)
+		if r := recover(); r != nil {
+			res.Passed = false
+			res.Error = fmt.Sprintf("panic: %v", r)
+		}
+	}()
+
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+	if err := agent.ValidateParams(cfg.Agent, c.Params); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	task := &agent.Task{
+		ID:        agent.NewID(ctx),
+		Input:     c.Input,
+		Params:    c.Params,
+		State:     make(map[string]interface{}),
+		StartedAt: agent.Now(ctx),
+	}
+	for k, v := range c.Params {
+		task.State[k] = v
+	}
+	result, err := cfg.Agent.Execute(ctx, task)
+	if result != nil {
+		res.Output = result.Output
+		res.Usage = result.TotalTokenUsage
+		res.Cohorts, _ = result.Metadata["experiments"].(map[string]string)
+	}
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	res.Failures = check(c, result)
+	for _, score := range cfg.Scorers {
+		if err := score(c, result); err != nil {
+			res.Failures = append(res.Failures, err.Error())
+		}
+	}
+	res.Passed = len(res.Failures) == 0
+	return res
+}
+
+// check returns a description of every expectation the result misses.
+func check(c Case, r *agent.Result) []string {
+	var failures []string
+	text := outputText(r.Output)
+
+	if c.Expected != nil && !equalOutput(c.Expected, r.Output) {
+		failures = append(failures, fmt.Sprintf("output %s does not equal expected %s", truncate(text), truncate(outputText(c.Expected))))
+	}
+	lower := strings.ToLower(text)
+	for _, s := range c.Contains {
+		if !strings.Contains(lower, strings.ToLower(s)) {
+			failures = append(failures, fmt.Sprintf("output does not contain %q", s))
+		}
+	}
+	for _, s := range c.NotContains {
+		if strings.Contains(lower, strings.ToLower(s)) {
+			failures = append(failures, fmt.Sprintf("output contains %q", s))
+		}
+	}
+	if c.Match != "" {
+		re, err := regexp.Compile(c.Match)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("invalid match pattern: %v", err))
+		} else if !re.MatchString(text) {
+			failures = append(failures, fmt.Sprintf("output does not match %q", c.Match))
+		}
+	}
+	if c.Schema != nil {
+		if err := agent.ValidateSchema(c.Schema, r.Output); err != nil {
+			failures = append(failures, fmt.Sprintf("output does not match schema: %v", err))
+		}
+	}
+	if len(c.Tools) > 0 {
+		called := make(map[string]bool)
+		for _, step := range r.Steps {
+			for _, tc := range step.ToolCalls {
+				called[tc.Name] = true
+			}
+		}
+		for _, name := range c.Tools {
+			if !called[name] {
+				failures = append(failures, fmt.Sprintf("tool %s was not called", name))
+			}
+		}
+	}
+	return failures
+}
+
+// equalOutput compares outputs by their JSON form, so structured results
+// decoded from a cases file match the agent's Go values.
+func equalOutput(expected, actual interface{}) bool {
+	if s, ok := expected.(string); ok {
+		a, ok := actual.(string)
+		return ok && strings.TrimSpace(a) == strings.TrimSpace(s)
+	}
+	e, err1 := json.Marshal(expected)
+	a, err2 := json.Marshal(actual)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	var ev, av interface{}
+	json.Unmarshal(e, &ev)
+	json.Unmarshal(a, &av)
+	e, _ = json.Marshal(ev)
+	a, _ = json.Marshal(av)
+	return string(e) == string(a)
+}
+
+func outputText(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func truncate(s string) string {
+	if r := []rune(s); len(r) > 200 {
+		return string(r[:200]) + "…"
+	}
+	return s
+}
+
+// LoadCases reads cases from a JSON lines file. Blank lines and lines
+// starting with # are skipped.
+func LoadCases(path string) ([]Case, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cases []Case
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var c Case
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		cases = append(cases, c)
+	}
+	return cases, scanner.Err()
+}
@@ -0,0 +1,52 @@
+package eval
+
+import (
+	"context"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// ExperimentReport compares the cohorts of a tool experiment over the same
+// cases.
+type ExperimentReport struct {
+	Experiment string  `json:"experiment"`
+	Control    *Report `json:"control"`
+	Treatment  *Report `json:"treatment"`
+	Lift       float64 `json:"lift"` // Treatment pass rate minus control pass rate
+}
+
+// RunExperiment runs every case once in each cohort of the named experiment,
+// pinning the cohort through Params["cohorts"], so the pass rates show
+// whether the experiment's tools help. cfg.Agent must be wrapped with
+// agent.WithToolExperiments.
+func RunExperiment(ctx context.Context, cfg Config, cases []Case, experiment string) *ExperimentReport {
+	report := &ExperimentReport{
+		Experiment: experiment,
+		Control:    Run(ctx, cfg, withCohort(cases, experiment, agent.CohortControl)),
+		Treatment:  Run(ctx, cfg, withCohort(cases, experiment, agent.CohortTreatment)),
+	}
+	report.Lift = report.Treatment.PassRate - report.Control.PassRate
+	return report
+}
+
+// withCohort returns copies of cases pinned to cohort of experiment.
+func withCohort(cases []Case, experiment, cohort string) []Case {
+	pinned := make([]Case, len(cases))
+	for i, c := range cases {
+		params := make(map[string]interface{}, len(c.Params)+1)
+		for k, v := range c.Params {
+			params[k] = v
+		}
+		cohorts := map[string]interface{}{}
+		if existing, ok := c.Params[agent.ParamCohorts].(map[string]interface{}); ok {
+			for k, v := range existing {
+				cohorts[k] = v
+			}
+		}
+		cohorts[experiment] = cohort
+		params[agent.ParamCohorts] = cohorts
+		c.Params = params
+		pinned[i] = c
+	}
+	return pinned
+}
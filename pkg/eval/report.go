@@ -0,0 +1,105 @@
+package eval
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Report summarizes an evaluation run.
+type Report struct {
+	Total    int              `json:"total"`
+	Passed   int              `json:"passed"`
+	Failed   int              `json:"failed"`
+	Errored  int              `json:"errored"`
+	PassRate float64          `json:"pass_rate"`
+	Duration time.Duration    `json:"duration_ns"`
+	Usage    agent.TokenUsage `json:"usage"`
+	Cases    []CaseResult     `json:"cases"`
+}
+
+func newReport(results []CaseResult, d time.Duration) *Report {
+	r := &Report{Total: len(results), Duration: d, Cases: results}
+	for _, c := range results {
+		switch {
+		case c.Passed:
+			r.Passed++
+		case c.Error != "":
+			r.Errored++
+		default:
+			r.Failed++
+		}
+		r.Usage.PromptTokens += c.Usage.PromptTokens
+		r.Usage.CompletionTokens += c.Usage.CompletionTokens
+		r.Usage.TotalTokens += c.Usage.TotalTokens
+		r.Usage.ReasoningTokens += c.Usage.ReasoningTokens
+	}
+	if r.Total > 0 {
+		r.PassRate = float64(r.Passed) / float64(r.Total)
+	}
+	return r
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteJUnit writes the report as JUnit XML, one test case per eval case:
+// missed expectations are failures and execution errors are errors.
+func (r *Report) WriteJUnit(w io.Writer, suite string) error {
+	type message struct {
+		Message string `xml:"message,attr"`
+		Body    string `xml:",chardata"`
+	}
+	type testCase struct {
+		Name      string   `xml:"name,attr"`
+		Classname string   `xml:"classname,attr"`
+		Time      string   `xml:"time,attr"`
+		Failure   *message `xml:"failure,omitempty"`
+		Error     *message `xml:"error,omitempty"`
+	}
+	type testSuite struct {
+		XMLName  xml.Name   `xml:"testsuite"`
+		Name     string     `xml:"name,attr"`
+		Tests    int        `xml:"tests,attr"`
+		Failures int        `xml:"failures,attr"`
+		Errors   int        `xml:"errors,attr"`
+		Time     string     `xml:"time,attr"`
+		Cases    []testCase `xml:"testcase"`
+	}
+
+	ts := testSuite{Name: suite, Tests: r.Total, Failures: r.Failed, Errors: r.Errored, Time: seconds(r.Duration)}
+	for _, c := range r.Cases {
+		tc := testCase{Name: c.ID, Classname: suite, Time: seconds(c.Duration)}
+		switch {
+		case c.Error != "":
+			tc.Error = &message{Message: c.Error, Body: c.Error}
+		case !c.Passed:
+			tc.Failure = &message{Message: c.Failures[0], Body: strings.Join(c.Failures, "\n")}
+		}
+		ts.Cases = append(ts.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(ts); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
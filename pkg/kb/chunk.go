@@ -0,0 +1,49 @@
+package kb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Chunker splits a document into chunks for embedding.
+type Chunker interface {
+	Chunk(doc Document) []Chunk
+}
+
+// WordChunker splits text into windows of Size words, each overlapping the
+// previous one by Overlap words.
+type WordChunker struct {
+	Size    int
+	Overlap int
+}
+
+func (c *WordChunker) Chunk(doc Document) []Chunk {
+	words := strings.Fields(doc.Text)
+	if len(words) == 0 {
+		return nil
+	}
+
+	stride := c.Size - c.Overlap
+	if stride <= 0 {
+		stride = c.Size
+	}
+
+	var chunks []Chunk
+	for start := 0; start < len(words); start += stride {
+		end := start + c.Size
+		if end > len(words) {
+			end = len(words)
+		}
+		chunks = append(chunks, Chunk{
+			ID:       fmt.Sprintf("%s#%d", doc.ID, len(chunks)),
+			DocID:    doc.ID,
+			Source:   doc.Source,
+			Text:     strings.Join(words[start:end], " "),
+			Metadata: doc.Metadata,
+		})
+		if end == len(words) {
+			break
+		}
+	}
+	return chunks
+}
@@ -0,0 +1,336 @@
+package kb
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sync"
+)
+
+// HNSWConfig holds configuration for creating an HNSWIndex.
+type HNSWConfig struct {
+	M              int   // Neighbors per node and layer; twice this on layer 0 (default 16)
+	EfConstruction int   // Candidate list size while inserting (default 200)
+	EfSearch       int   // Candidate list size while searching, at least k (default 64)
+	Seed           int64 // Seeds level assignment, so builds are reproducible
+}
+
+// HNSWIndex is an approximate nearest-neighbor index over a Hierarchical
+// Navigable Small World graph, held in memory. Searches visit a small part
+// of the collection, so it suits collections too large for MemoryIndex
+// without running a vector database. Save and LoadHNSW persist it.
+type HNSWIndex struct {
+	mu       sync.RWMutex
+	cfg      HNSWConfig
+	rng      *rand.Rand
+	nodes    []*hnswNode
+	entry    int // Entry point node, -1 while empty
+	maxLevel int
+}
+
+type hnswNode struct {
+	chunk     Chunk
+	vec       []float32 // Normalized copy of chunk.Vector
+	neighbors [][]int   // Per layer, 0 up to the node's level
+}
+
+// NewHNSWIndex creates a new empty HNSWIndex.
+func NewHNSWIndex(cfg HNSWConfig) *HNSWIndex {
+	if cfg.M <= 0 {
+		cfg.M = 16
+	}
+	if cfg.EfConstruction <= 0 {
+		cfg.EfConstruction = 200
+	}
+	if cfg.EfSearch <= 0 {
+		cfg.EfSearch = 64
+	}
+	return &HNSWIndex{cfg: cfg, rng: rand.New(rand.NewSource(cfg.Seed)), entry: -1}
+}
+
+// Len returns the number of indexed chunks.
+func (idx *HNSWIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.nodes)
+}
+
+func (idx *HNSWIndex) Add(ctx context.Context, chunks []Chunk) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, c := range chunks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(idx.nodes) > 0 && len(c.Vector) != len(idx.nodes[0].vec) {
+			return fmt.Errorf("chunk %s has %d dimensions, index has %d", c.ID, len(c.Vector), len(idx.nodes[0].vec))
+		}
+		idx.insert(c)
+	}
+	return nil
+}
+
+func (idx *HNSWIndex) Search(ctx context.Context, vector []float32, k int) ([]Hit, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	if idx.entry < 0 {
+		return nil, nil
+	}
+	if len(vector) != len(idx.nodes[0].vec) {
+		return nil, fmt.Errorf("query has %d dimensions, index has %d", len(vector), len(idx.nodes[0].vec))
+	}
+	if k <= 0 {
+		k = len(idx.nodes)
+	}
+
+	q := normalize(vector)
+	ep := idx.entry
+	for l := idx.maxLevel; l > 0; l-- {
+		ep = idx.searchLayer(q, ep, 1, l)[0].id
+	}
+	found := idx.searchLayer(q, ep, max(idx.cfg.EfSearch, k), 0)
+	if len(found) > k {
+		found = found[:k]
+	}
+	hits := make([]Hit, len(found))
+	for i, f := range found {
+		hits[i] = Hit{Chunk: idx.nodes[f.id].chunk, Score: 1 - f.dist}
+	}
+	return hits, nil
+}
+
+func (idx *HNSWIndex) insert(c Chunk) {
+	id := len(idx.nodes)
+	level := int(-math.Log(1-idx.rng.Float64()) / math.Log(float64(idx.cfg.M)))
+	n := &hnswNode{chunk: c, vec: normalize(c.Vector), neighbors: make([][]int, level+1)}
+	idx.nodes = append(idx.nodes, n)
+	if idx.entry < 0 {
+		idx.entry, idx.maxLevel = id, level
+		return
+	}
+
+	ep := idx.entry
+	for l := idx.maxLevel; l > level; l-- {
+		ep = idx.searchLayer(n.vec, ep, 1, l)[0].id
+	}
+	for l := min(level, idx.maxLevel); l >= 0; l-- {
+		found := idx.searchLayer(n.vec, ep, idx.cfg.EfConstruction, l)
+		ep = found[0].id
+		for _, f := range found[:min(len(found), idx.cfg.M)] {
+			n.neighbors[l] = append(n.neighbors[l], f.id)
+			idx.connect(f.id, id, l)
+		}
+	}
+	if level > idx.maxLevel {
+		idx.entry, idx.maxLevel = id, level
+	}
+}
+
+// connect adds to as a neighbor of from on layer l, dropping the farthest
+// neighbor once from has more than the layer allows.
+func (idx *HNSWIndex) connect(from, to, l int) {
+	n := idx.nodes[from]
+	n.neighbors[l] = append(n.neighbors[l], to)
+	limit := idx.cfg.M
+	if l == 0 {
+		limit *= 2
+	}
+	if len(n.neighbors[l]) <= limit {
+		return
+	}
+	worst, worstDist := 0, -1.0
+	for i, nb := range n.neighbors[l] {
+		if d := distance(n.vec, idx.nodes[nb].vec); d > worstDist {
+			worst, worstDist = i, d
+		}
+	}
+	n.neighbors[l] = append(n.neighbors[l][:worst], n.neighbors[l][worst+1:]...)
+}
+
+// searchLayer returns up to ef nodes of layer l closest to q, closest first,
+// by a best-first walk of the graph from ep.
+func (idx *HNSWIndex) searchLayer(q []float32, ep, ef, l int) []hnswCandidate {
+	start := hnswCandidate{ep, distance(q, idx.nodes[ep].vec)}
+	visited := map[int]bool{ep: true}
+	candidates := &candidateHeap{items: []hnswCandidate{start}}
+	results := &candidateHeap{items: []hnswCandidate{start}, farthest: true}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(hnswCandidate)
+		if c.dist > results.items[0].dist && results.Len() >= ef {
+			break
+		}
+		for _, nb := range idx.nodes[c.id].neighbors[l] {
+			if visited[nb] {
+				continue
+			}
+			visited[nb] = true
+			d := distance(q, idx.nodes[nb].vec)
+			if results.Len() < ef || d < results.items[0].dist {
+				heap.Push(candidates, hnswCandidate{nb, d})
+				heap.Push(results, hnswCandidate{nb, d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	out := make([]hnswCandidate, results.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(results).(hnswCandidate)
+	}
+	return out
+}
+
+type hnswCandidate struct {
+	id   int
+	dist float64
+}
+
+// candidateHeap is a min-heap of candidates by distance, or a max-heap with
+// farthest set.
+type candidateHeap struct {
+	items    []hnswCandidate
+	farthest bool
+}
+
+func (h *candidateHeap) Len() int { return len(h.items) }
+func (h *candidateHeap) Less(i, j int) bool {
+	if h.farthest {
+		return h.items[i].dist > h.items[j].dist
+	}
+	return h.items[i].dist < h.items[j].dist
+}
+func (h *candidateHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *candidateHeap) Push(x interface{}) { h.items = append(h.items, x.(hnswCandidate)) }
+func (h *candidateHeap) Pop() interface{} {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// distance is the cosine distance of two normalized vectors.
+func distance(a, b []float32) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return 1 - dot
+}
+
+func normalize(v []float32) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	out := make([]float32, len(v))
+	if norm == 0 {
+		return out
+	}
+	norm = math.Sqrt(norm)
+	for i, x := range v {
+		out[i] = float32(float64(x) / norm)
+	}
+	return out
+}
+
+// hnswSnapshot is the persisted form of an HNSWIndex. Metadata values must
+// be gob-encodable; register custom types with gob.Register.
+type hnswSnapshot struct {
+	Version   int
+	Config    HNSWConfig
+	Chunks    []Chunk
+	Neighbors [][][]int
+	Entry     int
+	MaxLevel  int
+}
+
+const hnswSnapshotVersion = 1
+
+// Save writes a snapshot of the index to w.
+func (idx *HNSWIndex) Save(w io.Writer) error {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	snap := hnswSnapshot{Version: hnswSnapshotVersion, Config: idx.cfg, Entry: idx.entry, MaxLevel: idx.maxLevel}
+	for _, n := range idx.nodes {
+		snap.Chunks = append(snap.Chunks, n.chunk)
+		snap.Neighbors = append(snap.Neighbors, n.neighbors)
+	}
+	return gob.NewEncoder(w).Encode(snap)
+}
+
+// SaveFile writes a snapshot of the index to path, replacing it atomically.
+func (idx *HNSWIndex) SaveFile(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = idx.Save(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadHNSW reads an index written by Save. The graph is restored as saved,
+// so loading does not rebuild it.
+func LoadHNSW(r io.Reader) (*HNSWIndex, error) {
+	var snap hnswSnapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("decoding HNSW snapshot: %w", err)
+	}
+	if snap.Version != hnswSnapshotVersion {
+		return nil, fmt.Errorf("unsupported HNSW snapshot version %d", snap.Version)
+	}
+	if len(snap.Neighbors) != len(snap.Chunks) || snap.Entry >= len(snap.Chunks) {
+		return nil, errors.New("corrupt HNSW snapshot")
+	}
+
+	idx := NewHNSWIndex(snap.Config)
+	// Continue the level sequence from where the saved index left off
+	for range snap.Chunks {
+		idx.rng.Float64()
+	}
+	idx.entry, idx.maxLevel = snap.Entry, snap.MaxLevel
+	if len(snap.Chunks) == 0 {
+		idx.entry = -1
+	}
+	for i, c := range snap.Chunks {
+		for _, layer := range snap.Neighbors[i] {
+			for _, nb := range layer {
+				if nb < 0 || nb >= len(snap.Chunks) {
+					return nil, errors.New("corrupt HNSW snapshot")
+				}
+			}
+		}
+		idx.nodes = append(idx.nodes, &hnswNode{chunk: c, vec: normalize(c.Vector), neighbors: snap.Neighbors[i]})
+	}
+	return idx, nil
+}
+
+// LoadHNSWFile reads an index written by SaveFile.
+func LoadHNSWFile(path string) (*HNSWIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadHNSW(bufio.NewReader(f))
+}
@@ -0,0 +1,152 @@
+// Package kb provides a batteries-included retrieval path: load documents,
+// chunk them, embed the chunks, index them, and search them from agents via
+// the kb_search tool.
+package kb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Document is a unit of source content before chunking.
+type Document struct {
+	ID       string
+	Source   string // File path, URL, or other origin
+	Text     string
+	Metadata map[string]interface{}
+}
+
+// Chunk is an indexed slice of a document.
+type Chunk struct {
+	ID       string
+	DocID    string
+	Source   string
+	Text     string
+	Vector   []float32
+	Metadata map[string]interface{}
+}
+
+// Hit is a search result.
+type Hit struct {
+	Chunk Chunk
+	Score float64 // Cosine similarity; higher is closer
+}
+
+// Embedder turns texts into vectors. Implementations typically call an
+// embeddings API.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Index stores embedded chunks and answers nearest-neighbor queries.
+// Implementations must be safe for concurrent use.
+type Index interface {
+	Add(ctx context.Context, chunks []Chunk) error
+	Search(ctx context.Context, vector []float32, k int) ([]Hit, error)
+}
+
+// Config holds configuration for creating a KnowledgeBase.
+type Config struct {
+	Name      string
+	Embedder  Embedder
+	Chunker   Chunker // Defaults to a 200-word chunker with 40 words of overlap
+	Index     Index   // Defaults to a MemoryIndex; see HNSWIndex for large collections
+	BatchSize int     // Chunks per Embed call (default 64)
+}
+
+// KnowledgeBase is a named, searchable collection of documents.
+type KnowledgeBase struct {
+	cfg Config
+	mu  sync.Mutex // Serializes ingestion
+}
+
+// IngestStats summarizes an Ingest call.
+type IngestStats struct {
+	Documents int
+	Chunks    int
+}
+
+// New creates a KnowledgeBase from the given configuration.
+func New(cfg Config) *KnowledgeBase {
+	if cfg.Chunker == nil {
+		cfg.Chunker = &WordChunker{Size: 200, Overlap: 40}
+	}
+	if cfg.Index == nil {
+		cfg.Index = NewMemoryIndex()
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 64
+	}
+	return &KnowledgeBase{cfg: cfg}
+}
+
+// Name returns the knowledge base name.
+func (kb *KnowledgeBase) Name() string {
+	return kb.cfg.Name
+}
+
+// Index returns the underlying index.
+func (kb *KnowledgeBase) Index() Index {
+	return kb.cfg.Index
+}
+
+// Ingest loads, chunks, embeds, and indexes documents from every source.
+func (kb *KnowledgeBase) Ingest(ctx context.Context, sources ...Source) (IngestStats, error) {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+
+	var stats IngestStats
+	for _, src := range sources {
+		docs, err := src.Load(ctx)
+		if err != nil {
+			return stats, fmt.Errorf("load %s: %w", src, err)
+		}
+
+		for _, doc := range docs {
+			chunks := kb.cfg.Chunker.Chunk(doc)
+			for start := 0; start < len(chunks); start += kb.cfg.BatchSize {
+				end := start + kb.cfg.BatchSize
+				if end > len(chunks) {
+					end = len(chunks)
+				}
+				if err := kb.embedAndAdd(ctx, chunks[start:end]); err != nil {
+					return stats, fmt.Errorf("index %s: %w", doc.Source, err)
+				}
+			}
+			stats.Documents++
+			stats.Chunks += len(chunks)
+		}
+	}
+	return stats, nil
+}
+
+func (kb *KnowledgeBase) embedAndAdd(ctx context.Context, chunks []Chunk) error {
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.Text
+	}
+	vectors, err := kb.cfg.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(vectors) != len(chunks) {
+		return fmt.Errorf("embedder returned %d vectors for %d chunks", len(vectors), len(chunks))
+	}
+	for i := range chunks {
+		chunks[i].Vector = vectors[i]
+	}
+	return kb.cfg.Index.Add(ctx, chunks)
+}
+
+// Search returns the k chunks most similar to query.
+func (kb *KnowledgeBase) Search(ctx context.Context, query string, k int) ([]Hit, error) {
+	vectors, err := kb.cfg.Embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("embedder returned no vector for query")
+	}
+	return kb.cfg.Index.Search(ctx, vectors[0], k)
+}
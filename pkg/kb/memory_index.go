@@ -0,0 +1,58 @@
+package kb
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+)
+
+// MemoryIndex is an exact, brute-force cosine-similarity index held in
+// memory. It suits small collections.
+type MemoryIndex struct {
+	mu     sync.RWMutex
+	chunks []Chunk
+}
+
+// NewMemoryIndex creates a new empty MemoryIndex.
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{}
+}
+
+func (idx *MemoryIndex) Add(ctx context.Context, chunks []Chunk) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.chunks = append(idx.chunks, chunks...)
+	return nil
+}
+
+func (idx *MemoryIndex) Search(ctx context.Context, vector []float32, k int) ([]Hit, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	hits := make([]Hit, 0, len(idx.chunks))
+	for _, c := range idx.chunks {
+		hits = append(hits, Hit{Chunk: c, Score: cosine(vector, c.Vector)})
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if k > 0 && len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits, nil
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
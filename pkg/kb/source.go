@@ -0,0 +1,113 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source loads documents for ingestion.
+type Source interface {
+	Load(ctx context.Context) ([]Document, error)
+	String() string
+}
+
+// TextSource is an in-memory document.
+type TextSource struct {
+	ID   string
+	Text string
+}
+
+func (s TextSource) Load(ctx context.Context) ([]Document, error) {
+	return []Document{{ID: s.ID, Source: s.ID, Text: s.Text}}, nil
+}
+
+func (s TextSource) String() string {
+	return "text:" + s.ID
+}
+
+// FileSource loads a file, or every file under a directory whose extension
+// is in Extensions (default .txt and .md).
+type FileSource struct {
+	Path       string
+	Extensions []string
+}
+
+func (s FileSource) Load(ctx context.Context) ([]Document, error) {
+	exts := s.Extensions
+	if len(exts) == 0 {
+		exts = []string{".txt", ".md"}
+	}
+
+	var docs []Document
+	err := filepath.WalkDir(s.Path, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || (path != s.Path && !hasExt(path, exts)) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, Document{ID: path, Source: path, Text: string(data)})
+		return nil
+	})
+	return docs, err
+}
+
+func (s FileSource) String() string {
+	return "file:" + s.Path
+}
+
+func hasExt(path string, exts []string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// URLSource fetches a document over HTTP.
+type URLSource struct {
+	URL    string
+	Client *http.Client // Defaults to http.DefaultClient
+}
+
+func (s URLSource) Load(ctx context.Context) ([]Document, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("fetch %s: status %d", s.URL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return []Document{{ID: s.URL, Source: s.URL, Text: string(data)}}, nil
+}
+
+func (s URLSource) String() string {
+	return "url:" + s.URL
+}
@@ -0,0 +1,67 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+)
+
+// SearchTool exposes a KnowledgeBase to agents as the kb_search tool.
+type SearchTool struct {
+	kb       *KnowledgeBase
+	name     string
+	defaultK int
+}
+
+// NewSearchTool creates a kb_search tool over kb returning up to defaultK
+// chunks (default 5) unless the model asks for a different k.
+func NewSearchTool(kb *KnowledgeBase, defaultK int) *SearchTool {
+	if defaultK == 0 {
+		defaultK = 5
+	}
+	return &SearchTool{kb: kb, name: "kb_search", defaultK: defaultK}
+}
+
+func (t *SearchTool) Name() string {
+	return t.name
+}
+
+func (t *SearchTool) Description() string {
+	return fmt.Sprintf("Search the %q knowledge base for passages relevant to a query.", t.kb.Name())
+}
+
+func (t *SearchTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{"type": "string"},
+			"k":     map[string]interface{}{"type": "integer", "minimum": 1},
+		},
+		"required": []string{"query"},
+	}
+}
+
+func (t *SearchTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	query, _ := args["query"].(string)
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	k := t.defaultK
+	if v, ok := args["k"].(float64); ok && v >= 1 {
+		k = int(v)
+	}
+
+	hits, err := t.kb.Search(ctx, query, k)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]interface{}, 0, len(hits))
+	for _, h := range hits {
+		results = append(results, map[string]interface{}{
+			"source": h.Chunk.Source,
+			"text":   h.Chunk.Text,
+			"score":  h.Score,
+		})
+	}
+	return results, nil
+}
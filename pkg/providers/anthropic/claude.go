@@ -0,0 +1,393 @@
+// Package anthropic provides Anthropic API integrations.
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+	"github.com/sultanfariz/gonostic/pkg/providers"
+)
+
+// DefaultBaseURL is the Anthropic API root.
+const DefaultBaseURL = "https://api.anthropic.com/v1"
+
+// DefaultVersion is the anthropic-version header sent with every request.
+const DefaultVersion = "2023-06-01"
+
+// ClaudeConfig holds configuration for creating a Claude provider.
+type ClaudeConfig struct {
+	APIKey    string             // Used unless the tenant supplies its own via agent.APIKeyFromContext
+	KeyPool   *providers.KeyPool // Rotates across keys with failover on 429/401/403; takes precedence over APIKey
+	BaseURL   string             // Default DefaultBaseURL
+	Version   string             // Default DefaultVersion
+	Model     string             // Default "claude-sonnet-4-5"; CompletionRequest.Model overrides it
+	MaxTokens int                // Default 4096; the API requires a limit, CompletionRequest.MaxTokens overrides it
+	HTTP      *providers.HTTPConfig
+}
+
+// Claude is an agent.ModelProvider for the Anthropic Messages API, with
+// native tool use and image and document inputs. It also implements
+// agent.HealthChecker.
+type Claude struct {
+	cfg    ClaudeConfig
+	client *http.Client
+}
+
+// NewClaude creates a new Claude provider from the given configuration.
+func NewClaude(cfg ClaudeConfig) (*Claude, error) {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	if cfg.Version == "" {
+		cfg.Version = DefaultVersion
+	}
+	if cfg.Model == "" {
+		cfg.Model = "claude-sonnet-4-5"
+	}
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = 4096
+	}
+	httpCfg := providers.DefaultHTTPConfig
+	if cfg.HTTP != nil {
+		httpCfg = *cfg.HTTP
+	}
+	client, err := providers.SharedClient(httpCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Claude{cfg: cfg, client: client}, nil
+}
+
+type message struct {
+	Role    string  `json:"role"`
+	Content []block `json:"content"`
+}
+
+// block is a content block of a message or response.
+type block struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+
+	Source *source `json:"source,omitempty"` // image and document
+
+	ID    string          `json:"id,omitempty"` // tool_use
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	ToolUseID string  `json:"tool_use_id,omitempty"` // tool_result
+	Content   []block `json:"content,omitempty"`
+
+	Thinking string `json:"thinking,omitempty"` // thinking, in responses
+}
+
+type source struct {
+	Type      string `json:"type"` // base64, url, or file
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+	FileID    string `json:"file_id,omitempty"`
+}
+
+type tool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	InputSchema interface{} `json:"input_schema"`
+}
+
+type messagesResponse struct {
+	Model      string  `json:"model"`
+	Content    []block `json:"content"`
+	StopReason string  `json:"stop_reason"`
+	Usage      *struct {
+		InputTokens              int `json:"input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	} `json:"usage"`
+}
+
+// Complete sends req to /messages. The system message becomes the system
+// prompt, assistant tool calls become tool_use blocks, and each run of
+// RoleTool messages becomes one user message of tool_result blocks; without
+// a history, req.Prompt and req.Files make up the user message. The stop
+// reason sets FinishReason, and Finished when the model ended its turn.
+func (c *Claude) Complete(ctx context.Context, req *agent.CompletionRequest) (*agent.ModelResponse, error) {
+	body, err := c.request(req)
+	if err != nil {
+		return nil, err
+	}
+	var out messagesResponse
+	if err := c.do(ctx, http.MethodPost, "/messages", body, &out); err != nil {
+		return nil, err
+	}
+
+	resp := &agent.ModelResponse{Model: out.Model, FinishReason: agent.ParseFinishReason(out.StopReason)}
+	var text, thinking []string
+	for _, b := range out.Content {
+		switch b.Type {
+		case "text":
+			text = append(text, b.Text)
+		case "thinking":
+			thinking = append(thinking, b.Thinking)
+		case "tool_use":
+			var args map[string]interface{}
+			if err := json.Unmarshal(b.Input, &args); err != nil {
+				return nil, fmt.Errorf("decode input of tool call %s: %w", b.Name, err)
+			}
+			resp.ToolCalls = append(resp.ToolCalls, agent.ToolCall{ID: b.ID, Name: b.Name, Arguments: args})
+		}
+	}
+	resp.Content = strings.Join(text, "")
+	resp.Reasoning = strings.Join(thinking, "\n")
+	resp.Finished = resp.Reason() == agent.FinishStop
+	if u := out.Usage; u != nil {
+		prompt := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+		resp.Usage = &agent.TokenUsage{
+			PromptTokens:     prompt,
+			CompletionTokens: u.OutputTokens,
+			TotalTokens:      prompt + u.OutputTokens,
+		}
+	}
+	return resp, nil
+}
+
+// Ping lists the models the key can use, checking the API is reachable and
+// the key is accepted.
+func (c *Claude) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/models", nil, nil)
+}
+
+// request builds the JSON body of a Messages API request.
+func (c *Claude) request(req *agent.CompletionRequest) ([]byte, error) {
+	model := c.cfg.Model
+	if req.Model != "" {
+		model = req.Model
+	}
+	maxTokens := c.cfg.MaxTokens
+	if req.MaxTokens != nil {
+		maxTokens = *req.MaxTokens
+	}
+
+	history := req.History
+	if len(history) == 0 {
+		history = []agent.Message{{Role: agent.RoleUser, Content: req.Prompt, Parts: fileParts(req.Files)}}
+	}
+	var system string
+	if history[0].Role == agent.RoleSystem {
+		system, history = history[0].Content, history[1:]
+	}
+	// The API has no structured output mode; ask for the schema instead
+	if req.OutputSchema != nil {
+		schema, err := json.Marshal(req.OutputSchema)
+		if err != nil {
+			return nil, fmt.Errorf("encode output schema: %w", err)
+		}
+		system = strings.TrimSpace(system + "\n\nRespond only with a JSON value matching this JSON schema:\n" + string(schema))
+	}
+	msgs, err := messages(history)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{"model": model, "max_tokens": maxTokens, "messages": msgs}
+	if system != "" {
+		body["system"] = system
+	}
+	if len(req.Tools) > 0 {
+		tools := make([]tool, len(req.Tools))
+		for i, t := range req.Tools {
+			schema := t.Schema()
+			if schema == nil {
+				schema = map[string]interface{}{"type": "object"}
+			}
+			tools[i] = tool{Name: t.Name(), Description: t.Description(), InputSchema: schema}
+		}
+		body["tools"] = tools
+	}
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	return json.Marshal(body)
+}
+
+// messages converts a history without its system message to API messages.
+// Tool results are sent by the user, and consecutive messages of the same
+// role are merged since the API requires user and assistant to alternate.
+func messages(history []agent.Message) ([]message, error) {
+	var msgs []message
+	for _, m := range history {
+		var role string
+		var content []block
+		switch m.Role {
+		case agent.RoleAssistant:
+			role = "assistant"
+			if m.Content != "" {
+				content = append(content, block{Type: "text", Text: m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				input := json.RawMessage("{}")
+				if len(tc.Arguments) > 0 {
+					b, err := json.Marshal(tc.Arguments)
+					if err != nil {
+						return nil, fmt.Errorf("encode arguments of tool call %s: %w", tc.Name, err)
+					}
+					input = b
+				}
+				content = append(content, block{Type: "tool_use", ID: tc.ID, Name: tc.Name, Input: input})
+			}
+		case agent.RoleTool:
+			role = "user"
+			result, err := blocks(m.Content, m.Parts)
+			if err != nil {
+				return nil, err
+			}
+			content = []block{{Type: "tool_result", ToolUseID: m.ToolCallID, Content: result}}
+		case agent.RoleUser:
+			role = "user"
+			b, err := blocks(m.Content, m.Parts)
+			if err != nil {
+				return nil, err
+			}
+			content = b
+		default:
+			return nil, fmt.Errorf("unsupported %s message in history", m.Role)
+		}
+		if len(content) == 0 {
+			continue
+		}
+		if n := len(msgs); n > 0 && msgs[n-1].Role == role {
+			msgs[n-1].Content = append(msgs[n-1].Content, content...)
+			continue
+		}
+		msgs = append(msgs, message{Role: role, Content: content})
+	}
+	return msgs, nil
+}
+
+// fileParts turns request files into message parts.
+func fileParts(files []agent.FileInput) []agent.Part {
+	parts := make([]agent.Part, 0, len(files))
+	for _, f := range files {
+		var data interface{} = f.Content
+		switch {
+		case f.URI != "":
+			data = f.URI
+		case f.ContentReader != nil:
+			data = f
+		}
+		parts = append(parts, agent.Part{Type: f.Type, Data: data})
+	}
+	return parts
+}
+
+// blocks renders text followed by parts as content blocks. Images and PDFs
+// are sent as base64 sources, other text as text blocks.
+func blocks(text string, parts []agent.Part) ([]block, error) {
+	var out []block
+	if text != "" {
+		out = append(out, block{Type: "text", Text: text})
+	}
+	for _, p := range parts {
+		kind := "document"
+		if strings.HasPrefix(p.Type, "image/") {
+			kind = "image"
+		}
+		var content []byte
+		switch d := p.Data.(type) {
+		case nil:
+			if p.Text != "" {
+				out = append(out, block{Type: "text", Text: p.Text})
+			}
+			continue
+		case string:
+			// An uploaded file's ID or URI
+			switch {
+			case strings.HasPrefix(d, "file_"):
+				out = append(out, block{Type: kind, Source: &source{Type: "file", FileID: d}})
+			case kind == "image" || p.Type == "application/pdf":
+				out = append(out, block{Type: kind, Source: &source{Type: "url", URL: d}})
+			default:
+				out = append(out, block{Type: "text", Text: d})
+			}
+			continue
+		case []byte:
+			content = d
+		case agent.FileInput:
+			// Read afresh for every request; Reader rewinds seekable content
+			b, err := d.Bytes()
+			if err != nil {
+				return nil, fmt.Errorf("read %s part: %w", p.Type, err)
+			}
+			content = b
+		default:
+			return nil, fmt.Errorf("unsupported %s part data %T", p.Type, p.Data)
+		}
+
+		if kind == "image" || p.Type == "application/pdf" {
+			data := base64.StdEncoding.EncodeToString(content)
+			out = append(out, block{Type: kind, Source: &source{Type: "base64", MediaType: p.Type, Data: data}})
+		} else {
+			out = append(out, block{Type: "text", Text: string(content)})
+		}
+	}
+	return out, nil
+}
+
+// do sends a request with the tenant's key, a key from the pool, or the
+// configured key, and decodes the JSON response into out, if not nil.
+func (c *Claude) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	key := agent.APIKeyFromContext(ctx)
+	if key == "" && c.cfg.KeyPool != nil {
+		return c.cfg.KeyPool.Do(ctx, func(key string) error {
+			return c.send(ctx, method, path, key, body, out)
+		})
+	}
+	if key == "" {
+		key = c.cfg.APIKey
+	}
+	return c.send(ctx, method, path, key, body, out)
+}
+
+func (c *Claude) send(ctx context.Context, method, path, key string, body []byte, out interface{}) error {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.BaseURL+path, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("x-api-key", key)
+	req.Header.Set("anthropic-version", c.cfg.Version)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return providers.StatusError(resp.StatusCode, string(raw))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
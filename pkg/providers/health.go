@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// CheckResult is the outcome of pinging a single provider.
+type CheckResult struct {
+	Healthy bool          `json:"healthy"`
+	Error   string        `json:"error,omitempty"`
+	Latency time.Duration `json:"latency"`
+}
+
+// HealthReport aggregates provider checks. Healthy is true only if every
+// check succeeded.
+type HealthReport struct {
+	Healthy bool                   `json:"healthy"`
+	Checks  map[string]CheckResult `json:"checks"`
+}
+
+// CheckHealth pings every provider that implements agent.HealthChecker in
+// parallel, bounding each ping by timeout. Providers without Ping are
+// reported healthy.
+func CheckHealth(ctx context.Context, models map[string]agent.ModelProvider, timeout time.Duration) HealthReport {
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	report := HealthReport{Healthy: true, Checks: make(map[string]CheckResult, len(models))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, model := range models {
+		checker, ok := model.(agent.HealthChecker)
+		if !ok {
+			// Pings started earlier in the loop may be writing already
+			mu.Lock()
+			report.Checks[name] = CheckResult{Healthy: true}
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string, checker agent.HealthChecker) {
+			defer wg.Done()
+
+			pingCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := checker.Ping(pingCtx)
+			res := CheckResult{Healthy: err == nil, Latency: time.Since(start)}
+			if err != nil {
+				res.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Checks[name] = res
+			if err != nil {
+				report.Healthy = false
+			}
+			mu.Unlock()
+		}(name, checker)
+	}
+
+	wg.Wait()
+	return report
+}
@@ -0,0 +1,120 @@
+package providers
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// HTTPConfig tunes the HTTP transport used to reach model backends. Zero
+// fields take the defaults noted below. Providers built with the same
+// HTTPConfig share one connection pool through SharedClient, so TLS setup is
+// paid once per host rather than on every turn.
+type HTTPConfig struct {
+	MaxIdleConns          int           // Across all hosts (default 100)
+	MaxIdleConnsPerHost   int           // Kept alive per host (default 32)
+	MaxConnsPerHost       int           // 0 = unlimited
+	IdleConnTimeout       time.Duration // Default 90s
+	DialTimeout           time.Duration // Default 10s
+	KeepAlive             time.Duration // TCP keep-alive period (default 30s)
+	TLSHandshakeTimeout   time.Duration // Default 10s
+	ResponseHeaderTimeout time.Duration // 0 = no limit; streaming responses need headers only
+	Proxy                 string        // Proxy URL; empty uses HTTP_PROXY/HTTPS_PROXY from the environment
+	DisableHTTP2          bool
+	Timeout               time.Duration // Whole-request client timeout (0 = rely on the request context)
+}
+
+// DefaultHTTPConfig is used by providers that are not given an HTTPConfig.
+var DefaultHTTPConfig = HTTPConfig{}
+
+func (c HTTPConfig) withDefaults() HTTPConfig {
+	if c.MaxIdleConns == 0 {
+		c.MaxIdleConns = 100
+	}
+	if c.MaxIdleConnsPerHost == 0 {
+		c.MaxIdleConnsPerHost = 32
+	}
+	if c.IdleConnTimeout == 0 {
+		c.IdleConnTimeout = 90 * time.Second
+	}
+	if c.DialTimeout == 0 {
+		c.DialTimeout = 10 * time.Second
+	}
+	if c.KeepAlive == 0 {
+		c.KeepAlive = 30 * time.Second
+	}
+	if c.TLSHandshakeTimeout == 0 {
+		c.TLSHandshakeTimeout = 10 * time.Second
+	}
+	return c
+}
+
+// Transport builds a new tuned transport. Most callers want SharedClient.
+func (c HTTPConfig) Transport() (*http.Transport, error) {
+	c = c.withDefaults()
+	proxy := http.ProxyFromEnvironment
+	if c.Proxy != "" {
+		u, err := url.Parse(c.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		proxy = http.ProxyURL(u)
+	}
+
+	dialer := &net.Dialer{Timeout: c.DialTimeout, KeepAlive: c.KeepAlive}
+	t := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          c.MaxIdleConns,
+		MaxIdleConnsPerHost:   c.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       c.MaxConnsPerHost,
+		IdleConnTimeout:       c.IdleConnTimeout,
+		TLSHandshakeTimeout:   c.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: c.ResponseHeaderTimeout,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     !c.DisableHTTP2,
+	}
+	if c.DisableHTTP2 {
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return t, nil
+}
+
+var (
+	sharedMu      sync.Mutex
+	sharedClients = map[HTTPConfig]*http.Client{}
+)
+
+// SharedClient returns a client for cfg, creating it on first use. Every
+// caller with an equal HTTPConfig gets the same client and connection pool.
+// Requests carry the W3C trace context of their context (agent.TraceTransport).
+func SharedClient(cfg HTTPConfig) (*http.Client, error) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if c, ok := sharedClients[cfg]; ok {
+		return c, nil
+	}
+	t, err := cfg.Transport()
+	if err != nil {
+		return nil, err
+	}
+	c := &http.Client{Transport: &agent.TraceTransport{Base: t}, Timeout: cfg.Timeout}
+	sharedClients[cfg] = c
+	return c, nil
+}
+
+// DefaultClient returns the shared client for DefaultHTTPConfig.
+func DefaultClient() *http.Client {
+	c, err := SharedClient(DefaultHTTPConfig)
+	if err != nil {
+		// DefaultHTTPConfig was given an invalid proxy; fall back rather than fail every call
+		return http.DefaultClient
+	}
+	return c
+}
@@ -0,0 +1,213 @@
+// Package providers contains shared infrastructure for ModelProvider
+// implementations: API key management, HTTP transport tuning, and health checks.
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoKeysAvailable is returned when every key is revoked, cooling down,
+	// or at its rate limit.
+	ErrNoKeysAvailable = errors.New("no API keys available")
+	// ErrKeyExhausted marks a key as out of quota (e.g., HTTP 429). Wrap it in
+	// errors returned to KeyPool.Do to trigger failover.
+	ErrKeyExhausted = errors.New("API key quota exhausted")
+	// ErrKeyRevoked marks a key as permanently unusable (e.g., HTTP 401/403).
+	ErrKeyRevoked = errors.New("API key revoked")
+)
+
+// KeyPoolConfig controls rotation and rate tracking for a KeyPool.
+type KeyPoolConfig struct {
+	RequestsPerMinute int           // Per-key request limit (0 = unlimited)
+	Cooldown          time.Duration // How long an exhausted key is skipped (default 1 minute)
+}
+
+// KeyStats reports usage for a single key. The key itself is masked.
+type KeyStats struct {
+	Key       string
+	Requests  int
+	Failures  int
+	Revoked   bool
+	Exhausted bool
+	LastUsed  time.Time
+}
+
+type keyState struct {
+	key            string
+	requests       int
+	failures       int
+	revoked        bool
+	exhaustedUntil time.Time
+	lastUsed       time.Time
+	window         []time.Time // Request times within the last minute
+}
+
+// KeyPool rotates requests across multiple API keys for one provider,
+// tracking per-key rate and failing over when a key is exhausted or revoked.
+type KeyPool struct {
+	mu   sync.Mutex
+	keys []*keyState
+	next int
+	cfg  KeyPoolConfig
+}
+
+// NewKeyPool creates a KeyPool over the given keys.
+func NewKeyPool(keys []string, cfg KeyPoolConfig) *KeyPool {
+	if cfg.Cooldown == 0 {
+		cfg.Cooldown = time.Minute
+	}
+	p := &KeyPool{cfg: cfg}
+	for _, k := range keys {
+		p.keys = append(p.keys, &keyState{key: k})
+	}
+	return p
+}
+
+// Acquire returns the next usable key in round-robin order and counts a
+// request against it.
+func (p *KeyPool) Acquire() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for i := 0; i < len(p.keys); i++ {
+		ks := p.keys[(p.next+i)%len(p.keys)]
+		if !p.usable(ks, now) {
+			continue
+		}
+		p.next = (p.next + i + 1) % len(p.keys)
+		ks.requests++
+		ks.lastUsed = now
+		if p.cfg.RequestsPerMinute > 0 {
+			// Only trimmed by usable when there is a limit to enforce
+			ks.window = append(ks.window, now)
+		}
+		return ks.key, nil
+	}
+	return "", ErrNoKeysAvailable
+}
+
+func (p *KeyPool) usable(ks *keyState, now time.Time) bool {
+	if ks.revoked || now.Before(ks.exhaustedUntil) {
+		return false
+	}
+	if p.cfg.RequestsPerMinute <= 0 {
+		return true
+	}
+
+	// Drop requests that fell out of the rolling window
+	cutoff := now.Add(-time.Minute)
+	i := 0
+	for i < len(ks.window) && ks.window[i].Before(cutoff) {
+		i++
+	}
+	ks.window = ks.window[i:]
+	return len(ks.window) < p.cfg.RequestsPerMinute
+}
+
+// ReportExhausted takes the key out of rotation for retryAfter, or the
+// configured cooldown when retryAfter is zero.
+func (p *KeyPool) ReportExhausted(key string, retryAfter time.Duration) {
+	if retryAfter == 0 {
+		retryAfter = p.cfg.Cooldown
+	}
+	p.update(key, func(ks *keyState) {
+		ks.failures++
+		ks.exhaustedUntil = time.Now().Add(retryAfter)
+	})
+}
+
+// ReportRevoked permanently removes the key from rotation.
+func (p *KeyPool) ReportRevoked(key string) {
+	p.update(key, func(ks *keyState) {
+		ks.failures++
+		ks.revoked = true
+	})
+}
+
+func (p *KeyPool) update(key string, fn func(*keyState)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ks := range p.keys {
+		if ks.key == key {
+			fn(ks)
+			return
+		}
+	}
+}
+
+// Do calls fn with a key from the pool. If fn returns an error wrapping
+// ErrKeyExhausted or ErrKeyRevoked, the key is reported and fn is retried
+// with the next available key. Once no key is left, the error joins
+// ErrNoKeysAvailable with fn's last error, so callers can tell quota
+// exhaustion from revocation.
+func (p *KeyPool) Do(ctx context.Context, fn func(key string) error) error {
+	var lastErr error
+	for {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(err, lastErr)
+		}
+		key, err := p.Acquire()
+		if err != nil {
+			return errors.Join(err, lastErr)
+		}
+
+		err = fn(key)
+		lastErr = err
+		switch {
+		case errors.Is(err, ErrKeyRevoked):
+			p.ReportRevoked(key)
+		case errors.Is(err, ErrKeyExhausted):
+			p.ReportExhausted(key, 0)
+		default:
+			return err
+		}
+	}
+}
+
+// Stats returns usage for each key in the pool.
+func (p *KeyPool) Stats() []KeyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	stats := make([]KeyStats, 0, len(p.keys))
+	for _, ks := range p.keys {
+		stats = append(stats, KeyStats{
+			Key:       MaskKey(ks.key),
+			Requests:  ks.requests,
+			Failures:  ks.failures,
+			Revoked:   ks.revoked,
+			Exhausted: now.Before(ks.exhaustedUntil),
+			LastUsed:  ks.lastUsed,
+		})
+	}
+	return stats
+}
+
+// MaskKey hides all but the last four characters of an API key.
+func MaskKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
+
+// StatusError maps an HTTP status code from a provider API to ErrKeyExhausted
+// or ErrKeyRevoked where applicable, wrapping the response body for context.
+func StatusError(code int, body string) error {
+	switch code {
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %s", ErrKeyExhausted, body)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %s", ErrKeyRevoked, body)
+	default:
+		return fmt.Errorf("provider returned status %d: %s", code, body)
+	}
+}
@@ -0,0 +1,411 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+	"github.com/sultanfariz/gonostic/pkg/providers"
+)
+
+// ChatConfig holds configuration for creating a Chat provider.
+type ChatConfig struct {
+	APIKey  string             // Used unless the tenant supplies its own via agent.APIKeyFromContext
+	KeyPool *providers.KeyPool // Rotates across keys with failover on 429/401/403; takes precedence over APIKey
+	BaseURL string             // Default DefaultBaseURL; any OpenAI-compatible server works
+	Model   string             // Default "gpt-4o-mini"; CompletionRequest.Model overrides it
+	HTTP    *providers.HTTPConfig
+}
+
+// Chat is an agent.ModelProvider for the OpenAI Chat Completions API, with
+// native tool calling, image and file inputs, and structured output. It
+// also implements agent.StreamingModelProvider and agent.HealthChecker.
+type Chat struct {
+	cfg    ChatConfig
+	client *http.Client
+}
+
+// NewChat creates a new Chat provider from the given configuration.
+func NewChat(cfg ChatConfig) (*Chat, error) {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	if cfg.Model == "" {
+		cfg.Model = "gpt-4o-mini"
+	}
+	httpCfg := providers.DefaultHTTPConfig
+	if cfg.HTTP != nil {
+		httpCfg = *cfg.HTTP
+	}
+	client, err := providers.SharedClient(httpCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Chat{cfg: cfg, client: client}, nil
+}
+
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    interface{}    `json:"content"` // string, []contentPart, or nil
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+	File     *filePart `json:"file,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+type filePart struct {
+	Filename string `json:"filename,omitempty"`
+	FileData string `json:"file_data,omitempty"` // Data URL
+	FileID   string `json:"file_id,omitempty"`
+}
+
+type chatToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"` // JSON-encoded
+	} `json:"function"`
+}
+
+type chatResponse struct {
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   *chatUsage   `json:"usage"`
+}
+
+type chatChoice struct {
+	Message      chatReply `json:"message"`
+	FinishReason string    `json:"finish_reason"`
+}
+
+type chatReply struct {
+	Content          *string        `json:"content"`
+	Refusal          string         `json:"refusal"`
+	ReasoningContent string         `json:"reasoning_content"` // Some compatible servers
+	ToolCalls        []chatToolCall `json:"tool_calls"`
+}
+
+type chatUsage struct {
+	PromptTokens            int `json:"prompt_tokens"`
+	CompletionTokens        int `json:"completion_tokens"`
+	TotalTokens             int `json:"total_tokens"`
+	CompletionTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+}
+
+// Complete sends req to /chat/completions. The history is sent as is, with
+// RoleTool messages as "tool" messages; without a history, req.Prompt and
+// req.Files make up the user message. Images are sent inline as base64 data
+// URLs, text files as text, and other files (e.g. PDFs) as file parts.
+func (c *Chat) Complete(ctx context.Context, req *agent.CompletionRequest) (*agent.ModelResponse, error) {
+	body, err := c.request(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var out chatResponse
+	if err := c.do(ctx, http.MethodPost, "/chat/completions", data, decodeJSON(&out)); err != nil {
+		return nil, err
+	}
+	return out.response()
+}
+
+// response converts the first choice to a ModelResponse.
+func (out *chatResponse) response() (*agent.ModelResponse, error) {
+	if len(out.Choices) == 0 {
+		return nil, errors.New("chat completion response has no choices")
+	}
+
+	choice := out.Choices[0]
+	resp := &agent.ModelResponse{
+		Reasoning:    choice.Message.ReasoningContent,
+		Model:        out.Model,
+		FinishReason: agent.ParseFinishReason(choice.FinishReason),
+	}
+	if choice.Message.Content != nil {
+		resp.Content = *choice.Message.Content
+	} else if choice.Message.Refusal != "" {
+		resp.Content = choice.Message.Refusal
+		resp.FinishReason = agent.FinishContentFilter
+	}
+	for _, tc := range choice.Message.ToolCalls {
+		var args map[string]interface{}
+		if s := strings.TrimSpace(tc.Function.Arguments); s != "" {
+			if err := json.Unmarshal([]byte(s), &args); err != nil {
+				return nil, fmt.Errorf("decode arguments of tool call %s: %w", tc.Function.Name, err)
+			}
+		}
+		resp.ToolCalls = append(resp.ToolCalls, agent.ToolCall{ID: tc.ID, Name: tc.Function.Name, Arguments: args})
+	}
+	resp.Finished = resp.Reason() == agent.FinishStop
+	if u := out.Usage; u != nil {
+		resp.Usage = &agent.TokenUsage{
+			PromptTokens:     u.PromptTokens,
+			CompletionTokens: u.CompletionTokens,
+			TotalTokens:      u.TotalTokens,
+			ReasoningTokens:  u.CompletionTokensDetails.ReasoningTokens,
+		}
+	}
+	return resp, nil
+}
+
+// Ping lists the models the key can use, checking the API is reachable and
+// the key is accepted.
+func (c *Chat) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/models", nil, decodeJSON(nil))
+}
+
+// request builds the JSON body of a chat completion request.
+func (c *Chat) request(req *agent.CompletionRequest) (map[string]interface{}, error) {
+	model := c.cfg.Model
+	if req.Model != "" {
+		model = req.Model
+	}
+	strict := req.Constraints != nil && req.Constraints.StrictSchema
+
+	history := req.History
+	if len(history) == 0 {
+		history = []agent.Message{{Role: agent.RoleUser, Content: req.Prompt, Parts: fileParts(req.Files)}}
+	}
+	msgs, err := chatMessages(history)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{"model": model, "messages": msgs}
+	if len(req.Tools) > 0 {
+		tools := make([]map[string]interface{}, len(req.Tools))
+		for i, t := range req.Tools {
+			fn := map[string]interface{}{"name": t.Name(), "description": t.Description(), "parameters": t.Schema()}
+			if strict {
+				fn["strict"] = true
+			}
+			tools[i] = map[string]interface{}{"type": "function", "function": fn}
+		}
+		body["tools"] = tools
+	}
+	if req.OutputSchema != nil {
+		body["response_format"] = map[string]interface{}{
+			"type": "json_schema",
+			"json_schema": map[string]interface{}{
+				"name":   "output",
+				"schema": req.OutputSchema,
+				"strict": strict,
+			},
+		}
+	}
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	if req.MaxTokens != nil {
+		body["max_tokens"] = *req.MaxTokens
+	}
+	return body, nil
+}
+
+// chatMessages converts a history to chat messages. Tool messages only
+// hold text, so media parts of a run of tool results follow it in a user
+// message.
+func chatMessages(history []agent.Message) ([]chatMessage, error) {
+	msgs := make([]chatMessage, 0, len(history))
+	var media []contentPart // Of the current run of tool results
+	for i, m := range history {
+		msg := chatMessage{Role: string(m.Role), Content: m.Content}
+		switch m.Role {
+		case agent.RoleTool:
+			msg.ToolCallID = m.ToolCallID
+			parts, err := contentParts("", m.Parts)
+			if err != nil {
+				return nil, err
+			}
+			media = append(media, parts...)
+		case agent.RoleAssistant:
+			if m.Content == "" && len(m.ToolCalls) > 0 {
+				msg.Content = nil
+			}
+			for _, tc := range m.ToolCalls {
+				args, err := json.Marshal(tc.Arguments)
+				if err != nil {
+					return nil, fmt.Errorf("encode arguments of tool call %s: %w", tc.Name, err)
+				}
+				call := chatToolCall{ID: tc.ID, Type: "function"}
+				call.Function.Name, call.Function.Arguments = tc.Name, string(args)
+				msg.ToolCalls = append(msg.ToolCalls, call)
+			}
+		default:
+			if len(m.Parts) > 0 {
+				parts, err := contentParts(m.Content, m.Parts)
+				if err != nil {
+					return nil, err
+				}
+				msg.Content = parts
+			}
+		}
+		msgs = append(msgs, msg)
+
+		runEnds := i+1 == len(history) || history[i+1].Role != agent.RoleTool
+		if m.Role == agent.RoleTool && runEnds && len(media) > 0 {
+			msgs = append(msgs, chatMessage{Role: string(agent.RoleUser), Content: media})
+			media = nil
+		}
+	}
+	return msgs, nil
+}
+
+// fileParts turns request files into message parts.
+func fileParts(files []agent.FileInput) []agent.Part {
+	parts := make([]agent.Part, 0, len(files))
+	for _, f := range files {
+		var data interface{} = f.Content
+		switch {
+		case f.URI != "":
+			data = f.URI
+		case f.ContentReader != nil:
+			data = f
+		}
+		parts = append(parts, agent.Part{Type: f.Type, Data: data})
+	}
+	return parts
+}
+
+// contentParts renders text followed by parts as content parts.
+func contentParts(text string, parts []agent.Part) ([]contentPart, error) {
+	var out []contentPart
+	if text != "" {
+		out = append(out, contentPart{Type: "text", Text: text})
+	}
+	for _, p := range parts {
+		var content []byte
+		switch d := p.Data.(type) {
+		case nil:
+			if p.Text != "" {
+				out = append(out, contentPart{Type: "text", Text: p.Text})
+			}
+			continue
+		case string:
+			// An uploaded file's URI
+			if strings.HasPrefix(p.Type, "image/") {
+				out = append(out, contentPart{Type: "image_url", ImageURL: &imageURL{URL: d}})
+			} else if strings.HasPrefix(d, "file-") {
+				out = append(out, contentPart{Type: "file", File: &filePart{FileID: d}})
+			} else {
+				out = append(out, contentPart{Type: "text", Text: d})
+			}
+			continue
+		case []byte:
+			content = d
+		case agent.FileInput:
+			// Read afresh for every request; Reader rewinds seekable content
+			b, err := d.Bytes()
+			if err != nil {
+				return nil, fmt.Errorf("read %s part: %w", p.Type, err)
+			}
+			content = b
+		default:
+			return nil, fmt.Errorf("unsupported %s part data %T", p.Type, p.Data)
+		}
+
+		switch {
+		case strings.HasPrefix(p.Type, "image/"):
+			out = append(out, contentPart{Type: "image_url", ImageURL: &imageURL{URL: dataURL(p.Type, content)}})
+		case strings.HasPrefix(p.Type, "text/"), p.Type == "application/json":
+			out = append(out, contentPart{Type: "text", Text: string(content)})
+		default:
+			out = append(out, contentPart{Type: "file", File: &filePart{FileData: dataURL(p.Type, content)}})
+		}
+	}
+	return out, nil
+}
+
+func dataURL(mime string, content []byte) string {
+	return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(content)
+}
+
+// do sends a request with the tenant's key, a key from the pool, or the
+// configured key, and passes the response body to handle.
+func (c *Chat) do(ctx context.Context, method, path string, body []byte, handle func(io.Reader) error) error {
+	url := c.cfg.BaseURL + path
+	if c.cfg.KeyPool != nil && agent.APIKeyFromContext(ctx) == "" {
+		return c.cfg.KeyPool.Do(ctx, func(key string) error {
+			return send(ctx, c.client, method, url, key, body, handle)
+		})
+	}
+	return send(ctx, c.client, method, url, c.cfg.APIKey, body, handle)
+}
+
+// doJSON sends a request and decodes the JSON response into out, if not nil.
+func doJSON(ctx context.Context, client *http.Client, method, url, apiKey string, body []byte, out interface{}) error {
+	return send(ctx, client, method, url, apiKey, body, decodeJSON(out))
+}
+
+// send sends a request with the tenant's key, else apiKey, and passes the
+// body of a successful response to handle.
+func send(ctx context.Context, client *http.Client, method, url, apiKey string, body []byte, handle func(io.Reader) error) error {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	key := agent.APIKeyFromContext(ctx)
+	if key == "" {
+		key = apiKey
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		raw, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		return providers.StatusError(resp.StatusCode, string(raw))
+	}
+	return handle(resp.Body)
+}
+
+// decodeJSON returns a handler decoding a response into out; a nil out
+// discards the response.
+func decodeJSON(out interface{}) func(io.Reader) error {
+	return func(r io.Reader) error {
+		raw, err := io.ReadAll(r)
+		if err != nil || out == nil {
+			return err
+		}
+		if err := json.Unmarshal(raw, out); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		return nil
+	}
+}
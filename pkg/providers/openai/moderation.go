@@ -0,0 +1,73 @@
+// Package openai provides OpenAI API integrations.
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+	"github.com/sultanfariz/gonostic/pkg/providers"
+)
+
+// DefaultBaseURL is the OpenAI API root.
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// ModeratorConfig holds configuration for creating a Moderator.
+type ModeratorConfig struct {
+	APIKey  string // Used unless the tenant supplies its own via agent.APIKeyFromContext
+	BaseURL string // Default DefaultBaseURL
+	Model   string // Default "omni-moderation-latest"
+	HTTP    *providers.HTTPConfig
+}
+
+// Moderator classifies text with the OpenAI moderation endpoint. It
+// implements agent.Moderator.
+type Moderator struct {
+	cfg    ModeratorConfig
+	client *http.Client
+}
+
+// NewModerator creates a new Moderator from the given configuration.
+func NewModerator(cfg ModeratorConfig) (*Moderator, error) {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	if cfg.Model == "" {
+		cfg.Model = "omni-moderation-latest"
+	}
+	httpCfg := providers.DefaultHTTPConfig
+	if cfg.HTTP != nil {
+		httpCfg = *cfg.HTTP
+	}
+	client, err := providers.SharedClient(httpCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Moderator{cfg: cfg, client: client}, nil
+}
+
+func (m *Moderator) Moderate(ctx context.Context, text string) (*agent.ModerationResult, error) {
+	body, err := json.Marshal(map[string]string{"model": m.cfg.Model, "input": text})
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Results []struct {
+			Flagged        bool               `json:"flagged"`
+			CategoryScores map[string]float64 `json:"category_scores"`
+		} `json:"results"`
+	}
+	if err := doJSON(ctx, m.client, http.MethodPost, m.cfg.BaseURL+"/moderations", m.cfg.APIKey, body, &out); err != nil {
+		return nil, fmt.Errorf("moderation: %w", err)
+	}
+	if len(out.Results) == 0 {
+		return nil, errors.New("moderation response has no results")
+	}
+	r := out.Results[0]
+	return &agent.ModerationResult{Flagged: r.Flagged, Categories: r.CategoryScores}, nil
+}
@@ -0,0 +1,127 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// chatChunk is one server-sent event of a streamed chat completion.
+type chatChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content          string `json:"content"`
+			Refusal          string `json:"refusal"`
+			ReasoningContent string `json:"reasoning_content"`
+			ToolCalls        []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage"`
+}
+
+// CompleteStream behaves like Complete but streams the response, calling
+// onDelta for each chunk of content. Tool call arguments arrive in pieces
+// and are assembled before the response is returned; usage is requested in
+// the final chunk.
+func (c *Chat) CompleteStream(ctx context.Context, req *agent.CompletionRequest, onDelta func(delta string)) (*agent.ModelResponse, error) {
+	body, err := c.request(req)
+	if err != nil {
+		return nil, err
+	}
+	body["stream"] = true
+	body["stream_options"] = map[string]interface{}{"include_usage": true}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var out chatResponse
+	err = c.do(ctx, http.MethodPost, "/chat/completions", data, func(r io.Reader) error {
+		out = chatResponse{}
+		return readStream(r, &out, onDelta)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.response()
+}
+
+// readStream assembles the server-sent events of r into out.
+func readStream(r io.Reader, out *chatResponse, onDelta func(string)) error {
+	var content, refusal, reasoning strings.Builder
+	var calls []chatToolCall
+	var finish string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk chatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("decode stream chunk: %w", err)
+		}
+		if chunk.Model != "" {
+			out.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			out.Usage = chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		choice := chunk.Choices[0]
+		if d := choice.Delta.Content; d != "" {
+			content.WriteString(d)
+			onDelta(d)
+		}
+		refusal.WriteString(choice.Delta.Refusal)
+		reasoning.WriteString(choice.Delta.ReasoningContent)
+		for _, tc := range choice.Delta.ToolCalls {
+			for len(calls) <= tc.Index {
+				calls = append(calls, chatToolCall{Type: "function"})
+			}
+			call := &calls[tc.Index]
+			if tc.ID != "" {
+				call.ID = tc.ID
+			}
+			call.Function.Name += tc.Function.Name
+			call.Function.Arguments += tc.Function.Arguments
+		}
+		if choice.FinishReason != "" {
+			finish = choice.FinishReason
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read stream: %w", err)
+	}
+
+	reply := chatReply{Refusal: refusal.String(), ReasoningContent: reasoning.String(), ToolCalls: calls}
+	if content.Len() > 0 || refusal.Len() == 0 {
+		text := content.String()
+		reply.Content = &text
+	}
+	out.Choices = []chatChoice{{Message: reply, FinishReason: finish}}
+	return nil
+}
@@ -0,0 +1,292 @@
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Debugger steps through a trace in a terminal.
+type Debugger struct {
+	Trace *Trace
+	Model agent.ModelProvider // Live model for re-running turns; nil disables re-runs
+	In    io.Reader
+	Out   io.Writer
+
+	// Edit lets the user change a prompt before a re-run. When nil, the new
+	// prompt is read from In up to a line holding a single ".".
+	Edit func(prompt string) (string, error)
+
+	// MaxLines caps how many lines of each prompt and response are shown
+	// before "f" (default 20).
+	MaxLines int
+
+	in *bufio.Scanner
+}
+
+const debuggerHelp = `commands:
+  n, <enter>  next turn          p    previous turn
+  g <n>       go to turn n       f    full request (history, tools)
+  e           edit prompt and re-run the turn against the live model
+  r           re-run the turn unchanged
+  q           quit`
+
+// Run shows the first turn and processes commands until "q" or end of input.
+func (d *Debugger) Run(ctx context.Context) error {
+	if d.Trace == nil || len(d.Trace.Turns) == 0 {
+		return errors.New("replay: trace has no turns")
+	}
+	if d.MaxLines == 0 {
+		d.MaxLines = 20
+	}
+	d.in = bufio.NewScanner(d.In)
+	d.in.Buffer(make([]byte, 64*1024), 1<<20)
+
+	cur := 0
+	d.show(cur)
+	for {
+		fmt.Fprint(d.Out, "\n(replay) ")
+		if !d.in.Scan() {
+			return d.in.Err()
+		}
+		cmd, arg, _ := strings.Cut(strings.TrimSpace(d.in.Text()), " ")
+		switch cmd {
+		case "", "n":
+			if cur+1 >= len(d.Trace.Turns) {
+				fmt.Fprintln(d.Out, "at last turn")
+				continue
+			}
+			cur++
+			d.show(cur)
+		case "p":
+			if cur == 0 {
+				fmt.Fprintln(d.Out, "at first turn")
+				continue
+			}
+			cur--
+			d.show(cur)
+		case "g":
+			n, err := strconv.Atoi(strings.TrimSpace(arg))
+			if err != nil || n < 1 || n > len(d.Trace.Turns) {
+				fmt.Fprintf(d.Out, "turn must be 1-%d\n", len(d.Trace.Turns))
+				continue
+			}
+			cur = n - 1
+			d.show(cur)
+		case "f":
+			d.showRequest(cur)
+		case "e", "r":
+			if d.Model == nil {
+				fmt.Fprintln(d.Out, "no live model configured; re-runs are disabled")
+				continue
+			}
+			prompt := d.Trace.Turns[cur].Request.Prompt
+			if cmd == "e" {
+				edited, err := d.edit(prompt)
+				if err != nil {
+					fmt.Fprintf(d.Out, "edit failed: %v\n", err)
+					continue
+				}
+				prompt = edited
+			}
+			d.rerun(ctx, cur, prompt)
+		case "q":
+			return nil
+		default:
+			fmt.Fprintln(d.Out, debuggerHelp)
+		}
+	}
+}
+
+// show prints a turn: prompt, response, tool calls, and state changes.
+func (d *Debugger) show(i int) {
+	t := &d.Trace.Turns[i]
+	header := fmt.Sprintf("Turn %d/%d · %s", i+1, len(d.Trace.Turns), t.AgentPath)
+	if t.Response != nil && t.Response.Model != "" {
+		header += " · " + t.Response.Model
+	}
+	if t.Latency >= time.Millisecond {
+		header += " · " + t.Latency.Round(time.Millisecond).String()
+	} else {
+		header += " · " + t.Latency.String()
+	}
+	fmt.Fprintf(d.Out, "\n── %s ──\n", header)
+
+	fmt.Fprintln(d.Out, "Prompt:")
+	d.block(t.Request.Prompt, d.MaxLines)
+	if n := len(t.Request.History); n > 0 {
+		fmt.Fprintf(d.Out, "History: %d messages, %d tools (f to show)\n", n, len(t.Request.Tools))
+	}
+
+	if t.Error != "" {
+		fmt.Fprintf(d.Out, "Error: %s\n", t.Error)
+	}
+	if t.Response != nil {
+		if t.Response.Content != "" {
+			fmt.Fprintln(d.Out, "Response:")
+			d.block(t.Response.Content, d.MaxLines)
+		}
+		if t.Response.Routing != "" {
+			fmt.Fprintf(d.Out, "Routing: %s\n", t.Response.Routing)
+		}
+	}
+
+	calls := t.ToolCalls
+	if calls == nil && t.Response != nil {
+		calls = t.Response.ToolCalls
+	}
+	if len(calls) > 0 {
+		fmt.Fprintln(d.Out, "Tool calls:")
+		for _, tc := range calls {
+			line := fmt.Sprintf("  %s(%s)", tc.Name, compact(tc.Arguments, 120))
+			switch {
+			case tc.Error != "":
+				line += " ✗ " + tc.Error
+			case tc.Result != nil:
+				line += " → " + compact(tc.Result, 120)
+			}
+			fmt.Fprintln(d.Out, line)
+		}
+	}
+
+	if diff := d.stateDiff(i); len(diff) > 0 {
+		fmt.Fprintln(d.Out, "State:")
+		for _, line := range diff {
+			fmt.Fprintln(d.Out, "  "+line)
+		}
+	}
+}
+
+// showRequest prints the full history and tool definitions of a turn.
+func (d *Debugger) showRequest(i int) {
+	t := &d.Trace.Turns[i]
+	for j, m := range t.Request.History {
+		fmt.Fprintf(d.Out, "[%d] %s:\n", j+1, m.Role)
+		d.block(m.Content, 0)
+		for _, p := range m.Parts {
+			fmt.Fprintf(d.Out, "    [%s part]\n", p.Type)
+		}
+	}
+	fmt.Fprintln(d.Out, "Prompt:")
+	d.block(t.Request.Prompt, 0)
+	for _, spec := range t.Request.Tools {
+		fmt.Fprintf(d.Out, "Tool %s: %s\n  %s\n", spec.ToolName, spec.ToolDescription, compact(spec.ToolSchema, 0))
+	}
+	if t.Request.OutputSchema != nil {
+		fmt.Fprintf(d.Out, "Output schema: %s\n", compact(t.Request.OutputSchema, 0))
+	}
+}
+
+// stateDiff describes the state changes of turn i against the state
+// accumulated from all earlier turns.
+func (d *Debugger) stateDiff(i int) []string {
+	delta := d.Trace.Turns[i].StateDelta
+	if len(delta) == 0 {
+		return nil
+	}
+	before := map[string]interface{}{}
+	for _, t := range d.Trace.Turns[:i] {
+		for k, v := range t.StateDelta {
+			before[k] = v
+		}
+	}
+	keys := make([]string, 0, len(delta))
+	for k := range delta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, k := range keys {
+		old, existed := before[k]
+		switch {
+		case !existed:
+			lines = append(lines, fmt.Sprintf("+ %s = %s", k, compact(delta[k], 120)))
+		case !reflect.DeepEqual(old, delta[k]):
+			lines = append(lines, fmt.Sprintf("~ %s: %s → %s", k, compact(old, 60), compact(delta[k], 60)))
+		}
+	}
+	return lines
+}
+
+// rerun sends turn i's request with prompt to the live model and prints the
+// new response next to the recorded one. Tool calls in the new response are
+// shown but not executed.
+func (d *Debugger) rerun(ctx context.Context, i int, prompt string) {
+	t := &d.Trace.Turns[i]
+	fmt.Fprintln(d.Out, "re-running...")
+	resp, err := d.Model.Complete(ctx, t.CompletionRequest(prompt))
+	if err != nil {
+		fmt.Fprintf(d.Out, "re-run failed: %v\n", err)
+		return
+	}
+	if t.Response != nil {
+		fmt.Fprintln(d.Out, "Recorded response:")
+		d.block(t.Response.Content, d.MaxLines)
+	}
+	fmt.Fprintln(d.Out, "New response:")
+	d.block(resp.Content, d.MaxLines)
+	for _, tc := range resp.ToolCalls {
+		fmt.Fprintf(d.Out, "  %s(%s) (not executed)\n", tc.Name, compact(tc.Arguments, 120))
+	}
+	if resp.Usage != nil {
+		fmt.Fprintf(d.Out, "Usage: %d prompt + %d completion tokens\n", resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	}
+}
+
+func (d *Debugger) edit(prompt string) (string, error) {
+	if d.Edit != nil {
+		return d.Edit(prompt)
+	}
+	fmt.Fprintln(d.Out, "Enter the new prompt; end with a line containing only \".\":")
+	var lines []string
+	for d.in.Scan() {
+		if d.in.Text() == "." {
+			return strings.Join(lines, "\n"), nil
+		}
+		lines = append(lines, d.in.Text())
+	}
+	if err := d.in.Err(); err != nil {
+		return "", err
+	}
+	return "", io.ErrUnexpectedEOF
+}
+
+// block prints text indented, truncated to maxLines lines (0 = all).
+func (d *Debugger) block(text string, maxLines int) {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for j, line := range lines {
+		if maxLines > 0 && j == maxLines {
+			fmt.Fprintf(d.Out, "  ... %d more lines\n", len(lines)-maxLines)
+			return
+		}
+		fmt.Fprintln(d.Out, "  "+line)
+	}
+}
+
+// compact renders a value as single-line JSON, truncated to max runes
+// (0 = no limit).
+func compact(v interface{}, max int) string {
+	var s string
+	if str, ok := v.(string); ok {
+		s = strconv.Quote(str)
+	} else if data, err := json.Marshal(v); err == nil {
+		s = string(data)
+	} else {
+		s = fmt.Sprint(v)
+	}
+	if r := []rune(s); max > 0 && len(r) > max {
+		s = string(r[:max-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Recorder captures an execution as a Trace. Wrap the agent's model with
+// Model and set Handler as ExecutionConfig.OnEvent; both are safe for
+// concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	trace Trace
+}
+
+// NewRecorder creates a Recorder for the given task ID (may be empty).
+func NewRecorder(taskID string) *Recorder {
+	return &Recorder{trace: Trace{TaskID: taskID}}
+}
+
+// Model wraps a provider so every completion is recorded as a turn,
+// attributed to the calling agent's path.
+func (r *Recorder) Model(m agent.ModelProvider) agent.ModelProvider {
+	return &recordedModel{inner: m, rec: r}
+}
+
+// Handler returns an event handler that attaches each step's executed tool
+// calls and state changes to the turn that produced it.
+func (r *Recorder) Handler() agent.EventHandler {
+	return func(ev *agent.Event) {
+		if ev.Type != agent.EventStep {
+			return
+		}
+		path := ev.AgentPath
+		if path == "" {
+			path = ev.Author
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for i := len(r.trace.Turns) - 1; i >= 0; i-- {
+			t := &r.trace.Turns[i]
+			if t.AgentPath != path || t.stepped {
+				continue
+			}
+			t.stepped = true
+			t.ToolCalls = toolCalls(ev.ToolCalls)
+			if ev.Actions != nil && len(ev.Actions.StateDelta) > 0 {
+				t.StateDelta = make(map[string]interface{}, len(ev.Actions.StateDelta))
+				for k, v := range ev.Actions.StateDelta {
+					t.StateDelta[k] = v
+				}
+			}
+			return
+		}
+	}
+}
+
+// Trace returns a copy of the recorded trace.
+func (r *Recorder) Trace() *Trace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := Trace{TaskID: r.trace.TaskID, Turns: append([]Turn(nil), r.trace.Turns...)}
+	return &t
+}
+
+// WriteFile writes the trace as indented JSON.
+func (r *Recorder) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r.Trace(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+type recordedModel struct {
+	inner agent.ModelProvider
+	rec   *Recorder
+}
+
+func (m *recordedModel) Complete(ctx context.Context, req *agent.CompletionRequest) (*agent.ModelResponse, error) {
+	return m.record(ctx, req, func() (*agent.ModelResponse, error) {
+		return m.inner.Complete(ctx, req)
+	})
+}
+
+// CompleteStream streams through to the wrapped provider when it supports
+// streaming; the assembled response is recorded once it completes.
+func (m *recordedModel) CompleteStream(ctx context.Context, req *agent.CompletionRequest, onDelta func(string)) (*agent.ModelResponse, error) {
+	return m.record(ctx, req, func() (*agent.ModelResponse, error) {
+		if s, ok := m.inner.(agent.StreamingModelProvider); ok {
+			return s.CompleteStream(ctx, req, onDelta)
+		}
+		return m.inner.Complete(ctx, req)
+	})
+}
+
+func (m *recordedModel) record(ctx context.Context, req *agent.CompletionRequest, call func() (*agent.ModelResponse, error)) (*agent.ModelResponse, error) {
+	turn := Turn{
+		AgentPath: agent.AgentPathFromContext(ctx),
+		Time:      time.Now(),
+		Request: Request{
+			Prompt:       req.Prompt,
+			History:      messages(req.History),
+			OutputSchema: req.OutputSchema,
+			Temperature:  req.Temperature,
+			MaxTokens:    req.MaxTokens,
+		},
+	}
+	for _, t := range req.Tools {
+		turn.Request.Tools = append(turn.Request.Tools, ToolSpec{ToolName: t.Name(), ToolDescription: t.Description(), ToolSchema: t.Schema()})
+	}
+
+	resp, err := call()
+	turn.Latency = time.Since(turn.Time)
+	turn.Response = newResponse(resp)
+	if err != nil {
+		turn.Error = err.Error()
+	}
+
+	m.rec.mu.Lock()
+	turn.Index = len(m.rec.trace.Turns)
+	m.rec.trace.Turns = append(m.rec.trace.Turns, turn)
+	m.rec.mu.Unlock()
+	return resp, err
+}
@@ -0,0 +1,167 @@
+// Package replay records executions turn by turn and steps back through them
+// for debugging, optionally re-running a turn with an edited prompt against a
+// live model.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Trace is a recorded execution: every model call with its full request and
+// response, plus the tool results and state changes of the step it drove.
+type Trace struct {
+	TaskID string `json:"task_id,omitempty"`
+	Turns  []Turn `json:"turns"`
+}
+
+// Turn is one model call and the step that followed it.
+type Turn struct {
+	Index      int                    `json:"index"`
+	AgentPath  string                 `json:"agent_path"`
+	Time       time.Time              `json:"time"`
+	Latency    time.Duration          `json:"latency"`
+	Request    Request                `json:"request"`
+	Response   *Response              `json:"response,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	ToolCalls  []ToolCall             `json:"tool_calls,omitempty"`  // Executed calls with results, from the step
+	StateDelta map[string]interface{} `json:"state_delta,omitempty"` // State written by the step
+
+	stepped bool // Step event already attached
+}
+
+// Request is the recorded completion request.
+type Request struct {
+	Prompt       string                 `json:"prompt"`
+	History      []agent.Message        `json:"history,omitempty"`
+	Tools        []ToolSpec             `json:"tools,omitempty"`
+	OutputSchema map[string]interface{} `json:"output_schema,omitempty"`
+	Temperature  *float32               `json:"temperature,omitempty"`
+	MaxTokens    *int                   `json:"max_tokens,omitempty"`
+}
+
+// Response is the recorded model response.
+type Response struct {
+	Content   string            `json:"content"`
+	Reasoning string            `json:"reasoning,omitempty"`
+	ToolCalls []ToolCall        `json:"tool_calls,omitempty"`
+	Usage     *agent.TokenUsage `json:"usage,omitempty"`
+	Model     string            `json:"model,omitempty"`
+	Routing   string            `json:"routing,omitempty"`
+}
+
+// ToolCall is a JSON-friendly agent.ToolCall.
+type ToolCall struct {
+	ID        string                 `json:"id,omitempty"`
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Result    interface{}            `json:"result,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Duration  time.Duration          `json:"duration,omitempty"`
+}
+
+// ToolSpec is a recorded tool definition. It implements agent.Tool so a turn
+// can be re-sent with the same tools, but it cannot be executed.
+type ToolSpec struct {
+	ToolName        string      `json:"name"`
+	ToolDescription string      `json:"description"`
+	ToolSchema      interface{} `json:"schema,omitempty"`
+}
+
+func (t ToolSpec) Name() string        { return t.ToolName }
+func (t ToolSpec) Description() string { return t.ToolDescription }
+func (t ToolSpec) Schema() interface{} { return t.ToolSchema }
+
+// ErrNotExecutable is returned by ToolSpec.Execute.
+var ErrNotExecutable = errors.New("replay: recorded tools cannot be executed")
+
+func (t ToolSpec) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	return nil, ErrNotExecutable
+}
+
+// CompletionRequest rebuilds the request sent on this turn, with prompt in
+// place of the recorded one when non-empty.
+func (t *Turn) CompletionRequest(prompt string) *agent.CompletionRequest {
+	if prompt == "" {
+		prompt = t.Request.Prompt
+	}
+	req := &agent.CompletionRequest{
+		Prompt:       prompt,
+		History:      t.Request.History,
+		OutputSchema: t.Request.OutputSchema,
+		Temperature:  t.Request.Temperature,
+		MaxTokens:    t.Request.MaxTokens,
+	}
+	for _, spec := range t.Request.Tools {
+		req.Tools = append(req.Tools, spec)
+	}
+	return req
+}
+
+// Load reads a trace written by Recorder.WriteFile.
+func Load(path string) (*Trace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t Trace
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func toolCalls(calls []agent.ToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]ToolCall, len(calls))
+	for i, tc := range calls {
+		out[i] = ToolCall{ID: tc.ID, Name: tc.Name, Arguments: tc.Arguments, Result: tc.Result, Duration: tc.Duration}
+		if tc.Error != nil {
+			out[i].Error = tc.Error.Error()
+		}
+	}
+	return out
+}
+
+func newResponse(resp *agent.ModelResponse) *Response {
+	if resp == nil {
+		return nil
+	}
+	return &Response{
+		Content:   resp.Content,
+		Reasoning: resp.Reasoning,
+		ToolCalls: toolCalls(resp.ToolCalls),
+		Usage:     resp.Usage,
+		Model:     resp.Model,
+		Routing:   resp.Routing,
+	}
+}
+
+// messages copies history for JSON, keeping only inline byte and URI part
+// data; streamed readers are replaced by a placeholder.
+func messages(history []agent.Message) []agent.Message {
+	out := make([]agent.Message, len(history))
+	for i, m := range history {
+		out[i] = m
+		if len(m.Parts) == 0 {
+			continue
+		}
+		out[i].Parts = make([]agent.Part, len(m.Parts))
+		for j, p := range m.Parts {
+			switch p.Data.(type) {
+			case nil, []byte, string:
+			default:
+				p.Data = "[stream]"
+			}
+			out[i].Parts[j] = p
+		}
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+// Package schema builds JSON schemas for tool arguments and structured
+// output, either fluently or from Go struct types.
+//
+//	s := schema.Object().
+//		Prop("query", schema.String().Describe("Search terms").Required()).
+//		Prop("limit", schema.Integer().Min(1).Max(50))
+//
+// Build returns the map[string]interface{} form expected by Tool.Schema and
+// agent output schemas.
+package schema
+
+import (
+	"encoding/json"
+)
+
+// Schema is a JSON schema under construction. Methods modify the schema in
+// place and return it for chaining.
+type Schema struct {
+	fields   map[string]interface{}
+	props    map[string]*Schema
+	order    []string
+	required bool
+}
+
+func newSchema(typ string) *Schema {
+	return &Schema{fields: map[string]interface{}{"type": typ}}
+}
+
+// Object starts an object schema. Add members with Prop.
+func Object() *Schema { return newSchema("object") }
+
+// String starts a string schema.
+func String() *Schema { return newSchema("string") }
+
+// Integer starts an integer schema.
+func Integer() *Schema { return newSchema("integer") }
+
+// Number starts a number schema.
+func Number() *Schema { return newSchema("number") }
+
+// Boolean starts a boolean schema.
+func Boolean() *Schema { return newSchema("boolean") }
+
+// Array starts an array schema whose elements match items.
+func Array(items *Schema) *Schema {
+	s := newSchema("array")
+	s.fields["items"] = items
+	return s
+}
+
+// Prop adds a property to an object schema. Mark the property schema with
+// Required to list it in the object's required keywords.
+func (s *Schema) Prop(name string, prop *Schema) *Schema {
+	if s.props == nil {
+		s.props = map[string]*Schema{}
+	}
+	if _, exists := s.props[name]; !exists {
+		s.order = append(s.order, name)
+	}
+	s.props[name] = prop
+	return s
+}
+
+// Required marks the schema as a required property of its parent object.
+func (s *Schema) Required() *Schema {
+	s.required = true
+	return s
+}
+
+// Describe sets the description shown to the model.
+func (s *Schema) Describe(description string) *Schema {
+	return s.set("description", description)
+}
+
+// Enum restricts the value to one of values.
+func (s *Schema) Enum(values ...interface{}) *Schema {
+	return s.set("enum", values)
+}
+
+// Default records the value used when the property is omitted.
+func (s *Schema) Default(value interface{}) *Schema {
+	return s.set("default", value)
+}
+
+// Min sets the inclusive minimum of a number or integer.
+func (s *Schema) Min(v float64) *Schema { return s.set("minimum", v) }
+
+// Max sets the inclusive maximum of a number or integer.
+func (s *Schema) Max(v float64) *Schema { return s.set("maximum", v) }
+
+// MinLength sets the minimum length of a string.
+func (s *Schema) MinLength(n int) *Schema { return s.set("minLength", n) }
+
+// MaxLength sets the maximum length of a string.
+func (s *Schema) MaxLength(n int) *Schema { return s.set("maxLength", n) }
+
+// Pattern sets a regular expression a string must match.
+func (s *Schema) Pattern(re string) *Schema { return s.set("pattern", re) }
+
+// Format sets a string format such as "date-time" or "uri".
+func (s *Schema) Format(format string) *Schema { return s.set("format", format) }
+
+// AdditionalProperties allows or forbids object keys not declared with Prop.
+func (s *Schema) AdditionalProperties(allowed bool) *Schema {
+	return s.set("additionalProperties", allowed)
+}
+
+func (s *Schema) set(key string, value interface{}) *Schema {
+	s.fields[key] = value
+	return s
+}
+
+// Build returns the schema as a fresh map. Later changes to s do not affect
+// maps already built.
+func (s *Schema) Build() map[string]interface{} {
+	out := make(map[string]interface{}, len(s.fields)+2)
+	for k, v := range s.fields {
+		if items, ok := v.(*Schema); ok {
+			v = items.Build()
+		}
+		out[k] = v
+	}
+	if len(s.order) > 0 {
+		props := make(map[string]interface{}, len(s.order))
+		var required []string
+		for _, name := range s.order {
+			prop := s.props[name]
+			props[name] = prop.Build()
+			if prop.required {
+				required = append(required, name)
+			}
+		}
+		out["properties"] = props
+		if len(required) > 0 {
+			out["required"] = required
+		}
+	}
+	return out
+}
+
+// MarshalJSON encodes the built schema.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Build())
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// ArtifactConfig serves the artifacts generated in sessions, for a chat UI's
+// "files in this conversation" panel.
+type ArtifactConfig struct {
+	Service agent.ArtifactService
+
+	// SigningKey signs download URLs, so they can be opened by a browser
+	// without credentials until they expire. Required; New rejects an empty
+	// key, which would let anyone forge URLs.
+	SigningKey []byte
+	URLTTL     time.Duration // Lifetime of download URLs (default 15m)
+
+	// BaseURL is prepended to download paths, e.g. "https://api.example.com"
+	// (default: relative URLs).
+	BaseURL string
+}
+
+// ErrInvalidSignature is returned for download URLs with a missing, wrong,
+// or expired signature.
+var ErrInvalidSignature = errors.New("invalid or expired artifact URL")
+
+// SignURL returns the download URL of an artifact, valid until expires.
+func (c *ArtifactConfig) SignURL(id string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	q := url.Values{"expires": {exp}, "sig": {c.signature(id, exp)}}
+	return c.BaseURL + "/artifacts/" + url.PathEscape(id) + "?" + q.Encode()
+}
+
+// verify checks the signature of a download request for id.
+func (c *ArtifactConfig) verify(id string, q url.Values, now time.Time) error {
+	exp := q.Get("expires")
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(q.Get("sig")), []byte(c.signature(id, exp))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (c *ArtifactConfig) signature(id, expires string) string {
+	mac := hmac.New(sha256.New, c.SigningKey)
+	mac.Write([]byte(id + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (c *ArtifactConfig) ttl() time.Duration {
+	if c.URLTTL > 0 {
+		return c.URLTTL
+	}
+	return 15 * time.Minute
+}
+
+type artifactListItem struct {
+	agent.StoredArtifact
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"url_expires_at"`
+}
+
+// handleListArtifacts lists a session's artifacts, oldest first, filtered by
+// the query parameters type and mime (exact, or a "image/*" wildcard). Each
+// carries a signed download URL. With Config.Identify set, callers only see
+// the artifacts of their own tasks.
+func (s *Server) handleListArtifacts(w http.ResponseWriter, r *http.Request) {
+	cfg := s.cfg.Artifacts
+	q := r.URL.Query()
+	filter := agent.ArtifactFilter{Type: q.Get("type"), MimeType: q.Get("mime")}
+	list, err := cfg.Service.ListBySession(r.Context(), r.PathValue("id"), filter)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	var userID string
+	if s.cfg.Identify != nil {
+		userID = s.cfg.Identify(r)
+	}
+	expires := time.Now().Add(cfg.ttl()).Truncate(time.Second)
+	items := []artifactListItem{}
+	for _, a := range list {
+		if s.cfg.Identify != nil && a.UserID != userID {
+			continue
+		}
+		items = append(items, artifactListItem{StoredArtifact: a, URL: cfg.SignURL(a.ID, expires), ExpiresAt: expires})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"artifacts": items})
+}
+
+// inlineTypes are the generated content types safe to display on the API
+// origin; anything else, e.g. HTML or SVG a tool wrote, could run script
+// there and is downloaded instead.
+var inlineTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// handleGetArtifact serves an artifact's content to a request carrying a
+// valid signature from SignURL. Content is sandboxed and, unless it is a
+// raster image or PDF, sent as an attachment.
+func (s *Server) handleGetArtifact(w http.ResponseWriter, r *http.Request) {
+	cfg := s.cfg.Artifacts
+	id := r.PathValue("id")
+	if err := cfg.verify(id, r.URL.Query(), time.Now()); err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+	meta, data, err := cfg.Service.Open(r.Context(), id)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	contentType := meta.MimeType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	disposition := "attachment"
+	if base, _, err := mime.ParseMediaType(contentType); err == nil && inlineTypes[base] {
+		disposition = "inline"
+	}
+	params := map[string]string{}
+	if meta.Name != "" {
+		params["filename"] = meta.Name
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, params))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// RateLimit is a token bucket: RequestsPerMinute sustained, up to Burst at
+// once. A zero RequestsPerMinute disables the limit.
+type RateLimit struct {
+	RequestsPerMinute float64
+	Burst             int           // Bucket size (default: one minute of requests, at least 1)
+	Cooldown          time.Duration // Once exceeded, reject all requests for this long (default: until the next token)
+}
+
+// RateLimitConfig limits task submissions per user and per session, protecting
+// model quota from a single client.
+type RateLimitConfig struct {
+	PerUser    RateLimit
+	PerSession RateLimit
+
+	// UserKey identifies the caller (default: Config.Identify if set, else
+	// the remote IP). Client-supplied headers should not be trusted here, as
+	// a caller choosing its own key gets a fresh bucket with every value.
+	UserKey func(*http.Request) string
+
+	// SessionKey identifies the conversation from the request and the
+	// submitted task params (default: params.session_id, scoped to the user
+	// key). Requests without a session key skip the per-session limit.
+	SessionKey func(r *http.Request, params map[string]interface{}) string
+}
+
+// RateLimitError reports a rejected request.
+type RateLimitError struct {
+	Scope      string // "user" or "session"
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s %s; retry after %s", e.Scope, e.Key, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RateLimitError) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimiter applies per-user and per-session buckets to requests.
+type rateLimiter struct {
+	cfg      RateLimitConfig
+	users    *buckets
+	sessions *buckets
+}
+
+// newRateLimiter creates a limiter, keying users by identify (if not nil)
+// unless cfg sets UserKey.
+func newRateLimiter(cfg RateLimitConfig, identify func(*http.Request) string) *rateLimiter {
+	if cfg.UserKey == nil {
+		cfg.UserKey = remoteIP
+		if identify != nil {
+			cfg.UserKey = func(r *http.Request) string {
+				if id := identify(r); id != "" {
+					return id
+				}
+				return remoteIP(r)
+			}
+		}
+	}
+	if cfg.SessionKey == nil {
+		userKey := cfg.UserKey
+		cfg.SessionKey = func(r *http.Request, params map[string]interface{}) string {
+			id, _ := params[agent.ParamSessionID].(string)
+			if id == "" {
+				return ""
+			}
+			return userKey(r) + "/" + id
+		}
+	}
+	return &rateLimiter{cfg: cfg, users: newBuckets(cfg.PerUser), sessions: newBuckets(cfg.PerSession)}
+}
+
+// check returns a *RateLimitError if the request, submitting a task with
+// params, exceeds either limit.
+func (l *rateLimiter) check(r *http.Request, params map[string]interface{}) error {
+	now := time.Now()
+	if key := l.cfg.UserKey(r); key != "" {
+		if wait, ok := l.users.take(key, now); !ok {
+			return &RateLimitError{Scope: "user", Key: key, RetryAfter: wait}
+		}
+	}
+	if key := l.cfg.SessionKey(r, params); key != "" {
+		if wait, ok := l.sessions.take(key, now); !ok {
+			return &RateLimitError{Scope: "session", Key: key, RetryAfter: wait}
+		}
+	}
+	return nil
+}
+
+// limit applies the rate limiter to a submission, answering 429 with a
+// Retry-After header and returning false when the caller is over its limit.
+func (s *Server) limit(w http.ResponseWriter, r *http.Request, params map[string]interface{}) bool {
+	if s.limiter == nil {
+		return true
+	}
+	if err := s.limiter.check(r, params); err != nil {
+		if rle, ok := err.(*RateLimitError); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rle.RetryAfter.Seconds()))))
+		}
+		writeError(w, statusFor(err), err)
+		return false
+	}
+	return true
+}
+
+type bucket struct {
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+// buckets is a set of token buckets sharing one limit.
+type buckets struct {
+	limit RateLimit
+	rate  float64 // tokens per second
+	burst float64
+
+	mu      sync.Mutex
+	entries map[string]*bucket
+	sweepAt time.Time
+}
+
+func newBuckets(limit RateLimit) *buckets {
+	b := &buckets{limit: limit, entries: map[string]*bucket{}}
+	if limit.RequestsPerMinute > 0 {
+		b.rate = limit.RequestsPerMinute / 60
+		b.burst = float64(limit.Burst)
+		if b.burst <= 0 {
+			b.burst = math.Max(1, math.Floor(limit.RequestsPerMinute))
+		}
+	}
+	return b
+}
+
+// take consumes a token for key, reporting how long to wait if none is left.
+func (b *buckets) take(key string, now time.Time) (time.Duration, bool) {
+	if b.rate == 0 {
+		return 0, true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweep(now)
+	e, ok := b.entries[key]
+	if !ok {
+		e = &bucket{tokens: b.burst, last: now}
+		b.entries[key] = e
+	}
+	if now.Before(e.blockedUntil) {
+		return e.blockedUntil.Sub(now), false
+	}
+
+	e.tokens = math.Min(b.burst, e.tokens+now.Sub(e.last).Seconds()*b.rate)
+	e.last = now
+	if e.tokens >= 1 {
+		e.tokens--
+		return 0, true
+	}
+
+	wait := time.Duration((1 - e.tokens) / b.rate * float64(time.Second))
+	if b.limit.Cooldown > wait {
+		wait = b.limit.Cooldown
+		e.blockedUntil = now.Add(wait)
+	}
+	return wait, false
+}
+
+// sweep drops buckets that have refilled completely, at most once a minute.
+func (b *buckets) sweep(now time.Time) {
+	if now.Before(b.sweepAt) {
+		return
+	}
+	b.sweepAt = now.Add(time.Minute)
+	for key, e := range b.entries {
+		full := e.tokens+now.Sub(e.last).Seconds()*b.rate >= b.burst
+		if full && !now.Before(e.blockedUntil) {
+			delete(b.entries, key)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+// Package server exposes an agent Executor over HTTP, along with liveness and
+// readiness probes for load balancers.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+	"github.com/sultanfariz/gonostic/pkg/providers"
+)
+
+// Config holds configuration for creating a Server.
+type Config struct {
+	Executor      *agent.Executor
+	Providers     map[string]agent.ModelProvider // Checked by /readyz
+	HealthTimeout time.Duration                  // Per-provider ping timeout (default 5s)
+	RateLimit     *RateLimitConfig               // Per-user and per-session limits on task submission (optional)
+
+	// Identify returns the authenticated caller of a request, passed to the
+	// task as ExecutionConfig.UserID for tenant resolution (optional).
+	Identify func(*http.Request) string
+
+	// Webhooks receives calls of generated webhook URLs, served at
+	// POST /webhooks/{id}; its BaseURL should point there (optional).
+	Webhooks *agent.Webhooks
+
+	// AuthorizeAdmin guards the /admin endpoints; they are only served when
+	// it is set, and requests it rejects get 403.
+	AuthorizeAdmin func(*http.Request) bool
+
+	// Artifacts serves session artifact listings and signed downloads
+	// (optional).
+	Artifacts *ArtifactConfig
+}
+
+// Server is an http.Handler serving task submission and health endpoints:
+//
+//	GET  /healthz     liveness; always 200 while the process is up
+//	GET  /readyz      readiness; 503 while the Executor warms up or if any
+//	                  configured provider fails Ping
+//	POST /tasks       submit a task; 400 on invalid params, 429 when rate limited
+//	GET  /tasks       list jobs, filtered by label=key:value, status, since,
+//	                  and limit; only the caller's with Config.Identify set
+//	GET  /tasks/{id}  job status, latest progress and usage, and result
+//	POST /tasks/{id}/cancel  cancel a job; 409 if it already finished
+//	POST /tasks/{id}/input   answer a job's pending question
+//
+// With Config.Identify set, the /tasks/{id} endpoints answer 404 for jobs
+// another caller submitted.
+//
+//	POST /webhooks/{id}      deliver a call to a webhook, with Config.Webhooks set
+//
+// With Config.Artifacts set, UIs can browse the files of a conversation:
+//
+//	GET /sessions/{id}/artifacts  a session's artifacts, filtered by type and
+//	                              mime, each with a signed download URL
+//	GET /artifacts/{id}           download an artifact; 403 without a valid
+//	                              signature
+//
+// With Config.AuthorizeAdmin set, operators can pause task intake:
+//
+//	GET    /admin/maintenance  current maintenance status
+//	PUT    /admin/maintenance  pause intake: {"reason": ..., "allow": [agent names]};
+//	                           submissions then get 503
+//	DELETE /admin/maintenance  resume intake
+type Server struct {
+	cfg     Config
+	mux     *http.ServeMux
+	limiter *rateLimiter
+}
+
+// New creates a new Server from the given configuration.
+func New(cfg Config) (*Server, error) {
+	if cfg.Artifacts != nil && len(cfg.Artifacts.SigningKey) == 0 {
+		return nil, errors.New("server: Artifacts.SigningKey is required")
+	}
+	s := &Server{cfg: cfg, mux: http.NewServeMux()}
+	if cfg.RateLimit != nil {
+		s.limiter = newRateLimiter(*cfg.RateLimit, cfg.Identify)
+	}
+	s.mux.HandleFunc("GET /healthz", s.handleHealthz)
+	s.mux.HandleFunc("GET /readyz", s.handleReadyz)
+	if cfg.Webhooks != nil {
+		s.mux.Handle("POST /webhooks/{id}", cfg.Webhooks)
+	}
+	if cfg.Artifacts != nil {
+		s.mux.HandleFunc("GET /sessions/{id}/artifacts", s.handleListArtifacts)
+		s.mux.HandleFunc("GET /artifacts/{id}", s.handleGetArtifact)
+	}
+	if cfg.Executor != nil {
+		s.mux.HandleFunc("POST /tasks", s.handleSubmit)
+		s.mux.HandleFunc("GET /tasks", s.handleListTasks)
+		s.mux.HandleFunc("GET /tasks/{id}", s.handleGetTask)
+		s.mux.HandleFunc("POST /tasks/{id}/cancel", s.handleCancelTask)
+		s.mux.HandleFunc("POST /tasks/{id}/input", s.handleAnswerTask)
+		if cfg.AuthorizeAdmin != nil {
+			s.mux.HandleFunc("GET /admin/maintenance", s.admin(s.handleGetMaintenance))
+			s.mux.HandleFunc("PUT /admin/maintenance", s.admin(s.handlePutMaintenance))
+			s.mux.HandleFunc("DELETE /admin/maintenance", s.admin(s.handleDeleteMaintenance))
+		}
+	}
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	report := providers.CheckHealth(r.Context(), s.cfg.Providers, s.cfg.HealthTimeout)
+	if s.cfg.Executor != nil {
+		check := providers.CheckResult{Healthy: true}
+		if err := s.cfg.Executor.WarmupErr(); err != nil {
+			check = providers.CheckResult{Error: err.Error()}
+			report.Healthy = false
+		}
+		report.Checks["warmup"] = check
+	}
+	status := http.StatusOK
+	if !report.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+type submitRequest struct {
+	Input  string                 `json:"input"`
+	Params map[string]interface{} `json:"params"`
+	Config *agent.ExecutionConfig `json:"config"`
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var req submitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !s.limit(w, r, req.Params) {
+		return
+	}
+
+	var opts []agent.CallOption
+	if s.cfg.Identify != nil {
+		opts = append(opts, agent.WithUserID(s.cfg.Identify(r)))
+	}
+	if tp := r.Header.Get(agent.TraceParentHeader); tp != "" {
+		opts = append(opts, agent.WithTraceParent(tp, r.Header.Get(agent.TraceStateHeader)))
+	}
+	taskID, err := s.cfg.Executor.Submit(req.Input, req.Params, req.Config, opts...)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"task_id": taskID})
+}
+
+type taskResponse struct {
+	TaskID   string              `json:"task_id"`
+	Status   agent.JobStatus     `json:"status"`
+	Progress *agent.Progress     `json:"progress,omitempty"`
+	Input    *agent.InputRequest `json:"input,omitempty"` // Pending question while status is needs_input
+	Usage    *agent.UsageReport  `json:"usage,omitempty"` // Running token usage and cost
+	Result   *agent.Result       `json:"result,omitempty"`
+}
+
+// ownJob answers 404 and returns false unless the job exists and, with
+// Config.Identify set, was submitted by the identified caller.
+func (s *Server) ownJob(w http.ResponseWriter, r *http.Request, taskID string) bool {
+	job, err := s.cfg.Executor.Job(taskID)
+	if err == nil && s.cfg.Identify != nil {
+		var owner string
+		if job.Task.Config != nil {
+			owner = job.Task.Config.UserID
+		}
+		if owner != s.cfg.Identify(r) {
+			err = fmt.Errorf("%w: %s", agent.ErrJobNotFound, taskID)
+		}
+	}
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	taskID := r.PathValue("id")
+	if !s.ownJob(w, r, taskID) {
+		return
+	}
+	status, err := s.cfg.Executor.GetStatus(taskID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	resp := taskResponse{TaskID: taskID, Status: status}
+	resp.Progress, _ = s.cfg.Executor.Progress(taskID)
+	resp.Input, _ = s.cfg.Executor.PendingInput(taskID)
+	resp.Usage, _ = s.cfg.Executor.Usage(taskID)
+	if status == agent.JobCompleted || status == agent.JobFailed {
+		resp.Result, _ = s.cfg.Executor.GetResult(taskID)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type taskListItem struct {
+	TaskID      string            `json:"task_id"`
+	Status      agent.JobStatus   `json:"status"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	StartedAt   time.Time         `json:"started_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+}
+
+// handleListTasks lists jobs, most recent first, filtered by the query
+// parameters label=key:value (repeatable), status (repeatable), since
+// (RFC 3339), and limit (default 100).
+func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := agent.JobFilter{Limit: 100}
+	for _, l := range q["label"] {
+		k, v, ok := strings.Cut(l, ":")
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("label %q is not key:value", l))
+			return
+		}
+		if filter.Labels == nil {
+			filter.Labels = make(map[string]string)
+		}
+		filter.Labels[k] = v
+	}
+	for _, st := range q["status"] {
+		filter.Status = append(filter.Status, agent.JobStatus(st))
+	}
+	if since := q.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid since: %w", err))
+			return
+		}
+		filter.Since = t
+	}
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", limit))
+			return
+		}
+		filter.Limit = n
+	}
+
+	if s.cfg.Identify != nil {
+		filter.UserID = s.cfg.Identify(r)
+	}
+
+	items := []taskListItem{}
+	for _, job := range s.cfg.Executor.ListJobs(filter) {
+		item := taskListItem{
+			TaskID:    job.Task.ID,
+			Status:    job.Status(),
+			Labels:    job.Labels(),
+			StartedAt: job.Task.StartedAt,
+		}
+		if done, ok := job.CompletedAt(); ok {
+			item.CompletedAt = &done
+		}
+		items = append(items, item)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tasks": items})
+}
+
+func (s *Server) handleCancelTask(w http.ResponseWriter, r *http.Request) {
+	taskID := r.PathValue("id")
+	if !s.ownJob(w, r, taskID) {
+		return
+	}
+	if err := s.cfg.Executor.Cancel(taskID); err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"task_id": taskID, "status": "cancelling"})
+}
+
+type answerRequest struct {
+	ID     string `json:"id"` // InputRequest ID; optional
+	Answer string `json:"answer"`
+}
+
+func (s *Server) handleAnswerTask(w http.ResponseWriter, r *http.Request) {
+	taskID := r.PathValue("id")
+	if !s.ownJob(w, r, taskID) {
+		return
+	}
+	var req answerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.cfg.Executor.Answer(taskID, req.ID, req.Answer); err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"task_id": taskID, "status": string(agent.JobRunning)})
+}
+
+// admin rejects requests Config.AuthorizeAdmin does not accept.
+func (s *Server) admin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.cfg.AuthorizeAdmin(r) {
+			writeError(w, http.StatusForbidden, errors.New("forbidden"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleGetMaintenance(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.cfg.Executor.Maintenance().Status())
+}
+
+type maintenanceRequest struct {
+	Reason string   `json:"reason"`
+	Allow  []string `json:"allow"`
+}
+
+func (s *Server) handlePutMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req maintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.cfg.Executor.Pause(req.Reason, req.Allow...)
+	writeJSON(w, http.StatusOK, s.cfg.Executor.Maintenance().Status())
+}
+
+func (s *Server) handleDeleteMaintenance(w http.ResponseWriter, r *http.Request) {
+	s.cfg.Executor.Resume()
+	writeJSON(w, http.StatusOK, s.cfg.Executor.Maintenance().Status())
+}
+
+// statusFor maps typed agent errors to HTTP status codes.
+func statusFor(err error) int {
+	var sc interface{ StatusCode() int }
+	if errors.As(err, &sc) {
+		return sc.StatusCode()
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,20 @@
+package sink
+
+import (
+	"context"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Channel sends each finished job on a Go channel, blocking until it is
+// received or the delivery context expires.
+type Channel chan<- *agent.Job
+
+func (c Channel) Deliver(ctx context.Context, job *agent.Job) error {
+	select {
+	case c <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
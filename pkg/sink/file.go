@@ -0,0 +1,75 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// File appends each finished job's Record to Path as a JSON line.
+type File struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (s *File) Deliver(ctx context.Context, job *agent.Job) error {
+	data, err := json.Marshal(NewRecord(job))
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Artifacts writes each job's artifacts to Dir/<task-id>/. String and byte
+// content is written as-is; anything else is JSON-encoded.
+type Artifacts struct {
+	Dir string
+}
+
+func (s *Artifacts) Deliver(ctx context.Context, job *agent.Job) error {
+	if job.Result == nil || len(job.Result.Artifacts) == 0 {
+		return nil
+	}
+	dir := filepath.Join(s.Dir, job.Task.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for i, a := range job.Result.Artifacts {
+		data, err := artifactBytes(a)
+		if err != nil {
+			return fmt.Errorf("artifact %d: %w", i, err)
+		}
+		name := fmt.Sprintf("%03d-%s", i, a.Type)
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func artifactBytes(a agent.Artifact) ([]byte, error) {
+	switch c := a.Content.(type) {
+	case string:
+		return []byte(c), nil
+	case []byte:
+		return c, nil
+	default:
+		return json.MarshalIndent(c, "", "  ")
+	}
+}
@@ -0,0 +1,52 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// ObjectStore is the subset of an object storage client (S3, GCS, MinIO)
+// the Object sink needs. Adapt an SDK client to it in a few lines.
+type ObjectStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+}
+
+// Object uploads each job's Record to <Prefix>/<task-id>/result.json and its
+// artifacts alongside it.
+type Object struct {
+	Store  ObjectStore
+	Prefix string
+}
+
+func (s *Object) Deliver(ctx context.Context, job *agent.Job) error {
+	base := path.Join(s.Prefix, job.Task.ID)
+	data, err := json.Marshal(NewRecord(job))
+	if err != nil {
+		return err
+	}
+	if err := s.Store.Put(ctx, path.Join(base, "result.json"), data, "application/json"); err != nil {
+		return err
+	}
+	if job.Result == nil {
+		return nil
+	}
+	for i, a := range job.Result.Artifacts {
+		data, err := artifactBytes(a)
+		if err != nil {
+			return fmt.Errorf("artifact %d: %w", i, err)
+		}
+		contentType := a.MimeType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		key := path.Join(base, fmt.Sprintf("artifacts/%03d-%s", i, a.Type))
+		if err := s.Store.Put(ctx, key, data, contentType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Package sink provides agent.ResultSink implementations that push finished
+// jobs to webhooks, files, object stores, channels, and SQL databases.
+package sink
+
+import (
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Record is the serialized form of a finished job written by the sinks.
+type Record struct {
+	TaskID      string                 `json:"task_id"`
+	Status      agent.JobStatus        `json:"status"`
+	Success     bool                   `json:"success"`
+	Output      interface{}            `json:"output,omitempty"`
+	Outputs     map[string]interface{} `json:"outputs,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	Params      map[string]interface{} `json:"params,omitempty"`
+	Labels      map[string]string      `json:"labels,omitempty"`
+	Artifacts   int                    `json:"artifacts"`
+	Usage       agent.TokenUsage       `json:"usage"`
+	StartedAt   time.Time              `json:"started_at"`
+	CompletedAt time.Time              `json:"completed_at"`
+}
+
+// NewRecord summarizes a finished job.
+func NewRecord(job *agent.Job) Record {
+	r := Record{
+		TaskID:      job.Task.ID,
+		Status:      job.Status(),
+		Params:      job.Task.Params,
+		Labels:      job.Labels(),
+		StartedAt:   job.Task.StartedAt,
+		CompletedAt: job.Task.CompletedAt,
+	}
+	if job.Result != nil {
+		r.Success = job.Result.Success
+		r.Output = job.Result.Output
+		r.Outputs = job.Result.Outputs
+		r.Error = job.Result.Error
+		r.Artifacts = len(job.Result.Artifacts)
+		r.Usage = job.Result.TotalTokenUsage
+	}
+	if job.Error != nil && r.Error == "" {
+		r.Error = job.Error.Error()
+	}
+	return r
+}
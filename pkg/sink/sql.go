@@ -0,0 +1,55 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// SQL inserts each finished job into Table with the columns task_id,
+// status, success, output, error, and completed_at. Output is stored as JSON
+// text. Works with any database/sql driver.
+type SQL struct {
+	DB    *sql.DB
+	Table string
+
+	// Placeholder returns the bind parameter for 1-based position n
+	// (default "?"; use DollarPlaceholder for PostgreSQL).
+	Placeholder func(n int) string
+}
+
+// DollarPlaceholder renders PostgreSQL-style bind parameters ($1, $2, ...).
+func DollarPlaceholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (s *SQL) Deliver(ctx context.Context, job *agent.Job) error {
+	if !identifier.MatchString(s.Table) {
+		return fmt.Errorf("sink: invalid table name %q", s.Table)
+	}
+	rec := NewRecord(job)
+	output, err := json.Marshal(rec.Output)
+	if err != nil {
+		return err
+	}
+
+	placeholder := s.Placeholder
+	if placeholder == nil {
+		placeholder = func(int) string { return "?" }
+	}
+	binds := make([]string, 6)
+	for i := range binds {
+		binds[i] = placeholder(i + 1)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (task_id, status, success, output, error, completed_at) VALUES (%s)",
+		s.Table, strings.Join(binds, ", "))
+	_, err = s.DB.ExecContext(ctx, query, rec.TaskID, string(rec.Status), rec.Success, string(output), rec.Error, rec.CompletedAt)
+	return err
+}
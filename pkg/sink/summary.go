@@ -0,0 +1,176 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Summary is a compact view of a finished job for callback payloads: the
+// outcome and key metrics, with links to the full result instead of its
+// steps and artifact content.
+type Summary struct {
+	TaskID          string            `json:"task_id"`
+	Status          agent.JobStatus   `json:"status"`
+	Success         bool              `json:"success"`
+	Output          interface{}       `json:"output,omitempty"`
+	OutputTruncated bool              `json:"output_truncated,omitempty"`
+	Error           string            `json:"error,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Metrics         SummaryMetrics    `json:"metrics"`
+	Artifacts       []ArtifactRef     `json:"artifacts,omitempty"`
+	Links           map[string]string `json:"links,omitempty"`
+}
+
+// SummaryMetrics are the headline numbers of a job.
+type SummaryMetrics struct {
+	Steps      int              `json:"steps"`
+	ToolCalls  int              `json:"tool_calls"`
+	Artifacts  int              `json:"artifacts"`
+	Usage      agent.TokenUsage `json:"usage"`
+	CostUSD    float64          `json:"cost_usd,omitempty"`
+	DurationMS int64            `json:"duration_ms"`
+}
+
+// ArtifactRef describes an artifact without its content.
+type ArtifactRef struct {
+	Index    int    `json:"index"`
+	Type     string `json:"type"`
+	MimeType string `json:"mime_type,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// Summarizer builds Summaries of at most MaxBytes of JSON, shrinking the
+// output and artifact list to fit. The zero value is usable.
+type Summarizer struct {
+	MaxBytes int // Cap on the encoded summary (default 16 KiB)
+
+	// ResultURL and TraceURL are link templates in which {task_id} and
+	// {trace_id} are replaced, e.g. "https://agents.example.com/tasks/{task_id}"
+	// (optional).
+	ResultURL string
+	TraceURL  string
+
+	// ArtifactURL returns a download link for an artifact, e.g. a
+	// pre-signed object store URL (optional).
+	ArtifactURL func(job *agent.Job, index int, a agent.Artifact) string
+}
+
+// maxSummaryError caps the error message of a summary.
+const maxSummaryError = 1024
+
+// Summarize returns the summary of a finished job. An output that would push
+// the summary past MaxBytes is cut short and marked OutputTruncated; if that
+// is not enough the artifact list is dropped, keeping its count.
+func (s *Summarizer) Summarize(job *agent.Job) *Summary {
+	rec := NewRecord(job)
+	sum := &Summary{
+		TaskID:  rec.TaskID,
+		Status:  rec.Status,
+		Success: rec.Success,
+		Output:  rec.Output,
+		Error:   truncateText(rec.Error, maxSummaryError),
+		Labels:  rec.Labels,
+		Metrics: SummaryMetrics{Usage: rec.Usage, Artifacts: rec.Artifacts},
+	}
+	if !rec.CompletedAt.IsZero() && !rec.StartedAt.IsZero() {
+		sum.Metrics.DurationMS = rec.CompletedAt.Sub(rec.StartedAt).Milliseconds()
+	}
+
+	traceID := ""
+	if r := job.Result; r != nil {
+		sum.Metrics.Steps = len(r.Steps)
+		for _, step := range r.Steps {
+			sum.Metrics.ToolCalls += len(step.ToolCalls)
+		}
+		sum.Metrics.CostUSD, _ = r.Metadata["cost_usd"].(float64)
+		traceID, _ = r.Metadata["trace_id"].(string)
+		for i, a := range r.Artifacts {
+			ref := ArtifactRef{Index: i, Type: a.Type, MimeType: a.MimeType}
+			if s.ArtifactURL != nil {
+				ref.URL = s.ArtifactURL(job, i, a)
+			}
+			sum.Artifacts = append(sum.Artifacts, ref)
+		}
+	}
+
+	links := map[string]string{}
+	if s.ResultURL != "" {
+		links["result"] = strings.ReplaceAll(s.ResultURL, "{task_id}", sum.TaskID)
+	}
+	if s.TraceURL != "" && traceID != "" {
+		links["trace"] = strings.ReplaceAll(s.TraceURL, "{trace_id}", traceID)
+	}
+	if len(links) > 0 {
+		sum.Links = links
+	}
+
+	s.fit(sum)
+	return sum
+}
+
+// fit shrinks sum to MaxBytes of JSON.
+func (s *Summarizer) fit(sum *Summary) {
+	limit := s.MaxBytes
+	if limit <= 0 {
+		limit = 16 << 10
+	}
+	size := encodedSize(sum)
+	if size <= limit {
+		return
+	}
+	if sum.Output != nil {
+		text := outputText(sum.Output)
+		sum.OutputTruncated = true
+		sum.Output = ""
+		base := encodedSize(sum)
+		// Escaping makes the encoded text longer than the text, so scale the
+		// cut by the encoded length and repeat until it fits
+		for keep := len(text); keep > 0 && size > limit; size = encodedSize(sum) {
+			keep = min(keep*max(limit-base, 0)/(size-base), keep-1)
+			sum.Output = truncateText(text, keep)
+		}
+		if size <= limit {
+			return
+		}
+	}
+	sum.Artifacts = nil
+	if encodedSize(sum) > limit {
+		sum.Output = nil
+	}
+}
+
+func encodedSize(v interface{}) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// outputText renders an output as text, JSON-encoding structured values.
+func outputText(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// truncateText cuts s to at most n bytes on a rune boundary, marking the cut
+// with an ellipsis.
+func truncateText(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
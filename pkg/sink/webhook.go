@@ -0,0 +1,75 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Webhook POSTs each finished job's Record as JSON to URL, or its Summary
+// when Summarizer is set.
+type Webhook struct {
+	URL        string
+	Headers    map[string]string
+	Client     *http.Client // Defaults to http.DefaultClient
+	Summarizer *Summarizer  // Send compact summaries instead of full records (optional)
+}
+
+func (s *Webhook) Deliver(ctx context.Context, job *agent.Job) error {
+	var payload interface{} = NewRecord(job)
+	if s.Summarizer != nil {
+		payload = s.Summarizer.Summarize(job)
+	}
+	return postJSON(ctx, s.Client, s.URL, s.Headers, payload)
+}
+
+// Callback POSTs the Summary of each finished job to the job's
+// ExecutionConfig.CallbackURL. Jobs without a callback URL are skipped.
+type Callback struct {
+	Headers    map[string]string
+	Client     *http.Client // Defaults to http.DefaultClient
+	Summarizer *Summarizer  // Defaults to a zero Summarizer (16 KiB cap, no links)
+}
+
+func (s *Callback) Deliver(ctx context.Context, job *agent.Job) error {
+	if job.Task.Config == nil || job.Task.Config.CallbackURL == "" {
+		return nil
+	}
+	summarizer := s.Summarizer
+	if summarizer == nil {
+		summarizer = &Summarizer{}
+	}
+	return postJSON(ctx, s.Client, job.Task.Config.CallbackURL, s.Headers, summarizer.Summarize(job))
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sink endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Package tools provides ready-made agent.Tool implementations.
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// BrowserDriver performs actions in a browser session. Implementations wrap a
+// real browser, e.g. via the Chrome DevTools Protocol.
+type BrowserDriver interface {
+	Navigate(ctx context.Context, url string) error
+	Click(ctx context.Context, selector string) error
+	Type(ctx context.Context, selector, text string) error
+	Screenshot(ctx context.Context) ([]byte, error) // PNG-encoded
+	Extract(ctx context.Context, selector string) (string, error)
+}
+
+// BrowserTool exposes a BrowserDriver to agents as a single "browser" tool
+// taking an action argument. Screenshots are returned as image parts so vision
+// models can see them.
+type BrowserTool struct {
+	driver BrowserDriver
+}
+
+// NewBrowserTool creates a BrowserTool backed by driver.
+func NewBrowserTool(driver BrowserDriver) *BrowserTool {
+	return &BrowserTool{driver: driver}
+}
+
+func (t *BrowserTool) Name() string {
+	return "browser"
+}
+
+func (t *BrowserTool) Description() string {
+	return "Control a web browser. Actions: navigate (url), click (selector), type (selector, text), screenshot, extract (selector; defaults to body text)."
+}
+
+func (t *BrowserTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type": "string",
+				"enum": []string{"navigate", "click", "type", "screenshot", "extract"},
+			},
+			"url":      map[string]interface{}{"type": "string"},
+			"selector": map[string]interface{}{"type": "string", "description": "CSS selector"},
+			"text":     map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *BrowserTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	action, _ := args["action"].(string)
+	url, _ := args["url"].(string)
+	selector, _ := args["selector"].(string)
+	text, _ := args["text"].(string)
+
+	switch action {
+	case "navigate":
+		if url == "" {
+			return nil, fmt.Errorf("navigate requires url")
+		}
+		if err := t.driver.Navigate(ctx, url); err != nil {
+			return nil, err
+		}
+		return "navigated to " + url, nil
+
+	case "click":
+		if selector == "" {
+			return nil, fmt.Errorf("click requires selector")
+		}
+		if err := t.driver.Click(ctx, selector); err != nil {
+			return nil, err
+		}
+		return "clicked " + selector, nil
+
+	case "type":
+		if selector == "" {
+			return nil, fmt.Errorf("type requires selector")
+		}
+		if err := t.driver.Type(ctx, selector, text); err != nil {
+			return nil, err
+		}
+		return fmt.Sprintf("typed into %s", selector), nil
+
+	case "screenshot":
+		png, err := t.driver.Screenshot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return agent.Part{Type: "image/png", Data: png}, nil
+
+	case "extract":
+		if selector == "" {
+			selector = "body"
+		}
+		return t.driver.Extract(ctx, selector)
+
+	default:
+		return nil, fmt.Errorf("unknown browser action %q", action)
+	}
+}
@@ -0,0 +1,296 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Calculator evaluates arithmetic expressions exactly as written, so agents
+// do not have to do arithmetic in their heads. It supports + - * / % ^,
+// parentheses, the constants pi and e, and the functions sqrt, abs, round,
+// floor, ceil, exp, ln, log10, log2, sin, cos, tan, min, max, and pow.
+type Calculator struct{}
+
+// NewCalculator creates a "calculator" tool.
+func NewCalculator() *Calculator {
+	return &Calculator{}
+}
+
+func (t *Calculator) Name() string { return "calculator" }
+
+func (t *Calculator) Description() string {
+	return "Evaluate an arithmetic expression, e.g. \"(1200 * 0.075) / 12\" or \"sqrt(2) ^ 3\". Use it for any calculation instead of computing in your head."
+}
+
+func (t *Calculator) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"expression": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"expression"},
+	}
+}
+
+// Idempotent marks the tool safe to prefetch and retry.
+func (t *Calculator) Idempotent() bool { return true }
+
+func (t *Calculator) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	expr, _ := args["expression"].(string)
+	v, err := Evaluate(expr)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"expression": expr, "result": v}, nil
+}
+
+// Evaluate computes an arithmetic expression; see Calculator for the syntax.
+func Evaluate(expr string) (float64, error) {
+	p := &exprParser{src: expr}
+	p.next()
+	v, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	if p.tok != "" {
+		return 0, fmt.Errorf("unexpected %q at position %d", p.tok, p.start)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("result of %q is not a finite number", expr)
+	}
+	return v, nil
+}
+
+// exprParser is a recursive-descent parser over a token stream. Tokens are
+// numbers, identifiers, and single-character operators; "" is the end.
+type exprParser struct {
+	src   string
+	pos   int
+	start int
+	tok   string
+}
+
+func (p *exprParser) next() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	p.start = p.pos
+	if p.pos >= len(p.src) {
+		p.tok = ""
+		return
+	}
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || p.src[p.pos] == '.' || p.src[p.pos] == '_') {
+			p.pos++
+		}
+		// Exponent, e.g. 1e-3
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			end := p.pos + 1
+			if end < len(p.src) && (p.src[end] == '+' || p.src[end] == '-') {
+				end++
+			}
+			if end < len(p.src) && isDigit(p.src[end]) {
+				for end < len(p.src) && isDigit(p.src[end]) {
+					end++
+				}
+				p.pos = end
+			}
+		}
+	case unicode.IsLetter(rune(c)):
+		for p.pos < len(p.src) && (unicode.IsLetter(rune(p.src[p.pos])) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+	default:
+		p.pos++
+	}
+	p.tok = p.src[p.start:p.pos]
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func (p *exprParser) expect(tok string) error {
+	if p.tok != tok {
+		if p.tok == "" {
+			return fmt.Errorf("expected %q at end of expression", tok)
+		}
+		return fmt.Errorf("expected %q at position %d, got %q", tok, p.start, p.tok)
+	}
+	p.next()
+	return nil
+}
+
+// expr := term (("+" | "-") term)*
+func (p *exprParser) expr() (float64, error) {
+	v, err := p.term()
+	for err == nil && (p.tok == "+" || p.tok == "-") {
+		op := p.tok
+		p.next()
+		var r float64
+		if r, err = p.term(); op == "+" {
+			v += r
+		} else {
+			v -= r
+		}
+	}
+	return v, err
+}
+
+// term := unary (("*" | "/" | "%") unary)*
+func (p *exprParser) term() (float64, error) {
+	v, err := p.unary()
+	for err == nil && (p.tok == "*" || p.tok == "/" || p.tok == "%") {
+		op := p.tok
+		p.next()
+		var r float64
+		if r, err = p.unary(); err != nil {
+			break
+		}
+		switch {
+		case op == "*":
+			v *= r
+		case r == 0:
+			return 0, fmt.Errorf("division by zero")
+		case op == "/":
+			v /= r
+		default:
+			v = math.Mod(v, r)
+		}
+	}
+	return v, err
+}
+
+// unary := ("-" | "+") unary | power
+func (p *exprParser) unary() (float64, error) {
+	if p.tok == "-" || p.tok == "+" {
+		neg := p.tok == "-"
+		p.next()
+		v, err := p.unary()
+		if neg {
+			v = -v
+		}
+		return v, err
+	}
+	return p.power()
+}
+
+// power := primary ("^" unary)?   (right-associative)
+func (p *exprParser) power() (float64, error) {
+	v, err := p.primary()
+	if err != nil || p.tok != "^" {
+		return v, err
+	}
+	p.next()
+	exp, err := p.unary()
+	return math.Pow(v, exp), err
+}
+
+var calcConstants = map[string]float64{"pi": math.Pi, "e": math.E}
+
+var calcFuncs = map[string]func(args []float64) (float64, error){
+	"sqrt":  unaryFunc(math.Sqrt),
+	"abs":   unaryFunc(math.Abs),
+	"round": unaryFunc(math.Round),
+	"floor": unaryFunc(math.Floor),
+	"ceil":  unaryFunc(math.Ceil),
+	"exp":   unaryFunc(math.Exp),
+	"ln":    unaryFunc(math.Log),
+	"log10": unaryFunc(math.Log10),
+	"log2":  unaryFunc(math.Log2),
+	"sin":   unaryFunc(math.Sin),
+	"cos":   unaryFunc(math.Cos),
+	"tan":   unaryFunc(math.Tan),
+	"pow": func(args []float64) (float64, error) {
+		if len(args) != 2 {
+			return 0, fmt.Errorf("pow takes 2 arguments")
+		}
+		return math.Pow(args[0], args[1]), nil
+	},
+	"min": variadicFunc(math.Min),
+	"max": variadicFunc(math.Max),
+}
+
+func unaryFunc(fn func(float64) float64) func([]float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) != 1 {
+			return 0, fmt.Errorf("takes 1 argument")
+		}
+		return fn(args[0]), nil
+	}
+}
+
+func variadicFunc(fn func(a, b float64) float64) func([]float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) == 0 {
+			return 0, fmt.Errorf("takes at least 1 argument")
+		}
+		v := args[0]
+		for _, a := range args[1:] {
+			v = fn(v, a)
+		}
+		return v, nil
+	}
+}
+
+// primary := number | constant | func "(" expr ("," expr)* ")" | "(" expr ")"
+func (p *exprParser) primary() (float64, error) {
+	tok := p.tok
+	switch {
+	case tok == "":
+		return 0, fmt.Errorf("unexpected end of expression")
+	case tok == "(":
+		p.next()
+		v, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		return v, p.expect(")")
+	case isDigit(tok[0]) || tok[0] == '.':
+		v, err := strconv.ParseFloat(strings.ReplaceAll(tok, "_", ""), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", tok)
+		}
+		p.next()
+		return v, nil
+	case unicode.IsLetter(rune(tok[0])):
+		name := strings.ToLower(tok)
+		p.next()
+		if v, ok := calcConstants[name]; ok && p.tok != "(" {
+			return v, nil
+		}
+		fn, ok := calcFuncs[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown function or constant %q", tok)
+		}
+		if err := p.expect("("); err != nil {
+			return 0, err
+		}
+		var args []float64
+		for p.tok != ")" {
+			v, err := p.expr()
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, v)
+			if p.tok != "," {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(")"); err != nil {
+			return 0, err
+		}
+		v, err := fn(args)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+		return v, nil
+	default:
+		return 0, fmt.Errorf("unexpected %q at position %d", tok, p.start)
+	}
+}
@@ -0,0 +1,71 @@
+// Package chromedp provides a tools.BrowserDriver backed by chromedp. It is a
+// separate module so the core framework does not depend on chromedp.
+package chromedp
+
+import (
+	"context"
+
+	"github.com/chromedp/chromedp"
+
+	"github.com/sultanfariz/gonostic/pkg/tools"
+)
+
+// Driver drives a headless Chrome instance through chromedp.
+type Driver struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+var _ tools.BrowserDriver = (*Driver)(nil)
+
+// New starts a browser session. Close releases it.
+func New(opts ...chromedp.ExecAllocatorOption) *Driver {
+	allocOpts := append(chromedp.DefaultExecAllocatorOptions[:], opts...)
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), allocOpts...)
+	ctx, cancel := chromedp.NewContext(allocCtx)
+	return &Driver{
+		ctx: ctx,
+		cancel: func() {
+			cancel()
+			allocCancel()
+		},
+	}
+}
+
+// Close shuts down the browser.
+func (d *Driver) Close() {
+	d.cancel()
+}
+
+// run executes actions in the browser session, aborting if ctx is done.
+func (d *Driver) run(ctx context.Context, actions ...chromedp.Action) error {
+	runCtx, cancel := context.WithCancel(d.ctx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+	return chromedp.Run(runCtx, actions...)
+}
+
+func (d *Driver) Navigate(ctx context.Context, url string) error {
+	return d.run(ctx, chromedp.Navigate(url))
+}
+
+func (d *Driver) Click(ctx context.Context, selector string) error {
+	return d.run(ctx, chromedp.Click(selector, chromedp.ByQuery))
+}
+
+func (d *Driver) Type(ctx context.Context, selector, text string) error {
+	return d.run(ctx, chromedp.SendKeys(selector, text, chromedp.ByQuery))
+}
+
+func (d *Driver) Screenshot(ctx context.Context) ([]byte, error) {
+	var buf []byte
+	err := d.run(ctx, chromedp.CaptureScreenshot(&buf))
+	return buf, err
+}
+
+func (d *Driver) Extract(ctx context.Context, selector string) (string, error) {
+	var text string
+	err := d.run(ctx, chromedp.Text(selector, &text, chromedp.ByQuery))
+	return text, err
+}
@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// DateCalculator does calendar arithmetic: adding a duration to a date, the
+// difference between two dates, and the weekday of a date. It never reads
+// the clock, so results depend only on the arguments.
+type DateCalculator struct{}
+
+// NewDateCalculator creates a "date_calc" tool.
+func NewDateCalculator() *DateCalculator {
+	return &DateCalculator{}
+}
+
+func (t *DateCalculator) Name() string { return "date_calc" }
+
+func (t *DateCalculator) Description() string {
+	return "Date arithmetic. Operations: add (date plus years/months/weeks/days/hours/minutes, negative to subtract), diff (from date to end), weekday (date). Dates are YYYY-MM-DD, YYYY-MM-DD HH:MM, or RFC 3339."
+}
+
+func (t *DateCalculator) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{"type": "string", "enum": []string{"add", "diff", "weekday"}},
+			"date":      map[string]interface{}{"type": "string"},
+			"end":       map[string]interface{}{"type": "string", "description": "Second date, for diff"},
+			"years":     map[string]interface{}{"type": "integer"},
+			"months":    map[string]interface{}{"type": "integer"},
+			"weeks":     map[string]interface{}{"type": "integer"},
+			"days":      map[string]interface{}{"type": "integer"},
+			"hours":     map[string]interface{}{"type": "integer"},
+			"minutes":   map[string]interface{}{"type": "integer"},
+			"timezone":  map[string]interface{}{"type": "string", "description": "IANA zone for dates without an offset, e.g. Asia/Jakarta (default UTC)"},
+		},
+		"required": []string{"operation", "date"},
+	}
+}
+
+// Idempotent marks the tool safe to prefetch and retry.
+func (t *DateCalculator) Idempotent() bool { return true }
+
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04", time.DateOnly}
+
+// parseDate parses s in one of dateLayouts, reporting whether it had a
+// time of day.
+func parseDate(s string, loc *time.Location) (time.Time, bool, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, layout != time.DateOnly, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("cannot parse date %q; use YYYY-MM-DD, YYYY-MM-DD HH:MM, or RFC 3339", s)
+}
+
+func intArg(args map[string]interface{}, key string) int {
+	switch v := args[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+func (t *DateCalculator) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	op, _ := args["operation"].(string)
+	loc := time.UTC
+	if tz, _ := args["timezone"].(string); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone %q", tz)
+		}
+		loc = l
+	}
+	dateArg, _ := args["date"].(string)
+	date, hasTime, err := parseDate(dateArg, loc)
+	if err != nil {
+		return nil, err
+	}
+	format := func(d time.Time) string {
+		if hasTime {
+			return d.Format(time.RFC3339)
+		}
+		return d.Format(time.DateOnly)
+	}
+
+	switch op {
+	case "add":
+		d := addMonths(date, 12*intArg(args, "years")+intArg(args, "months")).AddDate(0, 0, 7*intArg(args, "weeks")+intArg(args, "days"))
+		d = d.Add(time.Duration(intArg(args, "hours"))*time.Hour + time.Duration(intArg(args, "minutes"))*time.Minute)
+		if d.Hour() != 0 || d.Minute() != 0 {
+			hasTime = true
+		}
+		return map[string]interface{}{"date": format(d), "weekday": d.Weekday().String()}, nil
+
+	case "diff":
+		endArg, _ := args["end"].(string)
+		end, endHasTime, err := parseDate(endArg, loc)
+		if err != nil {
+			return nil, err
+		}
+		hours := end.Sub(date).Hours()
+		result := map[string]interface{}{
+			"days":  calendarDays(date, end),
+			"weeks": math.Round(hours/24/7*100) / 100,
+		}
+		if hasTime || endHasTime {
+			result["hours"] = math.Round(hours*100) / 100
+		}
+		years, months, days := calendarDiff(date, end)
+		result["calendar"] = map[string]interface{}{"years": years, "months": months, "days": days}
+		return result, nil
+
+	case "weekday":
+		return map[string]interface{}{"date": format(date), "weekday": date.Weekday().String(), "iso_week": isoWeek(date)}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown date_calc operation %q", op)
+	}
+}
+
+// addMonths adds n months to t, clamping to the last day of the target month
+// so that Jan 31 plus one month is Feb 28 (or 29) rather than early March.
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
+
+// calendarDays counts the days between the dates of a and b, ignoring the
+// time of day and DST shifts.
+func calendarDays(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
+// calendarDiff splits the span from a to b into whole years, months, and
+// days; the parts are negative when b is before a.
+func calendarDiff(a, b time.Time) (years, months, days int) {
+	sign := 1
+	if b.Before(a) {
+		a, b, sign = b, a, -1
+	}
+	months = (b.Year()-a.Year())*12 + int(b.Month()-a.Month())
+	if addMonths(a, months).After(b) {
+		months--
+	}
+	days = calendarDays(addMonths(a, months), b)
+	return sign * (months / 12), sign * (months % 12), sign * days
+}
+
+func isoWeek(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RegexExtractor returns the matches of a regular expression in a text, so
+// agents pull out IDs, amounts, or dates exactly instead of paraphrasing.
+type RegexExtractor struct {
+	MaxMatches int // Cap on matches returned with all (default 100)
+}
+
+// NewRegexExtractor creates a "regex_extract" tool.
+func NewRegexExtractor() *RegexExtractor {
+	return &RegexExtractor{MaxMatches: 100}
+}
+
+func (t *RegexExtractor) Name() string { return "regex_extract" }
+
+func (t *RegexExtractor) Description() string {
+	return "Extract matches of a regular expression (RE2 syntax) from text. Returns the first match, or every match with all. group selects a capture group by number or name."
+}
+
+func (t *RegexExtractor) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"text":    map[string]interface{}{"type": "string"},
+			"pattern": map[string]interface{}{"type": "string"},
+			"all":     map[string]interface{}{"type": "boolean", "description": "Return every match instead of the first"},
+			"group":   map[string]interface{}{"type": "string", "description": "Capture group number or name (default: whole match)"},
+		},
+		"required": []string{"text", "pattern"},
+	}
+}
+
+// Idempotent marks the tool safe to prefetch and retry.
+func (t *RegexExtractor) Idempotent() bool { return true }
+
+func (t *RegexExtractor) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	text, _ := args["text"].(string)
+	pattern, _ := args["pattern"].(string)
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	group := 0
+	switch g := args["group"].(type) {
+	case float64:
+		group = int(g)
+	case string:
+		if n, err := strconv.Atoi(g); err == nil {
+			group = n
+		} else if g != "" {
+			if group = re.SubexpIndex(g); group < 0 {
+				return nil, fmt.Errorf("pattern has no group named %q", g)
+			}
+		}
+	}
+	if group < 0 || group > re.NumSubexp() {
+		return nil, fmt.Errorf("pattern has no group %d", group)
+	}
+
+	limit := 1
+	if all, _ := args["all"].(bool); all {
+		if limit = t.MaxMatches; limit <= 0 {
+			limit = 100
+		}
+	}
+	matches := []string{}
+	for _, m := range re.FindAllStringSubmatch(text, limit) {
+		matches = append(matches, m[group])
+	}
+	return map[string]interface{}{"matches": matches, "count": len(matches)}, nil
+}
+
+// JSONQuery selects values from a JSON document by path, e.g. "a.b[0].c"
+// or "items[*].name".
+type JSONQuery struct{}
+
+// NewJSONQuery creates a "json_query" tool.
+func NewJSONQuery() *JSONQuery {
+	return &JSONQuery{}
+}
+
+func (t *JSONQuery) Name() string { return "json_query" }
+
+func (t *JSONQuery) Description() string {
+	return `Select values from a JSON document. Paths use dots for keys and brackets for indexes: "order.items[0].price"; [*] maps over an array: "items[*].name"; negative indexes count from the end. An empty path returns the whole document.`
+}
+
+func (t *JSONQuery) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"json": map[string]interface{}{"type": "string", "description": "The JSON document"},
+			"path": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"json", "path"},
+	}
+}
+
+// Idempotent marks the tool safe to prefetch and retry.
+func (t *JSONQuery) Idempotent() bool { return true }
+
+func (t *JSONQuery) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	doc, _ := args["json"].(string)
+	path, _ := args["path"].(string)
+	var v interface{}
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	result, err := QueryJSON(v, path)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"path": path, "result": result}, nil
+}
+
+// QueryJSON follows path, in the syntax of JSONQuery, through a decoded JSON
+// value.
+func QueryJSON(v interface{}, path string) (interface{}, error) {
+	segments, err := splitJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return queryJSON(v, segments, "")
+}
+
+func queryJSON(v interface{}, segments []string, at string) (interface{}, error) {
+	if len(segments) == 0 {
+		return v, nil
+	}
+	seg, rest := segments[0], segments[1:]
+
+	if !strings.HasPrefix(seg, "[") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s is not an object", describePath(at))
+		}
+		child, ok := obj[seg]
+		if !ok {
+			return nil, fmt.Errorf("%s has no key %q", describePath(at), seg)
+		}
+		return queryJSON(child, rest, joinPath(at, seg))
+	}
+
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is not an array", describePath(at))
+	}
+	if seg == "[*]" {
+		out := make([]interface{}, 0, len(arr))
+		for i, item := range arr {
+			r, err := queryJSON(item, rest, fmt.Sprintf("%s[%d]", at, i))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, r)
+		}
+		return out, nil
+	}
+	idx, err := strconv.Atoi(seg[1 : len(seg)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid index %s", seg)
+	}
+	if idx < 0 {
+		idx += len(arr)
+	}
+	if idx < 0 || idx >= len(arr) {
+		return nil, fmt.Errorf("index %s out of range for %s (length %d)", seg, describePath(at), len(arr))
+	}
+	return queryJSON(arr[idx], rest, at+seg)
+}
+
+// splitJSONPath splits "a.b[0]" into "a", "b", "[0]".
+func splitJSONPath(path string) ([]string, error) {
+	var segments []string
+	for i := 0; i < len(path); {
+		switch c := path[i]; {
+		case c == '.':
+			i++
+		case c == '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in path %q", path)
+			}
+			segments = append(segments, path[i:i+end+1])
+			i += end + 1
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			segments = append(segments, path[i:i+end])
+			i += end
+		}
+	}
+	return segments, nil
+}
+
+func joinPath(at, key string) string {
+	if at == "" {
+		return key
+	}
+	return at + "." + key
+}
+
+func describePath(at string) string {
+	if at == "" {
+		return "document"
+	}
+	return at
+}
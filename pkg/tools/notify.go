@@ -0,0 +1,254 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Notification is a message delivered by a Notifier.
+type Notification struct {
+	Subject string
+	Body    string
+}
+
+// Notifier delivers notifications to a channel (email, chat, webhook).
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// SMTPNotifier sends notifications as plain-text UTF-8 email.
+type SMTPNotifier struct {
+	Addr string // host:port of the SMTP server
+	Auth smtp.Auth
+	From string
+	To   []string
+}
+
+// ErrHeaderInjection is returned for email header values containing line
+// breaks, which would add headers or body text, e.g. a subject rendered
+// from prompt-injected tool arguments.
+var ErrHeaderInjection = errors.New("line break in email header")
+
+func (s *SMTPNotifier) Notify(ctx context.Context, n Notification) error {
+	to := strings.Join(s.To, ", ")
+	for _, v := range []string{s.From, to, n.Subject} {
+		if strings.ContainsAny(v, "\r\n") {
+			return ErrHeaderInjection
+		}
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		s.From, to, mime.QEncoding.Encode("utf-8", n.Subject), n.Body)
+	return smtp.SendMail(s.Addr, s.Auth, s.From, s.To, []byte(msg))
+}
+
+// SlackNotifier posts notifications to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client // Defaults to http.DefaultClient
+}
+
+func (s *SlackNotifier) Notify(ctx context.Context, n Notification) error {
+	text := n.Body
+	if n.Subject != "" {
+		text = "*" + n.Subject + "*\n" + n.Body
+	}
+	return postJSON(ctx, s.Client, s.WebhookURL, nil, map[string]string{"text": text})
+}
+
+// WebhookNotifier posts notifications as JSON {"subject", "body"} to a URL.
+type WebhookNotifier struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client // Defaults to http.DefaultClient
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	return postJSON(ctx, w.Client, w.URL, w.Headers, map[string]string{"subject": n.Subject, "body": n.Body})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ErrApprovalDenied is returned when a notification requiring approval is
+// rejected by the approver.
+var ErrApprovalDenied = errors.New("notification not approved")
+
+// NotifyToolConfig holds configuration for creating a NotifyTool.
+type NotifyToolConfig struct {
+	Name        string // Tool name (default "notify")
+	Description string
+	Notifier    Notifier
+
+	// Templates render the subject and body from the tool arguments, e.g.
+	// "Deploy {{.service}} finished". Default to the "subject" and "message"
+	// arguments.
+	SubjectTemplate string
+	BodyTemplate    string
+
+	// RequireApproval makes every send wait for Approve to return true.
+	RequireApproval bool
+	Approve         func(ctx context.Context, n Notification) (bool, error)
+}
+
+// NotifyTool lets an agent send templated notifications through a Notifier.
+type NotifyTool struct {
+	cfg     NotifyToolConfig
+	subject *template.Template
+	body    *template.Template
+}
+
+// NewNotifyTool creates a NotifyTool, parsing its templates.
+func NewNotifyTool(cfg NotifyToolConfig) (*NotifyTool, error) {
+	if cfg.Name == "" {
+		cfg.Name = "notify"
+	}
+	if cfg.Description == "" {
+		cfg.Description = "Send a notification with a subject and message."
+	}
+	if cfg.SubjectTemplate == "" {
+		cfg.SubjectTemplate = "{{.subject}}"
+	}
+	if cfg.BodyTemplate == "" {
+		cfg.BodyTemplate = "{{.message}}"
+	}
+
+	subject, err := template.New("subject").Option("missingkey=zero").Parse(cfg.SubjectTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parse subject template: %w", err)
+	}
+	body, err := template.New("body").Option("missingkey=zero").Parse(cfg.BodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parse body template: %w", err)
+	}
+	return &NotifyTool{cfg: cfg, subject: subject, body: body}, nil
+}
+
+func (t *NotifyTool) Name() string {
+	return t.cfg.Name
+}
+
+func (t *NotifyTool) Description() string {
+	return t.cfg.Description
+}
+
+func (t *NotifyTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"subject": map[string]interface{}{"type": "string"},
+			"message": map[string]interface{}{"type": "string"},
+		},
+		"additionalProperties": true,
+	}
+}
+
+func (t *NotifyTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	n, err := t.render(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.cfg.RequireApproval {
+		if t.cfg.Approve == nil {
+			return nil, fmt.Errorf("%s requires approval but no approver is configured", t.cfg.Name)
+		}
+		ok, err := t.cfg.Approve(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrApprovalDenied
+		}
+	}
+
+	if err := t.cfg.Notifier.Notify(ctx, n); err != nil {
+		return nil, err
+	}
+	return "notification sent", nil
+}
+
+func (t *NotifyTool) render(data interface{}) (Notification, error) {
+	var subject, body bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return Notification{}, err
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return Notification{}, err
+	}
+	return Notification{Subject: subject.String(), Body: body.String()}, nil
+}
+
+// NotifyOnCompletion returns a job hook for agent.WithOnJobDone that sends a
+// notification when a job that ran at least minDuration finishes.
+func NotifyOnCompletion(n Notifier, minDuration time.Duration) func(job *agent.Job) {
+	return func(job *agent.Job) {
+		duration := job.Task.CompletedAt.Sub(job.Task.StartedAt)
+		if duration < minDuration {
+			return
+		}
+
+		body := fmt.Sprintf("Task %s %s after %s.", job.Task.ID, job.Status(), duration.Round(time.Second))
+		if job.Error != nil {
+			body += "\nError: " + job.Error.Error()
+		} else if job.Result != nil {
+			body += fmt.Sprintf("\nOutput: %v", job.Result.Output)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		// Delivery is best-effort; the job outcome is already recorded
+		_ = n.Notify(ctx, Notification{Subject: fmt.Sprintf("Job %s %s", job.Task.ID, job.Status()), Body: body})
+	}
+}
+
+// NotifySpendAlerts returns a SpendConfig.OnAlert hook that sends each
+// alert through n. Delivery errors are passed to onError, if non-nil.
+func NotifySpendAlerts(n Notifier, onError func(agent.SpendAlert, error)) func(agent.SpendAlert) {
+	return func(a agent.SpendAlert) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		subject := fmt.Sprintf("Spend alert: %.0f%% of daily budget", a.Threshold*100)
+		if a.Tenant != "" {
+			subject += " (" + a.Tenant + ")"
+		}
+		if err := n.Notify(ctx, Notification{Subject: subject, Body: a.Message()}); err != nil && onError != nil {
+			onError(a, err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package tools
+
+import "github.com/sultanfariz/gonostic/pkg/agent"
+
+// ToolkitGroup is the ToolRegistry group RegisterDefaultToolkit uses.
+const ToolkitGroup = "toolkit"
+
+// DefaultToolkit returns the deterministic standard tools: calculator,
+// date_calc, convert_units, regex_extract, and json_query. They do not touch
+// the network or the clock, so agents can use them for exact answers in
+// place of arithmetic or conversions done by the model.
+func DefaultToolkit() []agent.Tool {
+	return []agent.Tool{
+		NewCalculator(),
+		NewDateCalculator(),
+		NewUnitConverter(),
+		NewRegexExtractor(),
+		NewJSONQuery(),
+	}
+}
+
+// RegisterDefaultToolkit registers DefaultToolkit under ToolkitGroup and any
+// extra groups.
+func RegisterDefaultToolkit(r *agent.ToolRegistry, groups ...string) {
+	for _, t := range DefaultToolkit() {
+		r.Register(t, append([]string{ToolkitGroup}, groups...)...)
+	}
+}
@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UnitConverter converts between units of length, mass, volume, area, time,
+// speed, data size, and temperature.
+type UnitConverter struct{}
+
+// NewUnitConverter creates a "convert_units" tool.
+func NewUnitConverter() *UnitConverter {
+	return &UnitConverter{}
+}
+
+func (t *UnitConverter) Name() string { return "convert_units" }
+
+func (t *UnitConverter) Description() string {
+	return "Convert a value between units, e.g. 5 mi to km, 70 kg to lb, 350 F to C, 2 GiB to MB. Units: " + strings.Join(unitNames(), ", ") + "."
+}
+
+func (t *UnitConverter) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"value": map[string]interface{}{"type": "number"},
+			"from":  map[string]interface{}{"type": "string"},
+			"to":    map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"value", "from", "to"},
+	}
+}
+
+// Idempotent marks the tool safe to prefetch and retry.
+func (t *UnitConverter) Idempotent() bool { return true }
+
+// unit is a unit's dimension and its size in the dimension's base unit.
+type unit struct {
+	dimension string
+	factor    float64
+}
+
+var units = map[string]unit{
+	// Length, in meters
+	"mm": {"length", 0.001}, "cm": {"length", 0.01}, "m": {"length", 1}, "km": {"length", 1000},
+	"in": {"length", 0.0254}, "ft": {"length", 0.3048}, "yd": {"length", 0.9144}, "mi": {"length", 1609.344}, "nmi": {"length", 1852},
+	// Mass, in kilograms
+	"mg": {"mass", 1e-6}, "g": {"mass", 0.001}, "kg": {"mass", 1}, "t": {"mass", 1000},
+	"oz": {"mass", 0.028349523125}, "lb": {"mass", 0.45359237}, "st": {"mass", 6.35029318},
+	// Volume, in liters
+	"ml": {"volume", 0.001}, "l": {"volume", 1}, "m3": {"volume", 1000},
+	"tsp": {"volume", 0.00492892159375}, "tbsp": {"volume", 0.01478676478125}, "floz": {"volume", 0.0295735295625},
+	"cup": {"volume", 0.2365882365}, "pt": {"volume", 0.473176473}, "qt": {"volume", 0.946352946}, "gal": {"volume", 3.785411784},
+	// Area, in square meters
+	"m2": {"area", 1}, "km2": {"area", 1e6}, "ha": {"area", 1e4}, "ft2": {"area", 0.09290304}, "acre": {"area", 4046.8564224}, "mi2": {"area", 2589988.110336},
+	// Time, in seconds
+	"ms": {"time", 0.001}, "s": {"time", 1}, "min": {"time", 60}, "h": {"time", 3600}, "day": {"time", 86400}, "week": {"time", 604800},
+	// Speed, in meters per second
+	"m/s": {"speed", 1}, "km/h": {"speed", 1 / 3.6}, "mph": {"speed", 0.44704}, "knot": {"speed", 1852.0 / 3600},
+	// Data, in bytes
+	"b": {"data", 1}, "kb": {"data", 1e3}, "mb": {"data", 1e6}, "gb": {"data", 1e9}, "tb": {"data", 1e12},
+	"kib": {"data", 1 << 10}, "mib": {"data", 1 << 20}, "gib": {"data", 1 << 30}, "tib": {"data", 1 << 40},
+	// Temperature; converted by convertTemperature
+	"c": {"temperature", 0}, "f": {"temperature", 0}, "k": {"temperature", 0},
+}
+
+var unitAliases = map[string]string{
+	"meter": "m", "meters": "m", "metre": "m", "kilometer": "km", "kilometers": "km", "centimeter": "cm", "millimeter": "mm",
+	"inch": "in", "inches": "in", "foot": "ft", "feet": "ft", "yard": "yd", "yards": "yd", "mile": "mi", "miles": "mi",
+	"gram": "g", "grams": "g", "kilogram": "kg", "kilograms": "kg", "kgs": "kg", "tonne": "t", "ounce": "oz", "ounces": "oz",
+	"pound": "lb", "pounds": "lb", "lbs": "lb", "stone": "st",
+	"liter": "l", "liters": "l", "litre": "l", "milliliter": "ml", "gallon": "gal", "gallons": "gal", "cups": "cup", "pint": "pt", "quart": "qt",
+	"fl oz": "floz", "sqm": "m2", "sqft": "ft2", "hectare": "ha", "acres": "acre",
+	"sec": "s", "second": "s", "seconds": "s", "minute": "min", "minutes": "min", "hr": "h", "hour": "h", "hours": "h", "days": "day", "weeks": "week",
+	"kph": "km/h", "kmh": "km/h", "knots": "knot", "kn": "knot",
+	"byte": "b", "bytes": "b",
+	"celsius": "c", "°c": "c", "fahrenheit": "f", "°f": "f", "kelvin": "k",
+}
+
+func lookupUnit(name string) (string, unit, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := unitAliases[key]; ok {
+		key = alias
+	}
+	u, ok := units[key]
+	if !ok {
+		return "", unit{}, fmt.Errorf("unknown unit %q", name)
+	}
+	return key, u, nil
+}
+
+func unitNames() []string {
+	names := make([]string, 0, len(units))
+	for name := range units {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (t *UnitConverter) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	value, ok := args["value"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("value must be a number")
+	}
+	fromArg, _ := args["from"].(string)
+	toArg, _ := args["to"].(string)
+	v, err := ConvertUnits(value, fromArg, toArg)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"value": v, "unit": toArg}, nil
+}
+
+// ConvertUnits converts value from one unit to another of the same
+// dimension; see UnitConverter.
+func ConvertUnits(value float64, from, to string) (float64, error) {
+	fromKey, fu, err := lookupUnit(from)
+	if err != nil {
+		return 0, err
+	}
+	toKey, tu, err := lookupUnit(to)
+	if err != nil {
+		return 0, err
+	}
+	if fu.dimension != tu.dimension {
+		return 0, fmt.Errorf("cannot convert %s (%s) to %s (%s)", from, fu.dimension, to, tu.dimension)
+	}
+	if fu.dimension == "temperature" {
+		return convertTemperature(value, fromKey, toKey), nil
+	}
+	return value * fu.factor / tu.factor, nil
+}
+
+func convertTemperature(v float64, from, to string) float64 {
+	switch from { // To Celsius
+	case "f":
+		v = (v - 32) * 5 / 9
+	case "k":
+		v -= 273.15
+	}
+	switch to {
+	case "f":
+		return v*9/5 + 32
+	case "k":
+		return v + 273.15
+	}
+	return v
+}
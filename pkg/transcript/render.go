@@ -0,0 +1,276 @@
+package transcript
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// Renderer writes a transcript in some output format.
+type Renderer interface {
+	Render(w io.Writer, t *Transcript) error
+}
+
+// DefaultMaxPayload is the per-payload character limit used when a renderer's
+// MaxPayload is 0.
+const DefaultMaxPayload = 4000
+
+func maxPayload(n int) int {
+	if n == 0 {
+		return DefaultMaxPayload
+	}
+	return n
+}
+
+// Markdown renders GitHub-flavored Markdown, with tool payloads in
+// collapsible <details> blocks.
+type Markdown struct {
+	Title         string
+	MaxPayload    int // Characters per tool payload (0 = DefaultMaxPayload, <0 = unlimited)
+	ShowReasoning bool
+}
+
+func (m Markdown) Render(w io.Writer, t *Transcript) error {
+	var b strings.Builder
+	if m.Title != "" {
+		fmt.Fprintf(&b, "## %s\n\n", m.Title)
+	}
+	limit := maxPayload(m.MaxPayload)
+	for _, e := range t.Entries {
+		switch e.Role {
+		case RoleTool:
+			summary := "Tool <code>" + template.HTMLEscapeString(e.Tool.Name) + "</code>"
+			if e.Tool.Error != "" {
+				summary += " (failed)"
+			}
+			if e.Tool.Duration > 0 {
+				summary += " — " + e.Tool.Duration.Round(time.Millisecond).String()
+			}
+			fmt.Fprintf(&b, "<details>\n<summary>%s</summary>\n\n", summary)
+			if args := payload(e.Tool.Arguments); args != "" && args != "null" {
+				fmt.Fprintf(&b, "Arguments:\n\n%s\n", fenced(truncate(args, limit)))
+			}
+			if e.Tool.Error != "" {
+				fmt.Fprintf(&b, "Error:\n\n%s\n", fenced(truncate(e.Tool.Error, limit)))
+			} else if res := payload(e.Tool.Result); res != "" {
+				fmt.Fprintf(&b, "Result:\n\n%s\n", fenced(truncate(res, limit)))
+			}
+			b.WriteString("</details>\n\n")
+		case RoleNote:
+			fmt.Fprintf(&b, "> _%s: %s_\n\n", label(e), oneLine(e.Content))
+		case RoleError:
+			fmt.Fprintf(&b, "> **Error** (%s): %s\n\n", label(e), oneLine(e.Content))
+		default:
+			fmt.Fprintf(&b, "**%s**\n\n", label(e))
+			if m.ShowReasoning && e.Reasoning != "" {
+				fmt.Fprintf(&b, "<details>\n<summary>Reasoning</summary>\n\n%s\n\n</details>\n\n", e.Reasoning)
+			}
+			if e.Content != "" {
+				fmt.Fprintf(&b, "%s\n\n", e.Content)
+			}
+		}
+	}
+	if t.Output != nil {
+		fmt.Fprintf(&b, "**Output**\n\n%s\n", fenced(truncate(payload(t.Output), limit)))
+	}
+	if t.Error != "" {
+		fmt.Fprintf(&b, "**Failed:** %s\n\n", oneLine(t.Error))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fenced wraps s in a code fence longer than any backtick run inside it.
+func fenced(s string) string {
+	fence := "```"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	return fence + "\n" + s + "\n" + fence + "\n"
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// HTML renders an HTML fragment (or a full page with Standalone), with tool
+// payloads in collapsible <details> elements. Entries carry the classes
+// "entry" and the role name for styling.
+type HTML struct {
+	Title         string
+	MaxPayload    int // Characters per tool payload (0 = DefaultMaxPayload, <0 = unlimited)
+	ShowReasoning bool
+	Standalone    bool // Wrap in a complete document with a default stylesheet
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Parse(`
+{{- if .Standalone}}<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<style>
+.transcript{font-family:system-ui,sans-serif;max-width:60rem}
+.entry{margin:.75rem 0;padding:.5rem .75rem;border-left:3px solid #ccc}
+.entry.user{border-color:#0969da}.entry.assistant{border-color:#1a7f37}
+.entry.tool{border-color:#9a6700}.entry.error{border-color:#cf222e;color:#cf222e}
+.entry.note{border-color:#ddd;color:#666;font-style:italic}
+.author{font-weight:600}.content{white-space:pre-wrap}
+pre{background:#f6f8fa;padding:.5rem;overflow-x:auto}
+</style></head><body>
+{{end -}}
+<div class="transcript">
+{{- if .Title}}
+<h2>{{.Title}}</h2>
+{{- end}}
+{{- range .Entries}}
+<div class="entry {{.Role}}">
+{{- if .Tool}}
+<details><summary>Tool <code>{{.Tool.Name}}</code>{{if .Tool.Error}} (failed){{end}}{{if .Duration}} — {{.Duration}}{{end}}</summary>
+{{- if .Arguments}}
+<div>Arguments</div><pre>{{.Arguments}}</pre>
+{{- end}}
+{{- if .Tool.Error}}
+<div>Error</div><pre>{{.Tool.Error}}</pre>
+{{- else if .Result}}
+<div>Result</div><pre>{{.Result}}</pre>
+{{- end}}
+</details>
+{{- else}}
+<div class="author">{{.Label}}</div>
+{{- if .Reasoning}}
+<details><summary>Reasoning</summary><div class="content">{{.Reasoning}}</div></details>
+{{- end}}
+{{- if .Content}}
+<div class="content">{{.Content}}</div>
+{{- end}}
+{{- end}}
+</div>
+{{- end}}
+{{- if .Output}}
+<div class="entry output"><div class="author">Output</div><pre>{{.Output}}</pre></div>
+{{- end}}
+{{- if .Error}}
+<div class="entry error"><div class="author">Failed</div><div class="content">{{.Error}}</div></div>
+{{- end}}
+</div>
+{{if .Standalone}}</body></html>
+{{end}}`))
+
+type htmlEntry struct {
+	Entry
+	Label     string
+	Arguments string
+	Result    string
+	Duration  string
+}
+
+func (h HTML) Render(w io.Writer, t *Transcript) error {
+	limit := maxPayload(h.MaxPayload)
+	data := struct {
+		Title      string
+		Standalone bool
+		Entries    []htmlEntry
+		Output     string
+		Error      string
+	}{Title: h.Title, Standalone: h.Standalone, Error: t.Error}
+
+	for _, e := range t.Entries {
+		he := htmlEntry{Entry: e, Label: label(e)}
+		if !h.ShowReasoning {
+			he.Reasoning = ""
+		}
+		if e.Tool != nil {
+			if args := payload(e.Tool.Arguments); args != "null" {
+				he.Arguments = truncate(args, limit)
+			}
+			he.Result = truncate(payload(e.Tool.Result), limit)
+			if e.Tool.Duration > 0 {
+				he.Duration = e.Tool.Duration.Round(time.Millisecond).String()
+			}
+		}
+		data.Entries = append(data.Entries, he)
+	}
+	if t.Output != nil {
+		data.Output = truncate(payload(t.Output), limit)
+	}
+	return htmlTemplate.Execute(w, data)
+}
+
+// ANSI renders colored text for terminals. Tool payloads cannot collapse, so
+// they default to a shorter limit.
+type ANSI struct {
+	MaxPayload    int // Characters per tool payload (0 = 500, <0 = unlimited)
+	ShowReasoning bool
+	NoColor       bool // Plain text, e.g. when output is not a terminal
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+func (a ANSI) Render(w io.Writer, t *Transcript) error {
+	limit := a.MaxPayload
+	if limit == 0 {
+		limit = 500
+	}
+	color := func(code, s string) string {
+		if a.NoColor {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	var b strings.Builder
+	for _, e := range t.Entries {
+		switch e.Role {
+		case RoleUser:
+			fmt.Fprintf(&b, "%s\n%s\n\n", color(ansiBold+ansiCyan, "▶ "+label(e)), e.Content)
+		case RoleAssistant:
+			fmt.Fprintf(&b, "%s\n", color(ansiBold+ansiGreen, "◀ "+label(e)))
+			if a.ShowReasoning && e.Reasoning != "" {
+				fmt.Fprintf(&b, "%s\n", color(ansiDim, indent(e.Reasoning)))
+			}
+			if e.Content != "" {
+				fmt.Fprintf(&b, "%s\n", e.Content)
+			}
+			b.WriteString("\n")
+		case RoleTool:
+			head := "⚙ " + label(e)
+			if e.Tool.Duration > 0 {
+				head += " (" + e.Tool.Duration.Round(time.Millisecond).String() + ")"
+			}
+			fmt.Fprintf(&b, "%s\n", color(ansiYellow, head))
+			if args := payload(e.Tool.Arguments); args != "" && args != "null" {
+				fmt.Fprintf(&b, "%s\n", color(ansiDim, indent(truncate(args, limit))))
+			}
+			if e.Tool.Error != "" {
+				fmt.Fprintf(&b, "%s\n", color(ansiRed, indent("error: "+truncate(e.Tool.Error, limit))))
+			} else if res := payload(e.Tool.Result); res != "" {
+				fmt.Fprintf(&b, "%s\n", indent("→ "+truncate(res, limit)))
+			}
+			b.WriteString("\n")
+		case RoleNote:
+			fmt.Fprintf(&b, "%s\n\n", color(ansiDim, "· "+label(e)+": "+e.Content))
+		case RoleError:
+			fmt.Fprintf(&b, "%s\n\n", color(ansiRed, "✗ "+label(e)+": "+e.Content))
+		}
+	}
+	if t.Output != nil {
+		fmt.Fprintf(&b, "%s\n%s\n", color(ansiBold, "Output"), truncate(payload(t.Output), limit))
+	}
+	if t.Error != "" {
+		fmt.Fprintf(&b, "%s\n", color(ansiBold+ansiRed, "Failed: "+t.Error))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func indent(s string) string {
+	return "  " + strings.ReplaceAll(s, "\n", "\n  ")
+}
@@ -0,0 +1,245 @@
+// Package transcript renders executions as readable conversation transcripts
+// (Markdown, HTML, or ANSI-colored terminal text) for tickets, PR comments,
+// and review tools.
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sultanfariz/gonostic/pkg/agent"
+)
+
+// Role is the kind of a transcript entry.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	RoleTool      Role = "tool"
+	RoleNote      Role = "note"  // Workflow actions: guardrails, delegation, escalation, compensation
+	RoleError     Role = "error" // A failed step
+)
+
+// Entry is one section of a transcript.
+type Entry struct {
+	Role      Role
+	Author    string // Agent name; empty for the user
+	AgentPath string
+	Time      time.Time
+	Content   string
+	Reasoning string
+	Tool      *ToolEntry // Set for RoleTool
+}
+
+// ToolEntry is a tool call with its payloads.
+type ToolEntry struct {
+	ID        string
+	Name      string
+	Arguments interface{}
+	Result    interface{}
+	Error     string
+	Duration  time.Duration
+}
+
+// Transcript is an execution laid out as conversation entries.
+type Transcript struct {
+	TaskID  string
+	Entries []Entry
+	Output  interface{} // Final structured output; nil when it is the last assistant message
+	Error   string
+	Usage   agent.TokenUsage
+}
+
+// FromResult builds a transcript from a finished execution. input is the
+// user prompt shown first; leave it empty to omit it.
+func FromResult(input string, r *agent.Result) *Transcript {
+	t := FromEvents(input, r.Events())
+	t.TaskID = r.TaskID
+	t.Error = r.Error
+	t.Usage = r.TotalTokenUsage
+	if r.Output != nil {
+		if s, ok := r.Output.(string); !ok || s != t.lastAssistant() {
+			t.Output = r.Output
+		}
+	}
+	return t
+}
+
+// FromEvents builds a transcript from an event stream, e.g. one gathered by
+// a Collector. Streaming deltas are joined; they are dropped once the step
+// carrying the full content arrives.
+func FromEvents(input string, events []*agent.Event) *Transcript {
+	t := &Transcript{}
+	if input != "" {
+		t.Entries = append(t.Entries, Entry{Role: RoleUser, Content: input})
+	}
+
+	partial := make(map[string]*Entry)
+	var order []string
+	for _, ev := range events {
+		if t.TaskID == "" {
+			t.TaskID = ev.TaskID
+		}
+		switch ev.Type {
+		case agent.EventPartial:
+			e, ok := partial[ev.AgentPath+"/"+ev.Author]
+			if !ok {
+				e = &Entry{Role: RoleAssistant, Author: ev.Author, AgentPath: ev.AgentPath, Time: ev.Timestamp}
+				partial[ev.AgentPath+"/"+ev.Author] = e
+				order = append(order, ev.AgentPath+"/"+ev.Author)
+			}
+			e.Content += ev.Content
+		case agent.EventStep:
+			delete(partial, ev.AgentPath+"/"+ev.Author)
+			t.addStep(ev)
+		case agent.EventResponse:
+			if ev.Content != "" {
+				t.Entries = append(t.Entries, Entry{Role: RoleAssistant, Author: ev.Author, AgentPath: ev.AgentPath, Time: ev.Timestamp, Content: ev.Content})
+			}
+			t.addTools(ev)
+		case agent.EventEscalation:
+			reason := ""
+			if ev.Actions != nil {
+				reason = ev.Actions.EscalationReason
+			}
+			t.Entries = append(t.Entries, Entry{Role: RoleNote, Author: ev.Author, AgentPath: ev.AgentPath, Time: ev.Timestamp, Content: strings.TrimSpace("escalated " + reason)})
+		}
+	}
+
+	// Streams cut off before their step completed
+	for _, key := range order {
+		if e, ok := partial[key]; ok {
+			t.Entries = append(t.Entries, *e)
+		}
+	}
+	return t
+}
+
+// addStep appends the entries for one execution step.
+func (t *Transcript) addStep(ev *agent.Event) {
+	base := Entry{Author: ev.Author, AgentPath: ev.AgentPath, Time: ev.Timestamp}
+	switch ev.Action {
+	case "reasoning", "tool_execution", "escalate", "":
+		if ev.Content != "" || ev.Reasoning != "" {
+			e := base
+			e.Role, e.Content, e.Reasoning = RoleAssistant, ev.Content, ev.Reasoning
+			t.Entries = append(t.Entries, e)
+		}
+		t.addTools(ev)
+		if ev.Action == "escalate" {
+			e := base
+			e.Role, e.Content = RoleNote, "escalated"
+			t.Entries = append(t.Entries, e)
+		}
+	default:
+		e := base
+		e.Role, e.Content = RoleNote, ev.Action
+		if out := ev.Output; out != nil && out != "" {
+			e.Content += ": " + payload(out)
+		}
+		t.Entries = append(t.Entries, e)
+	}
+	if ev.Error != "" {
+		e := base
+		e.Role, e.Content = RoleError, ev.Error
+		t.Entries = append(t.Entries, e)
+	}
+}
+
+func (t *Transcript) addTools(ev *agent.Event) {
+	for _, tc := range ev.ToolCalls {
+		tool := &ToolEntry{ID: tc.ID, Name: tc.Name, Arguments: tc.Arguments, Result: tc.Result, Duration: tc.Duration}
+		if tc.Error != nil {
+			tool.Error = tc.Error.Error()
+		}
+		t.Entries = append(t.Entries, Entry{Role: RoleTool, Author: ev.Author, AgentPath: ev.AgentPath, Time: ev.Timestamp, Tool: tool})
+	}
+}
+
+func (t *Transcript) lastAssistant() string {
+	for i := len(t.Entries) - 1; i >= 0; i-- {
+		if t.Entries[i].Role == RoleAssistant {
+			return t.Entries[i].Content
+		}
+	}
+	return ""
+}
+
+// Collector gathers streamed events for FromEvents. Use Handler as the
+// ExecutionConfig.OnEvent, with StreamingModeFull to see every step.
+type Collector struct {
+	mu     sync.Mutex
+	events []*agent.Event
+}
+
+// Handler returns the event handler that records into the collector.
+func (c *Collector) Handler() agent.EventHandler {
+	return func(ev *agent.Event) {
+		c.mu.Lock()
+		c.events = append(c.events, ev)
+		c.mu.Unlock()
+	}
+}
+
+// Transcript builds a transcript from the events collected so far.
+func (c *Collector) Transcript(input string) *Transcript {
+	c.mu.Lock()
+	events := append([]*agent.Event(nil), c.events...)
+	c.mu.Unlock()
+	return FromEvents(input, events)
+}
+
+// payload renders a tool argument or result: strings as-is, everything else
+// as indented JSON.
+func payload(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case error:
+		return v.Error()
+	}
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// truncate shortens s to max runes (0 = no limit).
+func truncate(s string, max int) string {
+	if max <= 0 {
+		return s
+	}
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max]) + fmt.Sprintf("\n… (%d more characters)", len(r)-max)
+}
+
+// label names the author of an entry.
+func label(e Entry) string {
+	switch e.Role {
+	case RoleUser:
+		return "User"
+	case RoleTool:
+		return "Tool " + e.Tool.Name
+	}
+	name := e.Author
+	if name == "" {
+		name = "Assistant"
+	}
+	if e.AgentPath != "" && e.AgentPath != e.Author {
+		name += " (" + e.AgentPath + ")"
+	}
+	return name
+}